@@ -0,0 +1,295 @@
+// Package alerts runs saved Search queries on a schedule and reports the episodes that newly match them, e.g. to
+// monitor mentions of a brand in podcasts.
+//
+// Episodes already reported for a query are remembered in a SeenStore, so every match is reported once:
+//
+//	engine := alerts.NewEngine(client, alerts.NewMemorySeenStore(),
+//		alerts.WithMatchFunc(func(matches []alerts.Match) {
+//			digest := alerts.NewDigest("Brand mentions", matches)
+//			sendEmail(digest.Markdown(), digest.HTML())
+//		}),
+//	)
+//	engine.Add(alerts.Query{ID: "acme", Q: `"acme corp"`, Window: 7 * 24 * time.Hour})
+//	go engine.Run(ctx, time.Hour)
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/searchpage"
+)
+
+// Query is a saved episode search
+type Query struct {
+	// ID identifies the query in the SeenStore, it must be stable across restarts
+	ID   string
+	Name string
+	// Q is the search term, as given to Search
+	Q string
+	// Args are extra Search filters, e.g. "language", "genre_ids" or "only_in"
+	Args map[string]string
+	// Window limits the search to episodes published in the window before each run, the engine default when zero
+	Window time.Duration
+	// Interval is how often the query runs, every Run tick when zero
+	Interval time.Duration
+}
+
+func (q Query) label() string {
+	if q.Name != "" {
+		return q.Name
+	}
+	return q.Q
+}
+
+// Match is an episode newly found by a query
+type Match struct {
+	QueryID   string
+	QueryName string
+
+	EpisodeID      string
+	Title          string
+	PubDate        time.Time
+	ListennotesURL string
+	Audio          string
+	PodcastID      string
+	PodcastTitle   string
+	Publisher      string
+
+	// The highlighted fields mark the matched words with <span class="ln-search-highlight">
+	TitleHighlighted       string
+	DescriptionHighlighted string
+	TranscriptsHighlighted []string
+}
+
+type searchResult struct {
+	ID                     string   `json:"id"`
+	Title                  string   `json:"title_original"`
+	TitleHighlighted       string   `json:"title_highlighted"`
+	DescriptionHighlighted string   `json:"description_highlighted"`
+	TranscriptsHighlighted []string `json:"transcripts_highlighted"`
+	PubDateMS              int64    `json:"pub_date_ms"`
+	ListennotesURL         string   `json:"listennotes_url"`
+	Audio                  string   `json:"audio"`
+	Podcast                struct {
+		ID        string `json:"id"`
+		Title     string `json:"title_original"`
+		Publisher string `json:"publisher_original"`
+	} `json:"podcast"`
+}
+
+// Engine runs saved queries and reports new matches
+type Engine struct {
+	client        listennotes.HTTPClient
+	seen          SeenStore
+	onMatches     func([]Match)
+	onError       func(error)
+	defaultWindow time.Duration
+	maxPages      int
+	now           func() time.Time
+
+	mu      sync.Mutex
+	queries map[string]Query
+	lastRun map[string]time.Time
+}
+
+// NewEngine will create an engine using the client for api calls and the store to remember reported episodes.
+// You can optionally override some configuration.
+func NewEngine(client listennotes.HTTPClient, seen SeenStore, opts ...Option) *Engine {
+	e := &Engine{
+		client:        client,
+		seen:          seen,
+		onMatches:     func([]Match) {},
+		onError:       func(error) {},
+		defaultWindow: 24 * time.Hour,
+		maxPages:      3,
+		now:           time.Now,
+		queries:       map[string]Query{},
+		lastRun:       map[string]time.Time{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Add saves the query, replacing a query with the same id
+func (e *Engine) Add(query Query) error {
+	if query.ID == "" {
+		return fmt.Errorf("alert query %q has no id", query.Q)
+	}
+	if query.Q == "" {
+		return fmt.Errorf("alert query %s has no search term", query.ID)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queries[query.ID] = query
+	return nil
+}
+
+// Remove deletes the saved query with the id
+func (e *Engine) Remove(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.queries, id)
+	delete(e.lastRun, id)
+}
+
+// Queries returns the saved queries ordered by id
+func (e *Engine) Queries() []Query {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	queries := make([]Query, 0, len(e.queries))
+	for _, q := range e.queries {
+		queries = append(queries, q)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].ID < queries[j].ID })
+	return queries
+}
+
+// Check runs the saved query with the id and returns its new matches, newest first.  The matches are remembered as
+// seen, they are not returned again.
+func (e *Engine) Check(id string) ([]Match, error) {
+	e.mu.Lock()
+	query, ok := e.queries[id]
+	e.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown alert query %s", id)
+	}
+	return e.check(query)
+}
+
+// CheckDue runs every saved query whose interval has passed since its last run and reports the new matches, if any,
+// to the match callback.  A failing query does not stop the others, the first error is returned.
+func (e *Engine) CheckDue() ([]Match, error) {
+	now := e.now()
+	var all []Match
+	var firstErr error
+	for _, query := range e.Queries() {
+		e.mu.Lock()
+		last, ran := e.lastRun[query.ID]
+		e.mu.Unlock()
+		if ran && query.Interval > 0 && now.Sub(last) < query.Interval {
+			continue
+		}
+
+		matches, err := e.check(query)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		all = append(all, matches...)
+	}
+
+	if len(all) > 0 {
+		e.onMatches(all)
+	}
+	return all, firstErr
+}
+
+// Run checks the due queries every interval until the context is done.  Errors of single runs are passed to the error
+// callback and retried on the next tick, only the context ends the loop.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.CheckDue(); err != nil {
+			e.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Engine) check(query Query) ([]Match, error) {
+	now := e.now()
+	window := query.Window
+	if window <= 0 {
+		window = e.defaultWindow
+	}
+
+	args := map[string]string{}
+	for k, v := range query.Args {
+		args[k] = v
+	}
+	args["q"] = query.Q
+	args["type"] = "episode"
+	args["sort_by_date"] = "1"
+	args["published_after"] = strconv.FormatInt(now.Add(-window).UnixNano()/int64(time.Millisecond), 10)
+
+	var matches []Match
+	var ids []string
+	pager := searchpage.New(e.client, args)
+	for page := 0; page < e.maxPages && pager.More(); page++ {
+		var results []searchResult
+		if err := pager.Next(&results); err != nil {
+			return nil, fmt.Errorf("failed to run alert query %s: %w", query.ID, err)
+		}
+
+		newOnPage := 0
+		for _, r := range results {
+			seen, err := e.seen.Seen(query.ID, r.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up seen episodes of %s: %w", query.ID, err)
+			}
+			if seen {
+				continue
+			}
+			newOnPage++
+			ids = append(ids, r.ID)
+			matches = append(matches, r.match(query))
+		}
+
+		// results are sorted by date, once a whole page holds known episodes the older pages were reported already
+		if newOnPage == 0 {
+			break
+		}
+	}
+
+	if len(ids) > 0 {
+		if err := e.seen.MarkSeen(query.ID, ids...); err != nil {
+			return nil, fmt.Errorf("failed to record seen episodes of %s: %w", query.ID, err)
+		}
+	}
+
+	e.mu.Lock()
+	e.lastRun[query.ID] = now
+	e.mu.Unlock()
+	return matches, nil
+}
+
+func (r searchResult) match(query Query) Match {
+	m := Match{
+		QueryID:                query.ID,
+		QueryName:              query.label(),
+		EpisodeID:              r.ID,
+		Title:                  r.Title,
+		ListennotesURL:         r.ListennotesURL,
+		Audio:                  r.Audio,
+		PodcastID:              r.Podcast.ID,
+		PodcastTitle:           r.Podcast.Title,
+		Publisher:              r.Podcast.Publisher,
+		TitleHighlighted:       r.TitleHighlighted,
+		DescriptionHighlighted: r.DescriptionHighlighted,
+		TranscriptsHighlighted: r.TranscriptsHighlighted,
+	}
+	if r.PubDateMS > 0 {
+		m.PubDate = time.Unix(0, r.PubDateMS*int64(time.Millisecond)).UTC()
+	}
+	return m
+}
@@ -0,0 +1,201 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	// episodes are the search results, newest first
+	episodes []map[string]interface{}
+	pageSize int
+	err      error
+	calls    []map[string]string
+}
+
+func (c *fakeClient) Search(args map[string]string) (*listennotes.Response, error) {
+	c.calls = append(c.calls, args)
+	if c.err != nil {
+		return nil, c.err
+	}
+	offset, _ := strconv.Atoi(args["offset"])
+	end := offset + c.pageSize
+	if end > len(c.episodes) {
+		end = len(c.episodes)
+	}
+	var results []interface{}
+	for _, e := range c.episodes[offset:end] {
+		results = append(results, e)
+	}
+	return &listennotes.Response{Data: map[string]interface{}{
+		"results":     results,
+		"next_offset": end,
+		"total":       len(c.episodes),
+	}}, nil
+}
+
+func episode(id string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                      id,
+		"title_original":          "Episode " + id,
+		"title_highlighted":       `Episode <span class="ln-search-highlight">` + id + `</span>`,
+		"description_highlighted": `...we talk about <span class="ln-search-highlight">Acme</span> <script>x</script>...`,
+		"transcripts_highlighted": []interface{}{`...thanks <span class="ln-search-highlight">Acme</span> for...`},
+		"pub_date_ms":             1579507216184,
+		"listennotes_url":         "https://www.listennotes.com/e/" + id + "/",
+		"podcast":                 map[string]interface{}{"id": "p1", "title_original": "The Show", "publisher_original": "Pub"},
+	}
+}
+
+func TestCheckReportsNewMatchesOnce(t *testing.T) {
+	client := &fakeClient{episodes: []map[string]interface{}{episode("3"), episode("2"), episode("1")}, pageSize: 2}
+	var reported []Match
+	engine := NewEngine(client, NewMemorySeenStore(), WithMatchFunc(func(m []Match) { reported = append(reported, m...) }))
+	now := time.Date(2021, 5, 3, 10, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	if err := engine.Add(Query{ID: "acme", Name: "Acme", Q: "acme", Args: map[string]string{"language": "English"}}); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	matches, err := engine.CheckDue()
+	if err != nil || len(matches) != 3 || len(reported) != 3 {
+		t.Fatalf("Expected 3 matches but got %v: %v", matches, err)
+	}
+	args := client.calls[0]
+	if args["sort_by_date"] != "1" || args["type"] != "episode" || args["language"] != "English" ||
+		args["published_after"] != strconv.FormatInt(now.Add(-24*time.Hour).Unix()*1000, 10) {
+		t.Errorf("Unexpected search args: %v", args)
+	}
+	if m := matches[0]; m.EpisodeID != "3" || m.QueryName != "Acme" || m.PodcastTitle != "The Show" ||
+		len(m.TranscriptsHighlighted) != 1 || m.PubDate.Year() != 2020 {
+		t.Errorf("Unexpected match: %+v", m)
+	}
+
+	client.episodes = append([]map[string]interface{}{episode("4")}, client.episodes...)
+	client.calls = nil
+	matches, _ = engine.CheckDue()
+	if len(matches) != 1 || matches[0].EpisodeID != "4" {
+		t.Errorf("Expected only the new episode but got %v", matches)
+	}
+	// the first page mixes the new episode with seen ones, the second page is all seen
+	if len(client.calls) != 2 {
+		t.Errorf("A page of seen episodes should end the run: %v", client.calls)
+	}
+}
+
+func TestCheckDueInterval(t *testing.T) {
+	client := &fakeClient{pageSize: 10}
+	engine := NewEngine(client, NewMemorySeenStore())
+	now := time.Date(2021, 5, 3, 10, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+	engine.Add(Query{ID: "a", Q: "a", Interval: time.Hour})
+	engine.Add(Query{ID: "b", Q: "b"})
+
+	engine.CheckDue()
+	now = now.Add(30 * time.Minute)
+	engine.CheckDue()
+	now = now.Add(30 * time.Minute)
+	engine.CheckDue()
+
+	counts := map[string]int{}
+	for _, c := range client.calls {
+		counts[c["q"]]++
+	}
+	if counts["a"] != 2 || counts["b"] != 3 {
+		t.Errorf("Unexpected runs: %v", counts)
+	}
+
+	if _, err := engine.Check("missing"); err == nil {
+		t.Error("Expected an error for an unknown query")
+	}
+}
+
+func TestRunReportsErrors(t *testing.T) {
+	client := &fakeClient{err: errors.New("boom")}
+	errs := make(chan error, 1)
+	engine := NewEngine(client, NewMemorySeenStore(), WithErrorFunc(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+	engine.Add(Query{ID: "acme", Q: "acme"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Run(ctx, time.Hour)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, client.err) {
+			t.Errorf("Expected the search error but got: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected Run to report the failed check")
+	}
+}
+
+func TestFileSeenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.json")
+	store, err := OpenFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if err := store.MarkSeen("q", "a", "b"); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	reopened, err := OpenFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if seen, _ := reopened.Seen("q", "b"); !seen {
+		t.Error("Expected the seen id to be persisted")
+	}
+	if seen, _ := reopened.Seen("other", "b"); seen {
+		t.Error("Seen ids are per query")
+	}
+}
+
+func TestDigest(t *testing.T) {
+	engine := NewEngine(&fakeClient{episodes: []map[string]interface{}{episode("1")}, pageSize: 10}, NewMemorySeenStore())
+	engine.Add(Query{ID: "acme", Name: "Acme", Q: "acme"})
+	matches, _ := engine.Check("acme")
+	digest := NewDigest("Brand mentions", matches)
+
+	markdown := digest.Markdown()
+	for _, expected := range []string{
+		"# Brand mentions",
+		"1 new episode for 1 alert.",
+		"## Acme (1)",
+		"### [Episode **1**](https://www.listennotes.com/e/1/)",
+		"The Show · Pub · Jan 20, 2020",
+		"> ...we talk about **Acme**",
+		"> 🎙 ...thanks **Acme** for...",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in markdown:\n%s", expected, markdown)
+		}
+	}
+
+	html := digest.HTML()
+	for _, expected := range []string{
+		`<h3><a href="https://www.listennotes.com/e/1/">Episode <b>1</b></a></h3>`,
+		"we talk about <b>Acme</b>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in html:\n%s", expected, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Errorf("Snippets should be sanitized:\n%s", html)
+	}
+}
@@ -0,0 +1,140 @@
+package alerts
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ListenNotes/podcast-api-go/description"
+)
+
+// highlightPattern matches the markup Search puts around matched words
+var highlightPattern = regexp.MustCompile(`<span class="ln-search-highlight">(.*?)</span>`)
+
+// Digest groups matches per query for an email body
+type Digest struct {
+	Title     string
+	Generated time.Time
+	Groups    []DigestGroup
+}
+
+// DigestGroup is the matches of a single query
+type DigestGroup struct {
+	QueryID   string
+	QueryName string
+	Matches   []Match
+}
+
+// NewDigest will group the matches per query, in the order the queries first appear
+func NewDigest(title string, matches []Match) Digest {
+	d := Digest{Title: title, Generated: time.Now()}
+	index := map[string]int{}
+	for _, m := range matches {
+		i, ok := index[m.QueryID]
+		if !ok {
+			i = len(d.Groups)
+			index[m.QueryID] = i
+			d.Groups = append(d.Groups, DigestGroup{QueryID: m.QueryID, QueryName: m.QueryName})
+		}
+		d.Groups[i].Matches = append(d.Groups[i].Matches, m)
+	}
+	return d
+}
+
+// Count is the number of matches in the digest
+func (d Digest) Count() int {
+	count := 0
+	for _, g := range d.Groups {
+		count += len(g.Matches)
+	}
+	return count
+}
+
+// Markdown renders the digest as a Markdown email body, highlights are in bold
+func (d Digest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", d.Title)
+	fmt.Fprintf(&b, "%d new %s for %d %s.\n", d.Count(), plural(d.Count(), "episode"),
+		len(d.Groups), plural(len(d.Groups), "alert"))
+
+	for _, g := range d.Groups {
+		fmt.Fprintf(&b, "\n## %s (%d)\n", g.QueryName, len(g.Matches))
+		for _, m := range g.Matches {
+			title := highlightMarkdown(m.TitleHighlighted)
+			if title == "" {
+				title = m.Title
+			}
+			fmt.Fprintf(&b, "\n### [%s](%s)\n\n", title, m.ListennotesURL)
+			fmt.Fprintf(&b, "%s\n", byline(m))
+			if snippet := highlightMarkdown(m.DescriptionHighlighted); snippet != "" {
+				fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(snippet, "\n", "\n> "))
+			}
+			for _, t := range m.TranscriptsHighlighted {
+				if snippet := highlightMarkdown(t); snippet != "" {
+					fmt.Fprintf(&b, "\n> 🎙 %s\n", strings.ReplaceAll(snippet, "\n", "\n> "))
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"highlight": highlightHTML,
+	"byline":    byline,
+	"plural":    plural,
+}).Parse(`<!DOCTYPE html>
+<html>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Count}} new {{plural .Count "episode"}} for {{len .Groups}} {{plural (len .Groups) "alert"}}.</p>
+{{range .Groups}}<h2>{{.QueryName}} ({{len .Matches}})</h2>
+{{range .Matches}}<div>
+<h3><a href="{{.ListennotesURL}}">{{if .TitleHighlighted}}{{highlight .TitleHighlighted}}{{else}}{{.Title}}{{end}}</a></h3>
+<p>{{byline .}}</p>
+{{if .DescriptionHighlighted}}<blockquote>{{highlight .DescriptionHighlighted}}</blockquote>
+{{end}}{{range .TranscriptsHighlighted}}<blockquote>&#127897; {{highlight .}}</blockquote>
+{{end}}</div>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// HTML renders the digest as an html email body, highlights are in bold
+func (d Digest) HTML() string {
+	var b bytes.Buffer
+	if err := digestTemplate.Execute(&b, d); err != nil {
+		// the template is fixed and the data only strings, this does not happen
+		return ""
+	}
+	return b.String()
+}
+
+func byline(m Match) string {
+	parts := []string{m.PodcastTitle}
+	if m.Publisher != "" {
+		parts = append(parts, m.Publisher)
+	}
+	if !m.PubDate.IsZero() {
+		parts = append(parts, m.PubDate.Format("Jan 2, 2006"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// highlightHTML turns the search highlight markup into bold text and sanitizes the rest of the snippet
+func highlightHTML(snippet string) template.HTML {
+	return template.HTML(description.Sanitize(highlightPattern.ReplaceAllString(snippet, "<b>$1</b>")))
+}
+
+func highlightMarkdown(snippet string) string {
+	return description.Markdown(highlightPattern.ReplaceAllString(snippet, "<b>$1</b>"))
+}
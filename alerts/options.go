@@ -0,0 +1,37 @@
+package alerts
+
+import (
+	"time"
+)
+
+// Option allows for options to be passed to the engine constructor function
+type Option func(e *Engine)
+
+// WithMatchFunc is called by CheckDue, and so by Run, with the new matches of all queries that ran
+func WithMatchFunc(fn func([]Match)) Option {
+	return func(e *Engine) {
+		e.onMatches = fn
+	}
+}
+
+// WithErrorFunc is called by Run with the error of each tick that failed, e.g. to log it.  The errors are dropped if
+// not provided.
+func WithErrorFunc(fn func(error)) Option {
+	return func(e *Engine) {
+		e.onError = fn
+	}
+}
+
+// WithDefaultWindow sets the published_after window of queries that do not set their own.  The default is 24 hours.
+func WithDefaultWindow(d time.Duration) Option {
+	return func(e *Engine) {
+		e.defaultWindow = d
+	}
+}
+
+// WithMaxPages caps the number of Search pages fetched per query run.  The default is 3.
+func WithMaxPages(n int) Option {
+	return func(e *Engine) {
+		e.maxPages = n
+	}
+}
@@ -0,0 +1,114 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ListenNotes/podcast-api-go/internal/atomicfile"
+)
+
+// SeenStore remembers which episodes were reported for each query.  Implementations must be safe for concurrent use.
+type SeenStore interface {
+	Seen(queryID, episodeID string) (bool, error)
+	MarkSeen(queryID string, episodeIDs ...string) error
+}
+
+// MemorySeenStore is a SeenStore that keeps ids in memory, state is lost on restart
+type MemorySeenStore struct {
+	mu   sync.Mutex
+	seen map[string]map[string]bool
+}
+
+var _ SeenStore = &MemorySeenStore{}
+
+// NewMemorySeenStore will create an empty in-memory store
+func NewMemorySeenStore() *MemorySeenStore {
+	return &MemorySeenStore{seen: map[string]map[string]bool{}}
+}
+
+// Seen implements SeenStore
+func (s *MemorySeenStore) Seen(queryID, episodeID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seen[queryID][episodeID], nil
+}
+
+// MarkSeen implements SeenStore
+func (s *MemorySeenStore) MarkSeen(queryID string, episodeIDs ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen[queryID] == nil {
+		s.seen[queryID] = map[string]bool{}
+	}
+	for _, id := range episodeIDs {
+		s.seen[queryID][id] = true
+	}
+	return nil
+}
+
+// FileSeenStore is a SeenStore persisted to a JSON file, rewritten on every MarkSeen
+type FileSeenStore struct {
+	path   string
+	memory *MemorySeenStore
+	mu     sync.Mutex
+}
+
+var _ SeenStore = &FileSeenStore{}
+
+// OpenFileSeenStore will load the store from path, a missing file is an empty store
+func OpenFileSeenStore(path string) (*FileSeenStore, error) {
+	s := &FileSeenStore{path: path, memory: NewMemorySeenStore()}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seen episodes %s: %w", path, err)
+	}
+
+	var stored map[string][]string
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse seen episodes %s: %w", path, err)
+	}
+	for queryID, ids := range stored {
+		s.memory.MarkSeen(queryID, ids...)
+	}
+	return s, nil
+}
+
+// Seen implements SeenStore
+func (s *FileSeenStore) Seen(queryID, episodeID string) (bool, error) {
+	return s.memory.Seen(queryID, episodeID)
+}
+
+// MarkSeen implements SeenStore
+func (s *FileSeenStore) MarkSeen(queryID string, episodeIDs ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memory.MarkSeen(queryID, episodeIDs...)
+
+	s.memory.mu.Lock()
+	stored := make(map[string][]string, len(s.memory.seen))
+	for q, ids := range s.memory.seen {
+		for id := range ids {
+			stored[q] = append(stored[q], id)
+		}
+		sort.Strings(stored[q])
+	}
+	s.memory.mu.Unlock()
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode seen episodes: %w", err)
+	}
+	if err := atomicfile.WriteFile(s.path, data); err != nil {
+		return fmt.Errorf("failed to write seen episodes: %w", err)
+	}
+	return nil
+}
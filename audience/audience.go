@@ -0,0 +1,280 @@
+// Package audience aggregates the audience demographics of FetchAudienceForPodcast across a portfolio of podcasts,
+// e.g. to quote the regional reach of a whole network.
+//
+// Each podcast's regional breakdown is weighted, by its listen score unless other weights are given, and combined
+// into a single breakdown:
+//
+//	aggregator := audience.NewAggregator(client)
+//	breakdown, err := aggregator.AggregateDomain("nytimes.com")
+//	for _, share := range breakdown.Regions {
+//		fmt.Printf("%s: %.2f%%\n", share.Region, share.Percent)
+//	}
+//
+// Podcasts without audience data are listed in Breakdown.Missing and left out of the weighted figures.
+package audience
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Member is a podcast of the portfolio
+type Member struct {
+	PodcastID   string
+	Title       string
+	ListenScore int
+	// Weight overrides the weight of the podcast when positive
+	Weight float64
+}
+
+// WeightFunc decides how much a podcast counts in the aggregate.  Podcasts weighing zero are left out.
+type WeightFunc func(m Member) float64
+
+// ByListenScore weighs podcasts by their Weight when set, otherwise by their listen score.  This is the default.
+// The api only returns listen scores on the PRO plan, see Aggregate for the fallback when no podcast has one.
+func ByListenScore(m Member) float64 {
+	if m.Weight > 0 {
+		return m.Weight
+	}
+	return float64(m.ListenScore)
+}
+
+// Equally weighs every podcast the same, unless it has a Weight
+func Equally(m Member) float64 {
+	if m.Weight > 0 {
+		return m.Weight
+	}
+	return 1
+}
+
+// RegionShare is the weighted share of the portfolio audience in a region
+type RegionShare struct {
+	Region string
+	// Percent is the weighted average share of the audience in percent
+	Percent float64
+	// Podcasts is the number of podcasts with audience in the region
+	Podcasts int
+}
+
+// MemberAudience is the audience data of a single podcast of the portfolio
+type MemberAudience struct {
+	Member   Member
+	Weight   float64
+	Audience listennotes.Audience
+}
+
+// Breakdown is the aggregated regional audience of a portfolio
+type Breakdown struct {
+	// Regions are ordered by share, largest first
+	Regions     []RegionShare
+	TotalWeight float64
+	Included    []MemberAudience
+	// Missing are the podcasts without audience data
+	Missing []Member
+	// Unweighted are the podcasts left out because their weight was zero
+	Unweighted []Member
+	// EqualWeights is set when the weight function left out every podcast and they were weighed Equally instead
+	EqualWeights bool
+	// Truncated is set when AggregateDomain reached the page cap before the last page of the domain's podcasts
+	Truncated bool
+}
+
+type domainPage struct {
+	Podcasts []listennotes.Podcast `json:"podcasts"`
+	HasNext  bool                  `json:"has_next"`
+}
+
+// Share returns the share of a region, zero for regions the portfolio has no reported audience in
+func (b Breakdown) Share(region string) float64 {
+	for _, r := range b.Regions {
+		if r.Region == region {
+			return r.Percent
+		}
+	}
+	return 0
+}
+
+// Aggregator fetches and combines audience data
+type Aggregator struct {
+	client      listennotes.HTTPClient
+	weight      WeightFunc
+	concurrency int
+	maxPages    int
+}
+
+// NewAggregator will create an aggregator with reasonable defaults.
+// You can optionally override some configuration.
+func NewAggregator(client listennotes.HTTPClient, opts ...Option) *Aggregator {
+	a := &Aggregator{
+		client:      client,
+		weight:      ByListenScore,
+		concurrency: 4,
+		maxPages:    50,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Aggregate fetches the audience of every member and combines them.  Members without audience data do not fail the
+// aggregate, any other api error does.
+// If the weight function leaves out every member, e.g. ByListenScore on the FREE plan which does not return listen
+// scores, the members are weighed Equally instead.
+func (a *Aggregator) Aggregate(members []Member) (Breakdown, error) {
+	breakdown := Breakdown{}
+	weight := a.weight
+	if !anyWeighted(members, weight) {
+		weight = Equally
+		breakdown.EqualWeights = len(members) > 0
+	}
+
+	var weighted []Member
+	for _, m := range members {
+		if weight(m) > 0 {
+			weighted = append(weighted, m)
+		} else {
+			breakdown.Unweighted = append(breakdown.Unweighted, m)
+		}
+	}
+
+	audiences := make([]*listennotes.Audience, len(weighted))
+	errs := make([]error, len(weighted))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < a.concurrency && w < len(weighted); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				audiences[i], errs[i] = a.fetch(weighted[i].PodcastID)
+			}
+		}()
+	}
+	for i := range weighted {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, m := range weighted {
+		if errs[i] != nil {
+			return Breakdown{}, errs[i]
+		}
+		if audiences[i] == nil {
+			breakdown.Missing = append(breakdown.Missing, m)
+			continue
+		}
+		breakdown.Included = append(breakdown.Included, MemberAudience{Member: m, Weight: weight(m), Audience: *audiences[i]})
+	}
+
+	breakdown.Regions, breakdown.TotalWeight = Combine(breakdown.Included)
+	return breakdown, nil
+}
+
+func anyWeighted(members []Member, weight WeightFunc) bool {
+	for _, m := range members {
+		if weight(m) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregateDomain aggregates every podcast FetchPodcastsByDomain returns for the publisher domain, up to the page cap
+// set with WithMaxPages
+func (a *Aggregator) AggregateDomain(domain string) (Breakdown, error) {
+	members, truncated, err := a.domainMembers(domain)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	breakdown, err := a.Aggregate(members)
+	breakdown.Truncated = truncated
+	return breakdown, err
+}
+
+// Combine computes the weighted regional breakdown of audiences.  A region missing from a podcast's audience counts
+// as zero for that podcast.
+func Combine(audiences []MemberAudience) ([]RegionShare, float64) {
+	total := 0.0
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, ma := range audiences {
+		total += ma.Weight
+		for _, r := range ma.Audience.ByRegions {
+			sums[r.Region] += ma.Weight * r.Percent
+			counts[r.Region]++
+		}
+	}
+	if total == 0 {
+		return nil, 0
+	}
+
+	shares := make([]RegionShare, 0, len(sums))
+	for region, sum := range sums {
+		shares = append(shares, RegionShare{Region: region, Percent: sum / total, Podcasts: counts[region]})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Percent != shares[j].Percent {
+			return shares[i].Percent > shares[j].Percent
+		}
+		return shares[i].Region < shares[j].Region
+	})
+	return shares, total
+}
+
+// fetch returns the audience of the podcast, nil when the podcast has none
+func (a *Aggregator) fetch(podcastID string) (*listennotes.Audience, error) {
+	resp, err := a.client.FetchAudienceForPodcast(podcastID, nil)
+	if errors.Is(err, listennotes.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audience of %s: %w", podcastID, err)
+	}
+
+	audience := &listennotes.Audience{}
+	if err := resp.Decode(audience); err != nil {
+		return nil, err
+	}
+	if len(audience.ByRegions) == 0 {
+		return nil, nil
+	}
+	return audience, nil
+}
+
+// domainMembers lists the podcasts of the domain, reporting whether the page cap cut the list short
+func (a *Aggregator) domainMembers(domain string) ([]Member, bool, error) {
+	var members []Member
+	for page := 1; page <= a.maxPages; page++ {
+		resp, err := a.client.FetchPodcastsByDomain(domain, map[string]string{"page": strconv.Itoa(page)})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to fetch podcasts of %s: %w", domain, err)
+		}
+
+		result := &domainPage{}
+		if err := resp.Decode(result); err != nil {
+			return nil, false, err
+		}
+		for _, p := range result.Podcasts {
+			m := Member{PodcastID: p.ID, Title: p.Title}
+			// listen_score is a notice string on plans without access to it
+			if score, ok := p.ListenScore.Value(); ok {
+				m.ListenScore = score
+			}
+			members = append(members, m)
+		}
+
+		if !result.HasNext {
+			return members, false, nil
+		}
+	}
+	return members, true, nil
+}
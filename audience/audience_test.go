@@ -0,0 +1,173 @@
+package audience
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	audiences map[string][]interface{}
+	pages     map[string][]interface{}
+}
+
+func region(code, ratio string) interface{} {
+	return map[string]interface{}{"region": code, "ratio": ratio}
+}
+
+func (c *fakeClient) FetchAudienceForPodcast(id string, args map[string]string) (*listennotes.Response, error) {
+	if id == "broken" {
+		return nil, listennotes.ErrInternalServerError
+	}
+	regions, ok := c.audiences[id]
+	if !ok {
+		return nil, listennotes.ErrNotFound
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"by_regions": regions}}, nil
+}
+
+func (c *fakeClient) FetchPodcastsByDomain(domain string, args map[string]string) (*listennotes.Response, error) {
+	podcasts := c.pages[args["page"]]
+	return &listennotes.Response{Data: map[string]interface{}{
+		"podcasts": podcasts,
+		"has_next": args["page"] == "1",
+	}}, nil
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAggregate(t *testing.T) {
+	client := &fakeClient{audiences: map[string][]interface{}{
+		"a":     {region("us", "50.00%"), region("gb", "10.00%")},
+		"b":     {region("us", "20.00%"), region("de", "40.00%")},
+		"empty": {},
+	}}
+	aggregator := NewAggregator(client)
+
+	breakdown, err := aggregator.Aggregate([]Member{
+		{PodcastID: "a", ListenScore: 60},
+		{PodcastID: "b", ListenScore: 20},
+		{PodcastID: "missing", ListenScore: 50},
+		{PodcastID: "empty", ListenScore: 50},
+		{PodcastID: "unscored"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	if breakdown.TotalWeight != 80 || len(breakdown.Included) != 2 {
+		t.Errorf("Unexpected included podcasts: %+v", breakdown)
+	}
+	if len(breakdown.Missing) != 2 || len(breakdown.Unweighted) != 1 {
+		t.Errorf("Unexpected missing %v and unweighted %v", breakdown.Missing, breakdown.Unweighted)
+	}
+	if breakdown.Regions[0].Region != "us" || !near(breakdown.Share("us"), 42.5) || breakdown.Regions[0].Podcasts != 2 {
+		t.Errorf("Unexpected us share: %+v", breakdown.Regions)
+	}
+	if !near(breakdown.Share("de"), 10) || !near(breakdown.Share("gb"), 7.5) || breakdown.Share("fr") != 0 {
+		t.Errorf("Unexpected shares: %+v", breakdown.Regions)
+	}
+
+	breakdown, _ = NewAggregator(client, WithWeightFunc(Equally)).Aggregate([]Member{
+		{PodcastID: "a"}, {PodcastID: "b", Weight: 3},
+	})
+	if !near(breakdown.Share("us"), 27.5) {
+		t.Errorf("Unexpected custom weighted share: %+v", breakdown.Regions)
+	}
+
+	if _, err := aggregator.Aggregate([]Member{{PodcastID: "broken", ListenScore: 1}}); !errors.Is(err, listennotes.ErrInternalServerError) {
+		t.Errorf("Expected api errors to fail the aggregate but got: %v", err)
+	}
+}
+
+func TestAggregateDomain(t *testing.T) {
+	client := &fakeClient{
+		audiences: map[string][]interface{}{
+			"a": {region("us", "100%")},
+			"b": {region("ca", "100%")},
+		},
+		pages: map[string][]interface{}{
+			"1": {map[string]interface{}{"id": "a", "title": "A", "listen_score": float64(30)}},
+			"2": {map[string]interface{}{"id": "b", "title": "B", "listen_score": float64(10)}},
+		},
+	}
+
+	breakdown, err := NewAggregator(client, WithConcurrency(1)).AggregateDomain("example.com")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !near(breakdown.Share("us"), 75) || !near(breakdown.Share("ca"), 25) || breakdown.Included[0].Member.Title != "A" {
+		t.Errorf("Unexpected breakdown: %+v", breakdown)
+	}
+}
+
+func TestAggregateFreePlan(t *testing.T) {
+	notice := "Please upgrade to PRO or ENTERPRISE plan to see Listen Score"
+	client := &fakeClient{
+		audiences: map[string][]interface{}{
+			"a": {region("us", "100%")},
+			"b": {region("ca", "100%")},
+		},
+		pages: map[string][]interface{}{
+			"1": {
+				map[string]interface{}{"id": "a", "title": "A", "listen_score": notice},
+				map[string]interface{}{"id": "b", "title": "B", "listen_score": notice},
+			},
+		},
+	}
+
+	breakdown, err := NewAggregator(client).AggregateDomain("example.com")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !breakdown.EqualWeights || len(breakdown.Unweighted) != 0 || breakdown.TotalWeight != 2 {
+		t.Errorf("Expected equal weights without listen scores: %+v", breakdown)
+	}
+	if !near(breakdown.Share("us"), 50) || !near(breakdown.Share("ca"), 50) {
+		t.Errorf("Unexpected shares: %+v", breakdown.Regions)
+	}
+
+	// a single scored podcast keeps the listen score weights
+	breakdown, _ = NewAggregator(client).Aggregate([]Member{{PodcastID: "a", ListenScore: 10}, {PodcastID: "b"}})
+	if breakdown.EqualWeights || len(breakdown.Unweighted) != 1 {
+		t.Errorf("Unexpected fallback: %+v", breakdown)
+	}
+}
+
+func TestAggregateDomainTruncated(t *testing.T) {
+	client := &fakeClient{
+		audiences: map[string][]interface{}{
+			"a": {region("us", "100%")},
+			"b": {region("ca", "100%")},
+		},
+		pages: map[string][]interface{}{
+			"1": {
+				map[string]interface{}{"id": "a", "title": "A", "listen_score": "30"},
+				map[string]interface{}{"id": "b", "title": "B", "listen_score": float64(10)},
+			},
+			"2": {map[string]interface{}{"id": "c", "title": "C", "listen_score": float64(50)}},
+		},
+	}
+
+	breakdown, err := NewAggregator(client, WithMaxPages(1)).AggregateDomain("example.com")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !breakdown.Truncated {
+		t.Errorf("Expected the breakdown to be marked truncated at the page cap")
+	}
+	// a numeric string score weighs like a number
+	if !near(breakdown.Share("us"), 75) || breakdown.TotalWeight != 40 {
+		t.Errorf("Unexpected breakdown: %+v", breakdown)
+	}
+
+	breakdown, _ = NewAggregator(client).AggregateDomain("example.com")
+	if breakdown.Truncated {
+		t.Errorf("A complete domain should not be truncated")
+	}
+}
@@ -0,0 +1,27 @@
+package audience
+
+// Option allows for options to be passed to the aggregator constructor function
+type Option func(a *Aggregator)
+
+// WithWeightFunc sets how podcasts are weighted, ByListenScore by default
+func WithWeightFunc(fn WeightFunc) Option {
+	return func(a *Aggregator) {
+		a.weight = fn
+	}
+}
+
+// WithConcurrency sets how many audience requests are in flight at once.  The default is 4.
+func WithConcurrency(n int) Option {
+	return func(a *Aggregator) {
+		if n > 0 {
+			a.concurrency = n
+		}
+	}
+}
+
+// WithMaxPages caps the number of FetchPodcastsByDomain pages read by AggregateDomain.  The default is 50.
+func WithMaxPages(n int) Option {
+	return func(a *Aggregator) {
+		a.maxPages = n
+	}
+}
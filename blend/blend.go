@@ -0,0 +1,369 @@
+// Package blend builds personalized "because you listened to" recommendations from a set of seed podcasts and
+// episodes.
+//
+// The recommendations of every seed are fetched, merged and re-ranked on how many seeds recommend an item, its listen
+// score and how well its genres fit the seeds.  Items the user already has are dropped and the list is diversified so
+// that a single show or publisher does not take over:
+//
+//	blender := blend.NewBlender(client, blend.WithSafeMode(), blend.WithLimit(20))
+//	items, err := blender.Blend([]blend.Seed{
+//		{ID: "4d3fe717742d4963a85562e9f84d8c79", Kind: blend.KindPodcast, Title: "Star Wars 7x7", Weight: 2},
+//		{ID: "6b6d65930c5a4f71b254465871fed370", Kind: blend.KindEpisode, Weight: 1},
+//	}, owned)
+//	for _, item := range items {
+//		fmt.Println(item.Title, item.Reasons)
+//	}
+package blend
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Kind is whether an item is a podcast or an episode
+type Kind string
+
+// Item kinds
+const (
+	KindPodcast Kind = "podcast"
+	KindEpisode Kind = "episode"
+)
+
+// Score weights
+const (
+	weightFrequency   = 0.6
+	weightListenScore = 0.2
+	weightGenres      = 0.2
+)
+
+// Seed is a podcast or episode the user listened to
+type Seed struct {
+	ID   string
+	Kind Kind
+	// Title is used in the reasons, the id when empty
+	Title string
+	// Weight is how much the seed counts, e.g. listening time.  Zero counts as 1.
+	Weight float64
+	// GenreIDs describe the user's taste, when no seed has genres the genres of the candidates are used
+	GenreIDs []int
+}
+
+func (s Seed) label() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.ID
+}
+
+// Item is a recommended podcast or episode
+type Item struct {
+	ID    string
+	Kind  Kind
+	Title string
+	// PodcastID and PodcastTitle are the item itself for podcasts, the podcast of the episode otherwise
+	PodcastID    string
+	PodcastTitle string
+	Publisher    string
+	GenreIDs     []int
+	ListenScore  int
+	Explicit     bool
+
+	Score float64
+	// BecauseOf are the ids of the seeds recommending the item, most relevant first
+	BecauseOf []string
+	// Reasons explain the score in words, e.g. "Because you listened to Star Wars 7x7"
+	Reasons []string
+
+	// frequency is the seed weight times rank weight, summed over the seeds recommending the item
+	frequency float64
+	seedRank  map[string]float64
+}
+
+// Blender merges the recommendations of several seeds
+type Blender struct {
+	client          listennotes.HTTPClient
+	safeMode        bool
+	limit           int
+	concurrency     int
+	publisherDecay  float64
+	maxPerPublisher int
+}
+
+// NewBlender will create a blender with reasonable defaults.
+// You can optionally override some configuration.
+func NewBlender(client listennotes.HTTPClient, opts ...Option) *Blender {
+	b := &Blender{
+		client:          client,
+		limit:           20,
+		concurrency:     4,
+		publisherDecay:  0.7,
+		maxPerPublisher: 3,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Blend returns the ranked recommendations for the seeds.  owned are the ids of podcasts and episodes the user already
+// has, they are left out along with the seeds themselves.
+func (b *Blender) Blend(seeds []Seed, owned []string) ([]Item, error) {
+	skip := map[string]bool{}
+	for _, id := range owned {
+		skip[id] = true
+	}
+	for _, seed := range seeds {
+		skip[seed.ID] = true
+	}
+
+	recs, err := b.fetchAll(seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	items := map[string]*Item{}
+	var order []string
+	for i, seed := range seeds {
+		weight := seed.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for rank, rec := range recs[i] {
+			if skip[rec.ID] || (b.safeMode && rec.Explicit) {
+				continue
+			}
+			item, ok := items[rec.ID]
+			if !ok {
+				r := rec
+				r.seedRank = map[string]float64{}
+				item = &r
+				items[rec.ID] = item
+				order = append(order, rec.ID)
+			}
+			contribution := weight * float64(len(recs[i])-rank) / float64(len(recs[i]))
+			if _, seen := item.seedRank[seed.ID]; !seen {
+				item.BecauseOf = append(item.BecauseOf, seed.ID)
+			}
+			item.seedRank[seed.ID] += contribution
+			item.frequency += contribution
+		}
+	}
+
+	profile := genreProfile(seeds, items)
+	maxFrequency := 0.0
+	for _, item := range items {
+		if item.frequency > maxFrequency {
+			maxFrequency = item.frequency
+		}
+	}
+
+	labels := map[string]string{}
+	for _, seed := range seeds {
+		labels[seed.ID] = seed.label()
+	}
+
+	ranked := make([]*Item, 0, len(order))
+	for _, id := range order {
+		item := items[id]
+		genres := genreOverlap(item.GenreIDs, profile)
+		item.Score = weightFrequency*item.frequency/maxFrequency +
+			weightListenScore*float64(item.ListenScore)/100 +
+			weightGenres*genres
+
+		sort.SliceStable(item.BecauseOf, func(i, j int) bool {
+			return item.seedRank[item.BecauseOf[i]] > item.seedRank[item.BecauseOf[j]]
+		})
+		item.Reasons = append(item.Reasons, "Because you listened to "+labels[item.BecauseOf[0]])
+		if len(item.BecauseOf) > 1 {
+			item.Reasons = append(item.Reasons, fmt.Sprintf("Recommended from %d of your shows", len(item.BecauseOf)))
+		}
+		if item.ListenScore > 0 {
+			item.Reasons = append(item.Reasons, fmt.Sprintf("Listen score %d", item.ListenScore))
+		}
+		if genres >= 0.5 {
+			item.Reasons = append(item.Reasons, "Matches your genres")
+		}
+		ranked = append(ranked, item)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+
+	return b.diversify(ranked), nil
+}
+
+// diversify picks items greedily by score, discounting items of a podcast or publisher that was picked already
+func (b *Blender) diversify(ranked []*Item) []Item {
+	picked := []Item{}
+	perPublisher := map[string]int{}
+	remaining := ranked
+	for len(remaining) > 0 && (b.limit <= 0 || len(picked) < b.limit) {
+		best, bestScore := -1, 0.0
+		for i, item := range remaining {
+			count := perPublisher[publisherKey(item)]
+			if b.maxPerPublisher > 0 && count >= b.maxPerPublisher {
+				continue
+			}
+			score := item.Score
+			for c := 0; c < count; c++ {
+				score *= b.publisherDecay
+			}
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		item := remaining[best]
+		perPublisher[publisherKey(item)]++
+		item.seedRank = nil
+		picked = append(picked, *item)
+		remaining = append(remaining[:best:best], remaining[best+1:]...)
+	}
+	return picked
+}
+
+func publisherKey(item *Item) string {
+	if item.Publisher != "" {
+		return "publisher " + item.Publisher
+	}
+	return "podcast " + item.PodcastID
+}
+
+// fetchAll fetches the recommendations of every seed with bounded concurrency, in the order of seeds
+func (b *Blender) fetchAll(seeds []Seed) ([][]Item, error) {
+	recs := make([][]Item, len(seeds))
+	errs := make([]error, len(seeds))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < b.concurrency && w < len(seeds); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				recs[i], errs[i] = b.fetch(seeds[i])
+			}
+		}()
+	}
+	for i := range seeds {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return recs, nil
+}
+
+func (b *Blender) fetch(seed Seed) ([]Item, error) {
+	args := map[string]string{}
+	if b.safeMode {
+		args["safe_mode"] = "1"
+	}
+
+	var resp *listennotes.Response
+	var err error
+	if seed.Kind == KindEpisode {
+		resp, err = b.client.FetchRecommendationsForEpisode(seed.ID, args)
+	} else {
+		resp, err = b.client.FetchRecommendationsForPodcast(seed.ID, args)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recommendations for %s: %w", seed.ID, err)
+	}
+
+	var items []Item
+	if seed.Kind == KindEpisode {
+		var episodes struct {
+			Recommendations []listennotes.Episode `json:"recommendations"`
+		}
+		if err := resp.Decode(&episodes); err != nil {
+			return nil, err
+		}
+		for _, e := range episodes.Recommendations {
+			item := Item{ID: e.ID, Kind: KindEpisode, Title: e.Title, Explicit: e.ExplicitContent}
+			if e.Podcast != nil {
+				item.PodcastID = e.Podcast.ID
+				item.PodcastTitle = e.Podcast.Title
+				item.Publisher = e.Podcast.Publisher
+				item.GenreIDs = e.Podcast.GenreIDs
+				item.ListenScore, _ = e.Podcast.ListenScore.Value()
+				item.Explicit = item.Explicit || e.Podcast.ExplicitContent
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	var podcasts struct {
+		Recommendations []listennotes.Podcast `json:"recommendations"`
+	}
+	if err := resp.Decode(&podcasts); err != nil {
+		return nil, err
+	}
+	for _, p := range podcasts.Recommendations {
+		score, _ := p.ListenScore.Value()
+		items = append(items, Item{
+			ID:           p.ID,
+			Kind:         KindPodcast,
+			Title:        p.Title,
+			PodcastID:    p.ID,
+			PodcastTitle: p.Title,
+			Publisher:    p.Publisher,
+			GenreIDs:     p.GenreIDs,
+			ListenScore:  score,
+			Explicit:     p.ExplicitContent,
+		})
+	}
+	return items, nil
+}
+
+// genreProfile weighs genres by how much they represent the user's taste, between 0 and 1
+func genreProfile(seeds []Seed, items map[string]*Item) map[int]float64 {
+	counts := map[int]float64{}
+	for _, seed := range seeds {
+		for _, g := range seed.GenreIDs {
+			counts[g]++
+		}
+	}
+	if len(counts) == 0 {
+		// without seed genres, the genres most recommended across the seeds stand in for the user's taste
+		for _, item := range items {
+			for _, g := range item.GenreIDs {
+				counts[g] += item.frequency
+			}
+		}
+	}
+
+	max := 0.0
+	for _, c := range counts {
+		if c > max {
+			max = c
+		}
+	}
+	profile := map[int]float64{}
+	for g, c := range counts {
+		profile[g] = c / max
+	}
+	return profile
+}
+
+// genreOverlap is the best profile weight among the genres, so that a single strong genre match is enough
+func genreOverlap(genres []int, profile map[int]float64) float64 {
+	best := 0.0
+	for _, g := range genres {
+		if profile[g] > best {
+			best = profile[g]
+		}
+	}
+	return best
+}
@@ -0,0 +1,100 @@
+package blend
+
+import (
+	"strings"
+	"testing"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	podcasts map[string][]interface{}
+	episodes map[string][]interface{}
+	args     []map[string]string
+}
+
+func (c *fakeClient) FetchRecommendationsForPodcast(id string, args map[string]string) (*listennotes.Response, error) {
+	c.args = append(c.args, args)
+	return &listennotes.Response{Data: map[string]interface{}{"recommendations": c.podcasts[id]}}, nil
+}
+
+func (c *fakeClient) FetchRecommendationsForEpisode(id string, args map[string]string) (*listennotes.Response, error) {
+	c.args = append(c.args, args)
+	return &listennotes.Response{Data: map[string]interface{}{"recommendations": c.episodes[id]}}, nil
+}
+
+func podcast(id, publisher string, score int, explicit bool, genres ...interface{}) interface{} {
+	return map[string]interface{}{
+		"id": id, "title": "Show " + id, "publisher": publisher, "listen_score": float64(score),
+		"explicit_content": explicit, "genre_ids": genres,
+	}
+}
+
+func ids(items []Item) string {
+	var list []string
+	for _, item := range items {
+		list = append(list, item.ID)
+	}
+	return strings.Join(list, ",")
+}
+
+func TestBlend(t *testing.T) {
+	client := &fakeClient{
+		podcasts: map[string][]interface{}{
+			"s1": {podcast("a", "P1", 40, false, 68.0), podcast("b", "P2", 90, false, 100.0), podcast("owned", "P3", 50, false)},
+			"s2": {podcast("a", "P1", 40, false, 68.0), podcast("x", "P4", 80, true, 68.0)},
+		},
+		episodes: map[string][]interface{}{
+			"e1": {map[string]interface{}{"id": "ep", "title": "An episode", "podcast": map[string]interface{}{
+				"id": "c", "title": "Show c", "publisher": "P5", "listen_score": float64(10), "genre_ids": []interface{}{68.0},
+			}}},
+		},
+	}
+	blender := NewBlender(client)
+
+	items, err := blender.Blend([]Seed{
+		{ID: "s1", Kind: KindPodcast, Title: "First show"},
+		{ID: "s2", Kind: KindPodcast, Weight: 2},
+		{ID: "e1", Kind: KindEpisode},
+	}, []string{"owned"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	if ids(items) != "a,x,ep,b" {
+		t.Errorf("Unexpected ranking: %s", ids(items))
+	}
+	a := items[0]
+	if len(a.BecauseOf) != 2 || a.BecauseOf[0] != "s2" || a.Reasons[0] != "Because you listened to s2" ||
+		a.Reasons[1] != "Recommended from 2 of your shows" {
+		t.Errorf("Unexpected reasons: %+v", a)
+	}
+	if ep := items[2]; ep.Kind != KindEpisode || ep.PodcastTitle != "Show c" || ep.Reasons[0] != "Because you listened to e1" {
+		t.Errorf("Unexpected episode item: %+v", ep)
+	}
+
+	safe, _ := NewBlender(client, WithSafeMode()).Blend([]Seed{{ID: "s2", Kind: KindPodcast}}, nil)
+	if ids(safe) != "a" || client.args[len(client.args)-1]["safe_mode"] != "1" {
+		t.Errorf("Expected explicit items to be dropped in safe mode: %s", ids(safe))
+	}
+}
+
+func TestBlendDiversity(t *testing.T) {
+	client := &fakeClient{podcasts: map[string][]interface{}{
+		"s": {
+			podcast("a1", "Big", 50, false), podcast("a2", "Big", 50, false), podcast("a3", "Big", 50, false),
+			podcast("b1", "Small", 50, false),
+		},
+	}}
+
+	items, _ := NewBlender(client, WithDiversity(0.3, 2)).Blend([]Seed{{ID: "s"}}, nil)
+	if ids(items) != "a1,b1,a2" {
+		t.Errorf("Expected the list to be diversified: %s", ids(items))
+	}
+
+	items, _ = NewBlender(client, WithDiversity(1, 0), WithLimit(3)).Blend([]Seed{{ID: "s"}}, nil)
+	if ids(items) != "a1,a2,a3" {
+		t.Errorf("Expected no diversification: %s", ids(items))
+	}
+}
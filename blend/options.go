@@ -0,0 +1,37 @@
+package blend
+
+// Option allows for options to be passed to the blender constructor function
+type Option func(b *Blender)
+
+// WithSafeMode asks the api for safe recommendations and drops explicit podcasts and episodes
+func WithSafeMode() Option {
+	return func(b *Blender) {
+		b.safeMode = true
+	}
+}
+
+// WithLimit caps the length of the blended list.  The default is 20, zero returns every item.
+func WithLimit(n int) Option {
+	return func(b *Blender) {
+		b.limit = n
+	}
+}
+
+// WithConcurrency sets how many recommendation requests are in flight at once.  The default is 4.
+func WithConcurrency(n int) Option {
+	return func(b *Blender) {
+		if n > 0 {
+			b.concurrency = n
+		}
+	}
+}
+
+// WithDiversity sets how strongly the list is diversified.  Every item of a publisher already in the list multiplies
+// the score of the next one by decay, and no publisher gets more than maxPerPublisher items.  The defaults are 0.7
+// and 3, a decay of 1 and a max of zero turn diversification off.
+func WithDiversity(decay float64, maxPerPublisher int) Option {
+	return func(b *Blender) {
+		b.publisherDecay = decay
+		b.maxPerPublisher = maxPerPublisher
+	}
+}
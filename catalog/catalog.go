@@ -0,0 +1,212 @@
+// Package catalog crawls the podcasts of publisher domains through FetchPodcastsByDomain and summarizes each
+// publisher's catalog.
+//
+// Progress is saved to a Store after every page, so an interrupted crawl picks up where it stopped:
+//
+//	store, err := catalog.NewFileStore("/var/lib/crawl")
+//	if err != nil {
+//		return err
+//	}
+//	crawler := catalog.NewCrawler(client, store, catalog.WithLatestEpisodes(3), catalog.WithAudience())
+//	catalogs, err := crawler.Crawl(ctx, []string{"nytimes.com", "npr.org", "wondery.com"})
+//	for _, c := range catalogs {
+//		fmt.Println(c.Domain, c.Shows, c.Episodes, c.MedianUpdateFrequency, c.AverageListenScore)
+//	}
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Podcast is a podcast of a publisher with its optional enrichments
+type Podcast struct {
+	listennotes.Podcast
+	// LatestEpisodes are the most recent episodes, newest first, when the crawler was asked for them
+	LatestEpisodes []listennotes.Episode `json:"latest_episodes,omitempty"`
+	// Audience is set when the crawler was asked for audience data and the podcast has some
+	Audience *listennotes.Audience `json:"audience,omitempty"`
+}
+
+// DomainState is the crawl progress of a single publisher domain
+type DomainState struct {
+	Domain string `json:"domain"`
+	// NextPage is the next FetchPodcastsByDomain page to fetch
+	NextPage  int       `json:"next_page"`
+	Done      bool      `json:"done"`
+	Podcasts  []Podcast `json:"podcasts"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type domainPage struct {
+	HasNext  bool                  `json:"has_next"`
+	Podcasts []listennotes.Podcast `json:"podcasts"`
+}
+
+// Crawler pages through publisher domains
+type Crawler struct {
+	client         listennotes.HTTPClient
+	store          Store
+	latestEpisodes int
+	audience       bool
+	maxPages       int
+	now            func() time.Time
+}
+
+// NewCrawler will create a crawler using the client for api calls and the store for progress.
+// You can optionally override some configuration.
+func NewCrawler(client listennotes.HTTPClient, store Store, opts ...Option) *Crawler {
+	c := &Crawler{
+		client:   client,
+		store:    store,
+		maxPages: 1000,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Crawl fetches every podcast of the domains and returns the catalog of each, in the order of domains.  Domains that
+// were crawled completely before are read from the store without api calls, see Reset to crawl them again.  A failing
+// domain does not stop the others, its catalog summarizes the podcasts crawled so far with the error in Catalog.Err,
+// and the first error is returned.  When the context is done the crawl stops after the current page, returning the
+// catalogs so far and the context error.
+func (c *Crawler) Crawl(ctx context.Context, domains []string) ([]Catalog, error) {
+	var catalogs []Catalog
+	var firstErr error
+	for _, domain := range domains {
+		state, err := c.CrawlDomain(ctx, domain)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return catalogs, ctxErr
+		}
+		catalog := Summarize(state)
+		if err != nil {
+			catalog.Domain = normalizeDomain(domain)
+			catalog.Err = err
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		catalogs = append(catalogs, catalog)
+	}
+	return catalogs, firstErr
+}
+
+// Reset forgets the stored progress of the domain, so that the next crawl fetches it again from the first page
+func (c *Crawler) Reset(domain string) error {
+	domain = normalizeDomain(domain)
+	if err := c.store.Save(DomainState{Domain: domain, NextPage: 1, UpdatedAt: c.now()}); err != nil {
+		return fmt.Errorf("failed to reset crawl state of %s: %w", domain, err)
+	}
+	return nil
+}
+
+// CrawlDomain fetches every podcast of a single domain, resuming from the stored progress
+func (c *Crawler) CrawlDomain(ctx context.Context, domain string) (DomainState, error) {
+	domain = normalizeDomain(domain)
+	state, ok, err := c.store.Load(domain)
+	if err != nil {
+		return DomainState{}, fmt.Errorf("failed to load crawl state of %s: %w", domain, err)
+	}
+	if !ok {
+		state = DomainState{Domain: domain, NextPage: 1}
+	}
+
+	for !state.Done {
+		if err := ctx.Err(); err != nil {
+			return state, err
+		}
+		if state.NextPage > c.maxPages {
+			return state, fmt.Errorf("crawl of %s stopped after %d pages", domain, c.maxPages)
+		}
+
+		resp, err := c.client.FetchPodcastsByDomain(domain, map[string]string{"page": strconv.Itoa(state.NextPage)})
+		if err != nil {
+			return state, fmt.Errorf("failed to fetch page %d of %s: %w", state.NextPage, domain, err)
+		}
+		page := &domainPage{}
+		if err := resp.Decode(page); err != nil {
+			return state, err
+		}
+
+		// the page is only saved once all of it is enriched, a failure repeats the whole page on resume
+		podcasts := make([]Podcast, 0, len(page.Podcasts))
+		for _, p := range page.Podcasts {
+			podcast, err := c.enrich(p)
+			if err != nil {
+				return state, err
+			}
+			podcasts = append(podcasts, podcast)
+		}
+
+		state.Podcasts = append(state.Podcasts, podcasts...)
+		state.NextPage++
+		state.Done = !page.HasNext || len(page.Podcasts) == 0
+		state.UpdatedAt = c.now()
+		if err := c.store.Save(state); err != nil {
+			return state, fmt.Errorf("failed to save crawl state of %s: %w", domain, err)
+		}
+	}
+	return state, nil
+}
+
+func (c *Crawler) enrich(p listennotes.Podcast) (Podcast, error) {
+	podcast := Podcast{Podcast: p}
+
+	if c.latestEpisodes > 0 {
+		resp, err := c.client.FetchPodcastByID(p.ID, map[string]string{"sort": "recent_first"})
+		if err != nil {
+			return Podcast{}, fmt.Errorf("failed to fetch episodes of %s: %w", p.ID, err)
+		}
+		detail := &listennotes.Podcast{}
+		if err := resp.Decode(detail); err != nil {
+			return Podcast{}, err
+		}
+		podcast.LatestEpisodes = detail.Episodes
+		if len(podcast.LatestEpisodes) > c.latestEpisodes {
+			podcast.LatestEpisodes = podcast.LatestEpisodes[:c.latestEpisodes]
+		}
+	}
+
+	if c.audience {
+		resp, err := c.client.FetchAudienceForPodcast(p.ID, nil)
+		switch {
+		case errors.Is(err, listennotes.ErrNotFound):
+			// no audience data for this podcast
+		case err != nil:
+			return Podcast{}, fmt.Errorf("failed to fetch audience of %s: %w", p.ID, err)
+		default:
+			audience := &listennotes.Audience{}
+			if err := resp.Decode(audience); err != nil {
+				return Podcast{}, err
+			}
+			if len(audience.ByRegions) > 0 {
+				podcast.Audience = audience
+			}
+		}
+	}
+
+	return podcast, nil
+}
+
+// normalizeDomain accepts domains written as urls, e.g. "https://www.nytimes.com/"
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "https://")
+	domain = strings.TrimPrefix(domain, "http://")
+	domain = strings.TrimPrefix(domain, "www.")
+	if i := strings.Index(domain, "/"); i >= 0 {
+		domain = domain[:i]
+	}
+	return domain
+}
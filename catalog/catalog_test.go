@@ -0,0 +1,170 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	pages     [][]interface{}
+	failPage  string
+	notFound  string
+	pageCalls []string
+}
+
+func podcast(id string, episodes, frequency, score int) interface{} {
+	return map[string]interface{}{
+		"id":                     id,
+		"title":                  "Podcast " + id,
+		"total_episodes":         float64(episodes),
+		"update_frequency_hours": float64(frequency),
+		"listen_score":           float64(score),
+	}
+}
+
+func (c *fakeClient) FetchPodcastsByDomain(domain string, args map[string]string) (*listennotes.Response, error) {
+	c.pageCalls = append(c.pageCalls, domain+" "+args["page"])
+	if domain == c.notFound {
+		return nil, listennotes.ErrNotFound
+	}
+	if args["page"] == c.failPage {
+		c.failPage = ""
+		return nil, listennotes.ErrTooManyRequests
+	}
+	page := int(args["page"][0] - '0')
+	return &listennotes.Response{Data: map[string]interface{}{
+		"podcasts": c.pages[page-1],
+		"has_next": page < len(c.pages),
+	}}, nil
+}
+
+func (c *fakeClient) FetchPodcastByID(id string, args map[string]string) (*listennotes.Response, error) {
+	return &listennotes.Response{Data: map[string]interface{}{
+		"id":       id,
+		"episodes": []interface{}{map[string]interface{}{"id": id + "-e2"}, map[string]interface{}{"id": id + "-e1"}},
+	}}, nil
+}
+
+func (c *fakeClient) FetchAudienceForPodcast(id string, args map[string]string) (*listennotes.Response, error) {
+	if id == "b" {
+		return nil, listennotes.ErrNotFound
+	}
+	return &listennotes.Response{Data: map[string]interface{}{
+		"by_regions": []interface{}{map[string]interface{}{"region": "us", "ratio": "80.00%"}},
+	}}, nil
+}
+
+func TestCrawlResumes(t *testing.T) {
+	client := &fakeClient{
+		pages: [][]interface{}{
+			{podcast("a", 100, 168, 60), podcast("b", 20, 24, 0)},
+			{podcast("c", 10, 48, 40)},
+			{podcast("d", 5, 0, 50)},
+		},
+		failPage: "2",
+	}
+	store := NewMemoryStore()
+	crawler := NewCrawler(client, store, WithLatestEpisodes(1), WithAudience())
+
+	if _, err := crawler.Crawl(context.Background(), []string{"https://www.Example.com/"}); !errors.Is(err, listennotes.ErrTooManyRequests) {
+		t.Fatalf("Expected the crawl to be interrupted but got: %v", err)
+	}
+	if state, ok, _ := store.Load("example.com"); !ok || state.NextPage != 2 || len(state.Podcasts) != 2 {
+		t.Fatalf("Expected the progress of the first page to be saved: %+v", state)
+	}
+
+	catalogs, err := crawler.Crawl(context.Background(), []string{"example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	expectedCalls := []string{"example.com 1", "example.com 2", "example.com 2", "example.com 3"}
+	if len(client.pageCalls) != len(expectedCalls) {
+		t.Fatalf("Expected the crawl to resume at page 2 but got %v", client.pageCalls)
+	}
+
+	c := catalogs[0]
+	if !c.Complete || c.Shows != 4 || c.Episodes != 135 || c.MedianUpdateFrequency != 48*time.Hour || c.AverageListenScore != 50 {
+		t.Errorf("Unexpected catalog: %+v", c)
+	}
+	a := c.Podcasts[0]
+	if len(a.LatestEpisodes) != 1 || a.LatestEpisodes[0].ID != "a-e2" || a.Audience == nil || a.Audience.ByRegions[0].Percent != 80 {
+		t.Errorf("Unexpected enrichment: %+v", a)
+	}
+	if c.Podcasts[1].Audience != nil {
+		t.Errorf("A podcast without audience data should have none: %+v", c.Podcasts[1])
+	}
+
+	client.pageCalls = nil
+	crawler.Crawl(context.Background(), []string{"example.com"})
+	if len(client.pageCalls) != 0 {
+		t.Errorf("A completed domain should not be crawled again: %v", client.pageCalls)
+	}
+}
+
+func TestCrawlContinuesAfterFailedDomain(t *testing.T) {
+	client := &fakeClient{pages: [][]interface{}{{podcast("a", 10, 24, 50)}}, notFound: "unknown.example"}
+	crawler := NewCrawler(client, NewMemoryStore())
+
+	catalogs, err := crawler.Crawl(context.Background(), []string{"unknown.example", "example.com"})
+	if !errors.Is(err, listennotes.ErrNotFound) {
+		t.Errorf("Expected the error of the failed domain but got: %v", err)
+	}
+	if len(catalogs) != 2 || catalogs[0].Domain != "unknown.example" || !errors.Is(catalogs[0].Err, listennotes.ErrNotFound) ||
+		catalogs[0].Complete {
+		t.Fatalf("Expected the failed domain to be reported: %+v", catalogs)
+	}
+	if catalogs[1].Err != nil || !catalogs[1].Complete || catalogs[1].Shows != 1 {
+		t.Errorf("Expected the other domain to be crawled: %+v", catalogs[1])
+	}
+}
+
+func TestReset(t *testing.T) {
+	client := &fakeClient{pages: [][]interface{}{{podcast("a", 10, 24, 50)}}}
+	crawler := NewCrawler(client, NewMemoryStore())
+	crawler.Crawl(context.Background(), []string{"example.com"})
+
+	client.pages = [][]interface{}{{podcast("a", 11, 24, 50), podcast("b", 1, 24, 50)}}
+	if err := crawler.Reset("https://www.example.com/"); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	catalogs, err := crawler.Crawl(context.Background(), []string{"example.com"})
+	if err != nil || catalogs[0].Shows != 2 || catalogs[0].Episodes != 12 {
+		t.Errorf("Expected a fresh crawl after the reset but got %+v: %v", catalogs, err)
+	}
+	if len(client.pageCalls) != 2 {
+		t.Errorf("Expected the first page to be fetched again: %v", client.pageCalls)
+	}
+}
+
+func TestCrawlCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	crawler := NewCrawler(&fakeClient{}, NewMemoryStore())
+	if _, err := crawler.Crawl(ctx, []string{"example.com"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context error but got: %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if _, ok, err := store.Load("npr.org"); ok || err != nil {
+		t.Errorf("Expected no state but got %v: %v", ok, err)
+	}
+
+	state := DomainState{Domain: "npr.org", NextPage: 3, Podcasts: []Podcast{{Podcast: listennotes.Podcast{ID: "a"}}}}
+	if err := store.Save(state); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	loaded, ok, err := store.Load("npr.org")
+	if !ok || err != nil || loaded.NextPage != 3 || loaded.Podcasts[0].ID != "a" {
+		t.Errorf("Unexpected state %+v: %v", loaded, err)
+	}
+}
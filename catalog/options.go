@@ -0,0 +1,26 @@
+package catalog
+
+// Option allows for options to be passed to the crawler constructor function
+type Option func(c *Crawler)
+
+// WithLatestEpisodes enriches every podcast with its n most recent episodes, at the cost of an api call per podcast
+func WithLatestEpisodes(n int) Option {
+	return func(c *Crawler) {
+		c.latestEpisodes = n
+	}
+}
+
+// WithAudience enriches every podcast with its audience data, at the cost of an api call per podcast
+func WithAudience() Option {
+	return func(c *Crawler) {
+		c.audience = true
+	}
+}
+
+// WithMaxPages caps the number of pages crawled per domain, as a guard against endless pagination.  The default is
+// 1000.
+func WithMaxPages(n int) Option {
+	return func(c *Crawler) {
+		c.maxPages = n
+	}
+}
@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"sort"
+	"time"
+)
+
+// Catalog is the summary of a publisher's podcasts
+type Catalog struct {
+	Domain   string
+	Podcasts []Podcast
+	// Complete is false when the crawl of the domain did not finish
+	Complete bool
+	Shows    int
+	Episodes int
+	// MedianUpdateFrequency is the median time between episodes over the shows that report it
+	MedianUpdateFrequency time.Duration
+	// AverageListenScore is the mean over the shows with a listen score
+	AverageListenScore float64
+	// Err is why the crawl of the domain did not finish, if it failed
+	Err error
+}
+
+// Summarize computes the totals of a crawled domain
+func Summarize(state DomainState) Catalog {
+	c := Catalog{
+		Domain:   state.Domain,
+		Podcasts: state.Podcasts,
+		Complete: state.Done,
+		Shows:    len(state.Podcasts),
+	}
+
+	var frequencies []int
+	scoreTotal, scored := 0, 0
+	for _, p := range state.Podcasts {
+		c.Episodes += p.TotalEpisodes
+		if p.UpdateFrequencyHours > 0 {
+			frequencies = append(frequencies, p.UpdateFrequencyHours)
+		}
+		if score, ok := p.ListenScore.Value(); ok && score > 0 {
+			scoreTotal += score
+			scored++
+		}
+	}
+
+	if len(frequencies) > 0 {
+		sort.Ints(frequencies)
+		mid := len(frequencies) / 2
+		hours := float64(frequencies[mid])
+		if len(frequencies)%2 == 0 {
+			hours = float64(frequencies[mid-1]+frequencies[mid]) / 2
+		}
+		c.MedianUpdateFrequency = time.Duration(hours * float64(time.Hour))
+	}
+	if scored > 0 {
+		c.AverageListenScore = float64(scoreTotal) / float64(scored)
+	}
+	return c
+}
@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ListenNotes/podcast-api-go/internal/atomicfile"
+)
+
+// Store persists the crawl progress per domain.  Implementations must be safe for concurrent use.
+type Store interface {
+	Load(domain string) (DomainState, bool, error)
+	Save(state DomainState) error
+}
+
+// MemoryStore is a Store that keeps progress in memory, state is lost on restart
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]DomainState
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore will create an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]DomainState{}}
+}
+
+// Load implements Store
+func (s *MemoryStore) Load(domain string) (DomainState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[domain]
+	return state, ok, nil
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(state DomainState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[state.Domain] = state
+	return nil
+}
+
+// FileStore is a Store keeping a JSON file per domain in a directory
+type FileStore struct {
+	dir string
+}
+
+var _ Store = &FileStore{}
+
+// NewFileStore will create a store in dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create crawl directory %s: %w", dir, err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Load implements Store
+func (s *FileStore) Load(domain string) (DomainState, bool, error) {
+	data, err := os.ReadFile(s.filename(domain))
+	if os.IsNotExist(err) {
+		return DomainState{}, false, nil
+	}
+	if err != nil {
+		return DomainState{}, false, fmt.Errorf("failed to read crawl state of %s: %w", domain, err)
+	}
+
+	var state DomainState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return DomainState{}, false, fmt.Errorf("failed to parse crawl state of %s: %w", domain, err)
+	}
+	return state, true, nil
+}
+
+// Save implements Store.  The file is replaced atomically, an interruption never leaves a partial state behind.
+func (s *FileStore) Save(state DomainState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode crawl state: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.filename(state.Domain), data); err != nil {
+		return fmt.Errorf("failed to write crawl state of %s: %w", state.Domain, err)
+	}
+	return nil
+}
+
+func (s *FileStore) filename(domain string) string {
+	return filepath.Join(s.dir, filepath.Base(domain)+".json")
+}
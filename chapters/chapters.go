@@ -0,0 +1,88 @@
+// Package chapters extracts chapter markers from the timestamped chapter lists found in episode show notes, e.g.
+// "00:00 Intro, 12:30 Interview", for shows that do not publish chapter files.
+//
+// Chapters can be exported as Podcasting 2.0 JSON chapters and as ID3 CHAP/CTOC compatible structures.
+package chapters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/description"
+)
+
+// ErrNoChapters is returned when a description has no usable chapter list
+var ErrNoChapters = fmt.Errorf("no chapter list found in the description")
+
+// Chapter is a single chapter of an episode
+type Chapter struct {
+	Start time.Duration
+	// End is the start of the next chapter, or the end of the audio for the last one.  It is zero when the audio
+	// length is unknown.
+	End   time.Duration
+	Title string
+	// URL is a link found on the chapter line, if any
+	URL string
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// FromEpisode extracts the chapters of an episode, as returned by FetchEpisodeByID or PodcastEpisodes, checking them
+// against its audio length
+func FromEpisode(episode listennotes.Episode) ([]Chapter, error) {
+	return Extract(episode.Description, episode.AudioLength())
+}
+
+// Extract finds the chapters of a description.  Timestamps must be increasing to be part of the chapter list, so a
+// stray time mentioned later in the notes is ignored.  When audioLength is known, chapters starting past the end of
+// the audio are dropped.  At least two chapters are needed for a chapter list.
+func Extract(desc string, audioLength time.Duration) ([]Chapter, error) {
+	links := description.Extract(desc).Links
+
+	var chapters []Chapter
+	for _, ts := range description.Timestamps(description.PlainText(desc)) {
+		if len(chapters) > 0 && ts.Offset <= chapters[len(chapters)-1].Start {
+			continue
+		}
+		if audioLength > 0 && ts.Offset >= audioLength {
+			continue
+		}
+		chapters = append(chapters, newChapter(ts, links))
+	}
+
+	if len(chapters) < 2 {
+		return nil, ErrNoChapters
+	}
+
+	for i := range chapters {
+		if i+1 < len(chapters) {
+			chapters[i].End = chapters[i+1].Start
+		} else {
+			chapters[i].End = audioLength
+		}
+	}
+	return chapters, nil
+}
+
+func newChapter(ts description.Timestamp, links []description.Link) Chapter {
+	chapter := Chapter{Start: ts.Offset, Title: ts.Label}
+
+	if u := urlPattern.FindString(chapter.Title); u != "" {
+		chapter.URL = strings.TrimRight(u, ".,;:!?)]}")
+		chapter.Title = strings.Join(strings.Fields(strings.Replace(chapter.Title, u, "", 1)), " ")
+		chapter.Title = strings.Trim(chapter.Title, " -–—:|")
+		return chapter
+	}
+
+	// links in the html keep their text on the chapter line, e.g. "12:30 Interview with <a href=...>Jane</a>"
+	for _, link := range links {
+		if link.Text != "" && strings.Contains(chapter.Title, link.Text) {
+			chapter.URL = link.URL
+			break
+		}
+	}
+	return chapter
+}
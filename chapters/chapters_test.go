@@ -0,0 +1,69 @@
+package chapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+const notes = `<p>This week we talk to Jane about her new book.</p>
+<p>00:00 Intro<br>
+05:10 News - https://example.com/news<br>
+12:30 Interview with <a href="https://jane.example.com">Jane</a><br>
+1:02:00 Outro</p>
+<p>Mentioned at 03:00: nothing important.</p>`
+
+func TestFromEpisode(t *testing.T) {
+	episode := listennotes.Episode{Description: notes, AudioLengthSec: 3600}
+	chapters, err := FromEpisode(episode)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	expected := []Chapter{
+		{Start: 0, End: 5*time.Minute + 10*time.Second, Title: "Intro"},
+		{Start: 5*time.Minute + 10*time.Second, End: 12*time.Minute + 30*time.Second, Title: "News", URL: "https://example.com/news"},
+		{Start: 12*time.Minute + 30*time.Second, End: time.Hour, Title: "Interview with Jane", URL: "https://jane.example.com"},
+	}
+	if len(chapters) != len(expected) {
+		t.Fatalf("Expected %d chapters but got %+v", len(expected), chapters)
+	}
+	for i := range expected {
+		if chapters[i] != expected[i] {
+			t.Errorf("Chapter %d was %+v, expected %+v", i, chapters[i], expected[i])
+		}
+	}
+}
+
+func TestNoChapters(t *testing.T) {
+	if _, err := Extract("<p>Call us at 12:30 tomorrow.</p>", 0); err != ErrNoChapters {
+		t.Errorf("A single timestamp is not a chapter list: %v", err)
+	}
+}
+
+func TestExports(t *testing.T) {
+	chapters, _ := Extract(notes, 0)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, chapters); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	var doc JSONChapters
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid json: %s", err)
+	}
+	if doc.Version != JSONChaptersVersion || len(doc.Chapters) != 4 || doc.Chapters[3].StartTime != 3720 || doc.Chapters[3].EndTime != 0 {
+		t.Errorf("JSON chapters were not as expected: %+v", doc)
+	}
+
+	toc, frames := ToID3(chapters)
+	if len(toc.ChildElementIDs) != 4 || toc.ChildElementIDs[0] != "chp0" || !toc.Ordered {
+		t.Errorf("Table of contents was not as expected: %+v", toc)
+	}
+	if frames[1].StartTimeMs != 310000 || frames[1].EndTimeMs != 750000 || frames[3].EndTimeMs != frames[3].StartTimeMs {
+		t.Errorf("CHAP frames were not as expected: %+v", frames)
+	}
+}
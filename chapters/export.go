@@ -0,0 +1,98 @@
+package chapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONChaptersVersion is the version of the Podcasting 2.0 JSON chapters format written by WriteJSON
+const JSONChaptersVersion = "1.2.0"
+
+// JSONChapters is the Podcasting 2.0 JSON chapters document, see
+// https://github.com/Podcastindex-org/podcast-namespace/blob/main/chapters/jsonChapters.md
+type JSONChapters struct {
+	Version  string        `json:"version"`
+	Chapters []JSONChapter `json:"chapters"`
+}
+
+// JSONChapter is a single chapter of a JSONChapters document, times are in seconds
+type JSONChapter struct {
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime,omitempty"`
+	Title     string  `json:"title"`
+	URL       string  `json:"url,omitempty"`
+}
+
+// ToJSONChapters converts the chapters to a Podcasting 2.0 JSON chapters document
+func ToJSONChapters(chapters []Chapter) JSONChapters {
+	doc := JSONChapters{Version: JSONChaptersVersion, Chapters: []JSONChapter{}}
+	for _, c := range chapters {
+		doc.Chapters = append(doc.Chapters, JSONChapter{
+			StartTime: c.Start.Seconds(),
+			EndTime:   c.End.Seconds(),
+			Title:     c.Title,
+			URL:       c.URL,
+		})
+	}
+	return doc
+}
+
+// WriteJSON writes the chapters as a Podcasting 2.0 JSON chapters document
+func WriteJSON(w io.Writer, chapters []Chapter) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ToJSONChapters(chapters)); err != nil {
+		return fmt.Errorf("failed to write json chapters: %w", err)
+	}
+	return nil
+}
+
+// ID3NoOffset is the byte offset value meaning that the chapter times should be used instead
+const ID3NoOffset = 0xFFFFFFFF
+
+// ID3Chapter holds the fields of an ID3v2 CHAP frame, see https://id3.org/id3v2-chapters-1.0
+type ID3Chapter struct {
+	ElementID   string
+	StartTimeMs uint32
+	EndTimeMs   uint32
+	StartOffset uint32
+	EndOffset   uint32
+	// Title is the TIT2 sub-frame
+	Title string
+	// URL is the WXXX sub-frame, empty when the chapter has no link
+	URL string
+}
+
+// ID3TableOfContents holds the fields of an ID3v2 CTOC frame listing the chapters in order
+type ID3TableOfContents struct {
+	ElementID       string
+	TopLevel        bool
+	Ordered         bool
+	ChildElementIDs []string
+}
+
+// ToID3 converts the chapters to ID3 CHAP frames with their CTOC table of contents.  The end of a last chapter with
+// an unknown audio length is left at its start time.
+func ToID3(chapters []Chapter) (ID3TableOfContents, []ID3Chapter) {
+	toc := ID3TableOfContents{ElementID: "toc", TopLevel: true, Ordered: true}
+	frames := make([]ID3Chapter, 0, len(chapters))
+	for i, c := range chapters {
+		end := c.End
+		if end < c.Start {
+			end = c.Start
+		}
+		id := fmt.Sprintf("chp%d", i)
+		toc.ChildElementIDs = append(toc.ChildElementIDs, id)
+		frames = append(frames, ID3Chapter{
+			ElementID:   id,
+			StartTimeMs: uint32(c.Start.Milliseconds()),
+			EndTimeMs:   uint32(end.Milliseconds()),
+			StartOffset: ID3NoOffset,
+			EndOffset:   ID3NoOffset,
+			Title:       c.Title,
+			URL:         c.URL,
+		})
+	}
+	return toc, frames
+}
@@ -38,32 +38,36 @@ type HTTPClient interface {
 }
 
 type standardHTTPClient struct {
-	apiKey     string
-	httpClient *http.Client
-	baseURL    string
+	apiKey      string
+	httpClient  *http.Client
+	baseURL     string
+	inflight    *requestGroup
+	keyPool     *KeyPool
+	credentials *cachedCredentials
 }
 
 var _ HTTPClient = &standardHTTPClient{}
 
 // NewClient will create a client with reasonable defaults.
-// If an apiKey is not provided, the client will use the mock test API by default.
+// If an apiKey is not provided (and no key pool or credentials are configured), the client will use the mock test API by default.
 // You can optionally override some configuration.
 func NewClient(apiKey string, opts ...ClientOption) HTTPClient {
-	baseURL := BaseURLTest
-	if apiKey != "" {
-		baseURL = BaseURLProduction
-	}
-
 	client := &standardHTTPClient{
 		apiKey:     apiKey,
 		httpClient: defaultHTTPClient,
-		baseURL:    baseURL,
 	}
 
 	for _, opt := range opts {
 		opt(client)
 	}
 
+	if client.baseURL == "" {
+		client.baseURL = BaseURLTest
+		if apiKey != "" || client.keyPool != nil || client.credentials != nil {
+			client.baseURL = BaseURLProduction
+		}
+	}
+
 	return client
 }
 
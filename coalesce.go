@@ -0,0 +1,63 @@
+package listennotes
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// inflightCall is a single upstream request that any number of identical callers are waiting on
+type inflightCall struct {
+	done    chan struct{}
+	resp    *Response
+	err     error
+	waiters int
+}
+
+// requestGroup collapses identical concurrent requests into a single upstream call
+type requestGroup struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+func newRequestGroup() *requestGroup {
+	return &requestGroup{calls: map[string]*inflightCall{}}
+}
+
+// do runs fn once for all concurrent callers sharing the key.  Every caller receives the same response and error.
+// The first caller runs fn, the others wait for it until their ctx is done.  A waiter that gives up gets the ctx error
+// and leaves the call running for everyone else.
+func (g *requestGroup) do(ctx context.Context, key string, fn func() (*Response, error)) (*Response, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		call.waiters++
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.resp, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.resp, call.err = fn()
+	return call.resp, call.err
+}
+
+// requestKey builds a canonical key for a request.  url.Values.Encode sorts by key, so argument order does not matter.
+func requestKey(method string, path string, args map[string]string) string {
+	values := url.Values{}
+	for k, v := range args {
+		values.Set(k, v)
+	}
+	return method + " " + path + "?" + values.Encode()
+}
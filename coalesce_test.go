@@ -0,0 +1,154 @@
+package listennotes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForWaiters blocks until n callers are waiting on the in-flight call for key
+func waitForWaiters(t *testing.T, g *requestGroup, key string, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		g.mu.Lock()
+		call, ok := g.calls[key]
+		waiting := ok && call.waiters >= n
+		g.mu.Unlock()
+		if waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Callers did not join the in-flight call %s", key)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRequestCoalescing(t *testing.T) {
+	var hits int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		started <- struct{}{}
+		<-release
+		if r.URL.Path == "/podcasts/missing" {
+			w.WriteHeader(404)
+		}
+		w.Write([]byte(`{"id": "abc"}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithRequestCoalescing())
+	group := client.(*standardHTTPClient).inflight
+
+	var wg sync.WaitGroup
+	responses := make([]*Response, 10)
+	errs := make([]error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			args := map[string]string{"a": "1", "b": "2"}
+			if i%2 == 0 {
+				args = map[string]string{"b": "2", "a": "1"}
+			}
+			responses[i], errs[i] = client.FetchPodcastByID("abc", args)
+		}(i)
+	}
+	<-started
+	waitForWaiters(t, group, requestKey("GET", "podcasts/abc", map[string]string{"a": "1", "b": "2"}), 9)
+	release <- struct{}{}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("Expected a single upstream request but got %d", n)
+	}
+	for i := range responses {
+		if errs[i] != nil || responses[i] != responses[0] {
+			t.Errorf("Waiter %d did not get the shared response: %v", i, errs[i])
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.FetchPodcastByID("missing", nil)
+		}(i)
+	}
+	<-started
+	waitForWaiters(t, group, requestKey("GET", "podcasts/missing", nil), 4)
+	release <- struct{}{}
+	wg.Wait()
+
+	for i := 0; i < 5; i++ {
+		if errs[i] != ErrNotFound {
+			t.Errorf("Waiter %d did not get the shared error: %v", i, errs[i])
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("Completed requests should not be coalesced with later ones: %d", n)
+	}
+}
+
+func TestRequestCoalescingCancelledWaiter(t *testing.T) {
+	group := newRequestGroup()
+	release := make(chan struct{})
+	shared := &Response{}
+
+	leader := make(chan error)
+	go func() {
+		_, err := group.do(context.Background(), "key", func() (*Response, error) {
+			<-release
+			return shared, nil
+		})
+		leader <- err
+	}()
+	waitForWaiters(t, group, "key", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := group.do(ctx, "key", nil)
+		cancelled <- err
+	}()
+	waitForWaiters(t, group, "key", 1)
+
+	var resp *Response
+	other := make(chan error)
+	go func() {
+		var err error
+		resp, err = group.do(context.Background(), "key", nil)
+		other <- err
+	}()
+	waitForWaiters(t, group, "key", 2)
+
+	cancel()
+	if err := <-cancelled; err != context.Canceled {
+		t.Errorf("Expected the cancelled waiter to give up but got: %v", err)
+	}
+
+	close(release)
+	if err := <-leader; err != nil {
+		t.Errorf("The shared call should not be cancelled: %v", err)
+	}
+	if err := <-other; err != nil || resp != shared {
+		t.Errorf("The other waiter should get the shared response: %v", err)
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	a := requestKey("GET", "search", map[string]string{"q": "a", "type": "episode"})
+	b := requestKey("GET", "search", map[string]string{"type": "episode", "q": "a"})
+	if a != b {
+		t.Errorf("Argument order should not change the key: %s != %s", a, b)
+	}
+	if a == requestKey("GET", "search", map[string]string{"q": "b"}) {
+		t.Errorf("Different arguments should change the key")
+	}
+}
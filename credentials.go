@@ -0,0 +1,123 @@
+package listennotes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCredentialsTTL is how long a key from a CredentialProvider is used before the provider is asked again
+const DefaultCredentialsTTL = 5 * time.Minute
+
+// CredentialProvider supplies the api key.  It is called lazily when a request needs a key, so a rotated key is picked
+// up without restarting the process.
+type CredentialProvider interface {
+	APIKey() (string, error)
+}
+
+// CredentialFunc allows using a plain function, e.g. a call to a secret manager, as a CredentialProvider
+type CredentialFunc func() (string, error)
+
+// APIKey implements CredentialProvider
+func (f CredentialFunc) APIKey() (string, error) {
+	return f()
+}
+
+// EnvCredentials reads the api key from an environment variable
+func EnvCredentials(name string) CredentialProvider {
+	return CredentialFunc(func() (string, error) {
+		apiKey := strings.TrimSpace(os.Getenv(name))
+		if apiKey == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return apiKey, nil
+	})
+}
+
+// FileCredentials reads the api key from a file, e.g. a mounted Kubernetes secret.  The file is read again whenever
+// its modification time or size changes.
+func FileCredentials(path string) CredentialProvider {
+	return &fileCredentials{path: path}
+}
+
+type fileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	apiKey  string
+	modTime time.Time
+	size    int64
+}
+
+func (f *fileCredentials) APIKey() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read api key file %s: %w", f.path, err)
+	}
+	if f.apiKey != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.apiKey, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read api key file %s: %w", f.path, err)
+	}
+	apiKey := strings.TrimSpace(string(data))
+	if apiKey == "" {
+		return "", fmt.Errorf("api key file %s is empty", f.path)
+	}
+
+	f.apiKey = apiKey
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return apiKey, nil
+}
+
+// cachedCredentials keeps the key of a provider for a while, so that the provider is not asked on every request
+type cachedCredentials struct {
+	provider CredentialProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	apiKey  string
+	expires time.Time
+}
+
+func newCachedCredentials(provider CredentialProvider, ttl time.Duration) *cachedCredentials {
+	return &cachedCredentials{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+func (c *cachedCredentials) APIKey() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.apiKey != "" && c.now().Before(c.expires) {
+		return c.apiKey, nil
+	}
+
+	apiKey, err := c.provider.APIKey()
+	if err != nil {
+		return "", err
+	}
+	c.apiKey = apiKey
+	c.expires = c.now().Add(c.ttl)
+	return apiKey, nil
+}
+
+// invalidate drops the cached key, so that the next request asks the provider again
+func (c *cachedCredentials) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.apiKey = ""
+}
@@ -0,0 +1,131 @@
+package listennotes
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCredentialsReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "api-key")
+	ioutil.WriteFile(path, []byte("first-key\n"), 0o600)
+
+	provider := FileCredentials(path)
+	if apiKey, err := provider.APIKey(); err != nil || apiKey != "first-key" {
+		t.Errorf("Expected first-key but got %s: %v", apiKey, err)
+	}
+
+	ioutil.WriteFile(path, []byte("second-key-rotated\n"), 0o600)
+	if apiKey, err := provider.APIKey(); err != nil || apiKey != "second-key-rotated" {
+		t.Errorf("Expected the rotated key but got %s: %v", apiKey, err)
+	}
+
+	os.Remove(path)
+	if _, err := provider.APIKey(); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	os.Setenv("LISTEN_API_KEY_TEST", " env-key ")
+	defer os.Unsetenv("LISTEN_API_KEY_TEST")
+
+	if apiKey, err := EnvCredentials("LISTEN_API_KEY_TEST").APIKey(); err != nil || apiKey != "env-key" {
+		t.Errorf("Expected env-key but got %s: %v", apiKey, err)
+	}
+	if _, err := EnvCredentials("LISTEN_API_KEY_TEST_MISSING").APIKey(); err == nil {
+		t.Errorf("Expected an error for a missing variable")
+	}
+}
+
+func TestCachedCredentials(t *testing.T) {
+	calls := 0
+	cached := newCachedCredentials(CredentialFunc(func() (string, error) {
+		calls++
+		return "key", nil
+	}), time.Minute)
+	now := time.Now()
+	cached.now = func() time.Time { return now }
+
+	cached.APIKey()
+	cached.APIKey()
+	if calls != 1 {
+		t.Errorf("Expected the key to be cached but the provider was called %d times", calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	cached.APIKey()
+	if calls != 2 {
+		t.Errorf("Expected the key to expire but the provider was called %d times", calls)
+	}
+
+	cached.invalidate()
+	cached.APIKey()
+	if calls != 3 {
+		t.Errorf("Expected an invalidated key to be fetched again but the provider was called %d times", calls)
+	}
+}
+
+func TestWithCredentials(t *testing.T) {
+	var seen []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.Header.Get(RequestHeaderKeyAPI)
+		seen = append(seen, apiKey)
+		if apiKey == "revoked" {
+			w.WriteHeader(401)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	current := "revoked"
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithCredentials(CredentialFunc(func() (string, error) {
+		return current, nil
+	})))
+
+	if _, err := client.Search(nil); err != ErrUnauthorized {
+		t.Errorf("Expected unauthorized but got: %v", err)
+	}
+	current = "rotated"
+	if _, err := client.Search(nil); err != nil {
+		t.Errorf("Expected the rotated key to be used after a 401 but got: %v", err)
+	}
+	if len(seen) != 2 || seen[1] != "rotated" {
+		t.Errorf("Keys sent were not as expected: %v", seen)
+	}
+
+	if c := NewClient("", WithCredentials(EnvCredentials("X"))).(*standardHTTPClient); c.baseURL != BaseURLProduction {
+		t.Errorf("A client with credentials should use the production url: %s", c.baseURL)
+	}
+}
+
+func TestCredentialsAndKeyPool(t *testing.T) {
+	ts, seen := newKeyPoolTestServer(t, nil, nil)
+	credentials := WithCredentials(CredentialFunc(func() (string, error) {
+		return "from-credentials", nil
+	}))
+
+	pool := NewKeyPool(RoundRobin, "pool-key")
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), credentials, WithKeyPool(pool))
+	client.Search(nil)
+	if seen["pool-key"] != 1 || seen["from-credentials"] != 0 || pool.Snapshot()[0].Requests != 1 {
+		t.Errorf("The key pool passed last should be used: %v", seen)
+	}
+
+	pool = NewKeyPool(RoundRobin, "pool-key")
+	client = NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithKeyPool(pool), credentials)
+	client.Search(nil)
+	if seen["from-credentials"] != 1 || pool.Snapshot()[0].Requests != 0 {
+		t.Errorf("The credentials passed last should be used: %v", seen)
+	}
+}
@@ -0,0 +1,149 @@
+package curated
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Changes are the differences between two snapshots
+type Changes struct {
+	// Initial is set on the first run, when every list counts as added
+	Initial bool
+	Added   []List
+	// Removed is only filled when the newer snapshot is complete
+	Removed []List
+	Changed []ListChange
+}
+
+// ListChange is a list present in both snapshots that changed
+type ListChange struct {
+	Before List
+	After  List
+	// Fields are the names of the changed list fields, e.g. "title" or "source_url"
+	Fields          []string
+	AddedPodcasts   []listennotes.Podcast
+	RemovedPodcasts []listennotes.Podcast
+}
+
+// Empty reports whether nothing changed
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// Diff compares two snapshots.  Lists are sorted by title, podcasts keep their list order.
+func Diff(before, after Snapshot) Changes {
+	var changes Changes
+	for id, list := range after.Lists {
+		old, ok := before.Lists[id]
+		if !ok {
+			changes.Added = append(changes.Added, list)
+			continue
+		}
+		if change, ok := diffList(old, list); ok {
+			changes.Changed = append(changes.Changed, change)
+		}
+	}
+	if after.Complete {
+		for id, list := range before.Lists {
+			if _, ok := after.Lists[id]; !ok {
+				changes.Removed = append(changes.Removed, list)
+			}
+		}
+	}
+
+	sortLists(changes.Added)
+	sortLists(changes.Removed)
+	sort.Slice(changes.Changed, func(i, j int) bool {
+		return lessList(changes.Changed[i].After, changes.Changed[j].After)
+	})
+	return changes
+}
+
+func diffList(before, after List) (ListChange, bool) {
+	change := ListChange{Before: before, After: after}
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"title", before.Title, after.Title},
+		{"description", before.Description, after.Description},
+		{"source_url", before.SourceURL, after.SourceURL},
+		{"source_domain", before.SourceDomain, after.SourceDomain},
+	}
+	for _, f := range fields {
+		if f.before != f.after {
+			change.Fields = append(change.Fields, f.name)
+		}
+	}
+	if before.PubDateMs != after.PubDateMs {
+		change.Fields = append(change.Fields, "pub_date_ms")
+	}
+
+	change.AddedPodcasts = missingPodcasts(after.Podcasts, before.Podcasts)
+	change.RemovedPodcasts = missingPodcasts(before.Podcasts, after.Podcasts)
+	changed := len(change.Fields) > 0 || len(change.AddedPodcasts) > 0 || len(change.RemovedPodcasts) > 0
+	return change, changed
+}
+
+// missingPodcasts returns the podcasts of a that are not in b
+func missingPodcasts(a, b []listennotes.Podcast) []listennotes.Podcast {
+	ids := map[string]bool{}
+	for _, p := range b {
+		ids[p.ID] = true
+	}
+	var missing []listennotes.Podcast
+	for _, p := range a {
+		if !ids[p.ID] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+func sortLists(lists []List) {
+	sort.Slice(lists, func(i, j int) bool {
+		return lessList(lists[i], lists[j])
+	})
+}
+
+func lessList(a, b List) bool {
+	if a.Title != b.Title {
+		return a.Title < b.Title
+	}
+	return a.ID < b.ID
+}
+
+// String is a plain text report of the changes, meant for the editors
+func (c Changes) String() string {
+	if c.Empty() {
+		return "No changes to curated lists\n"
+	}
+
+	var b strings.Builder
+	if c.Initial {
+		fmt.Fprintf(&b, "First run, %d curated lists\n", len(c.Added))
+		return b.String()
+	}
+	for _, l := range c.Added {
+		fmt.Fprintf(&b, "+ %s (%d podcasts) %s\n", l.Title, len(l.Podcasts), l.SourceURL)
+	}
+	for _, l := range c.Removed {
+		fmt.Fprintf(&b, "- %s %s\n", l.Title, l.SourceURL)
+	}
+	for _, ch := range c.Changed {
+		fmt.Fprintf(&b, "~ %s %s\n", ch.After.Title, ch.After.SourceURL)
+		if len(ch.Fields) > 0 {
+			fmt.Fprintf(&b, "    changed: %s\n", strings.Join(ch.Fields, ", "))
+		}
+		for _, p := range ch.AddedPodcasts {
+			fmt.Fprintf(&b, "    + %s\n", p.Title)
+		}
+		for _, p := range ch.RemovedPodcasts {
+			fmt.Fprintf(&b, "    - %s\n", p.Title)
+		}
+	}
+	return b.String()
+}
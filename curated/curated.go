@@ -0,0 +1,180 @@
+// Package curated ingests the curated podcast lists of Listen Notes and tracks how they change between runs.
+//
+// Every run walks FetchCuratedPodcastsLists, fetches the podcasts of new or changed lists with
+// FetchCuratedPodcastsListByID and stores the result.  The changes since the previous run are returned:
+//
+//	ingester := curated.NewIngester(client, curated.NewFileStore("/var/lib/curated.json"))
+//	changes, err := ingester.Run(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(changes)
+package curated
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// List is a curated list with its podcasts
+type List struct {
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	SourceURL      string `json:"source_url"`
+	SourceDomain   string `json:"source_domain"`
+	ListennotesURL string `json:"listennotes_url"`
+	PubDateMs      int64  `json:"pub_date_ms"`
+	// Total is the number of podcasts in the list
+	Total int `json:"total"`
+	// Podcasts are all podcasts of the list, in list order
+	Podcasts []listennotes.Podcast `json:"podcasts"`
+	// FetchedAt is when the podcasts of the list were last fetched
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// PubDate is the publish date of the list
+func (l List) PubDate() time.Time {
+	if l.PubDateMs == 0 {
+		return time.Time{}
+	}
+	return time.Unix(l.PubDateMs/1000, (l.PubDateMs%1000)*int64(time.Millisecond))
+}
+
+// Snapshot is the stored result of a run
+type Snapshot struct {
+	Lists map[string]List `json:"lists"`
+	// Complete is false when the run stopped at the page limit, lists it did not reach are carried over from the
+	// previous snapshot
+	Complete  bool      `json:"complete"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type listsPage struct {
+	HasNext      bool   `json:"has_next"`
+	CuratedLists []List `json:"curated_lists"`
+}
+
+// Ingester walks the curated lists
+type Ingester struct {
+	client     listennotes.HTTPClient
+	store      Store
+	maxPages   int
+	refetchAll bool
+	now        func() time.Time
+}
+
+// NewIngester will create an ingester using the client for api calls and the store for snapshots.
+// You can optionally override some configuration.
+func NewIngester(client listennotes.HTTPClient, store Store, opts ...Option) *Ingester {
+	in := &Ingester{
+		client:   client,
+		store:    store,
+		maxPages: 1000,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(in)
+	}
+
+	return in
+}
+
+// Run walks every curated list, saves the new snapshot and returns the changes since the previous one.  The
+// podcasts of a list are only fetched when the list is new or its summary changed, see WithRefetchAll.  Nothing is
+// saved when the run fails or the context is done.
+func (in *Ingester) Run(ctx context.Context) (Changes, error) {
+	previous, ok, err := in.store.Load()
+	if err != nil {
+		return Changes{}, fmt.Errorf("failed to load curated lists: %w", err)
+	}
+
+	next := Snapshot{Lists: map[string]List{}, Complete: true}
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return Changes{}, err
+		}
+		if page > in.maxPages {
+			next.Complete = false
+			break
+		}
+
+		resp, err := in.client.FetchCuratedPodcastsLists(map[string]string{"page": strconv.Itoa(page)})
+		if err != nil {
+			return Changes{}, fmt.Errorf("failed to fetch page %d of curated lists: %w", page, err)
+		}
+		p := &listsPage{}
+		if err := resp.Decode(p); err != nil {
+			return Changes{}, err
+		}
+
+		for _, summary := range p.CuratedLists {
+			if old, ok := previous.Lists[summary.ID]; ok && !in.refetchAll && sameSummary(old, summary) {
+				next.Lists[summary.ID] = old
+				continue
+			}
+			list, err := in.fetch(summary.ID)
+			if err != nil {
+				return Changes{}, err
+			}
+			next.Lists[list.ID] = list
+		}
+
+		if !p.HasNext || len(p.CuratedLists) == 0 {
+			break
+		}
+	}
+
+	if !next.Complete {
+		for id, list := range previous.Lists {
+			if _, ok := next.Lists[id]; !ok {
+				next.Lists[id] = list
+			}
+		}
+	}
+	next.UpdatedAt = in.now()
+
+	changes := Diff(previous, next)
+	changes.Initial = !ok
+	if err := in.store.Save(next); err != nil {
+		return Changes{}, fmt.Errorf("failed to save curated lists: %w", err)
+	}
+	return changes, nil
+}
+
+func (in *Ingester) fetch(id string) (List, error) {
+	resp, err := in.client.FetchCuratedPodcastsListByID(id, nil)
+	if err != nil {
+		return List{}, fmt.Errorf("failed to fetch curated list %s: %w", id, err)
+	}
+	list := List{}
+	if err := resp.Decode(&list); err != nil {
+		return List{}, err
+	}
+	list.FetchedAt = in.now()
+	return list, nil
+}
+
+// sameSummary reports whether the summary from the lists page matches the stored list.  The page only has the first
+// podcasts of a list, a podcast swapped further down with the total unchanged goes unnoticed.
+func sameSummary(stored, summary List) bool {
+	if stored.Title != summary.Title || stored.Description != summary.Description ||
+		stored.SourceURL != summary.SourceURL || stored.PubDateMs != summary.PubDateMs ||
+		stored.Total != summary.Total {
+		return false
+	}
+	if len(summary.Podcasts) > len(stored.Podcasts) {
+		return false
+	}
+	for i, p := range summary.Podcasts {
+		if stored.Podcasts[i].ID != p.ID {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package curated
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	pageSize  int
+	lists     []map[string]interface{}
+	byIDCalls map[string]int
+	pageCalls int
+}
+
+func (c *fakeClient) FetchCuratedPodcastsLists(args map[string]string) (*listennotes.Response, error) {
+	c.pageCalls++
+	page := 1
+	if args["page"] == "2" {
+		page = 2
+	}
+	start := (page - 1) * c.pageSize
+	end := start + c.pageSize
+	if end > len(c.lists) {
+		end = len(c.lists)
+	}
+	var summaries []interface{}
+	for _, l := range c.lists[start:end] {
+		summary := map[string]interface{}{}
+		for k, v := range l {
+			summary[k] = v
+		}
+		podcasts := l["podcasts"].([]interface{})
+		if len(podcasts) > 2 {
+			podcasts = podcasts[:2]
+		}
+		summary["podcasts"] = podcasts
+		summaries = append(summaries, summary)
+	}
+	return &listennotes.Response{Data: map[string]interface{}{
+		"has_next":      end < len(c.lists),
+		"curated_lists": summaries,
+	}}, nil
+}
+
+func (c *fakeClient) FetchCuratedPodcastsListByID(id string, args map[string]string) (*listennotes.Response, error) {
+	c.byIDCalls[id]++
+	for _, l := range c.lists {
+		if l["id"] == id {
+			return &listennotes.Response{Data: l}, nil
+		}
+	}
+	return nil, listennotes.ErrNotFound
+}
+
+func list(id, title string, podcastIDs ...string) map[string]interface{} {
+	var podcasts []interface{}
+	for _, p := range podcastIDs {
+		podcasts = append(podcasts, map[string]interface{}{"id": p, "title": "Podcast " + p})
+	}
+	return map[string]interface{}{
+		"id":         id,
+		"title":      title,
+		"source_url": "https://example.com/" + id,
+		"total":      float64(len(podcastIDs)),
+		"podcasts":   podcasts,
+	}
+}
+
+func TestRun(t *testing.T) {
+	client := &fakeClient{
+		pageSize:  2,
+		byIDCalls: map[string]int{},
+		lists: []map[string]interface{}{
+			list("l1", "Art", "a", "b", "c"),
+			list("l2", "Books", "d", "e"),
+			list("l3", "Comedy", "f"),
+		},
+	}
+	ingester := NewIngester(client, NewFileStore(filepath.Join(t.TempDir(), "curated.json")))
+
+	changes, err := ingester.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !changes.Initial || len(changes.Added) != 3 || len(changes.Added[0].Podcasts) != 3 {
+		t.Errorf("First run should add every list with all podcasts: %+v", changes)
+	}
+	if client.pageCalls != 2 {
+		t.Errorf("Expected 2 page calls but got %d", client.pageCalls)
+	}
+
+	// l1 swaps c for g, l2 is retitled, l3 is removed, l4 is added
+	client.lists = []map[string]interface{}{
+		list("l1", "Art", "a", "b", "g"),
+		list("l2", "Great books", "d", "e"),
+		list("l4", "Drama", "h"),
+	}
+	changes, err = ingester.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if changes.Initial || len(changes.Added) != 1 || changes.Added[0].ID != "l4" {
+		t.Errorf("Added lists were not as expected: %+v", changes.Added)
+	}
+	if len(changes.Removed) != 1 || changes.Removed[0].ID != "l3" {
+		t.Errorf("Removed lists were not as expected: %+v", changes.Removed)
+	}
+	if len(changes.Changed) != 1 || changes.Changed[0].Fields[0] != "title" {
+		t.Errorf("Changed lists were not as expected: %+v", changes.Changed)
+	}
+	// the swap is past the podcasts of the summary, only a refetch finds it
+	if client.byIDCalls["l1"] != 1 {
+		t.Errorf("An unchanged summary should not be refetched: %d", client.byIDCalls["l1"])
+	}
+
+	ingester.refetchAll = true
+	changes, _ = ingester.Run(context.Background())
+	if len(changes.Changed) != 1 {
+		t.Fatalf("Expected the podcast swap to be found: %+v", changes)
+	}
+	change := changes.Changed[0]
+	if len(change.AddedPodcasts) != 1 || change.AddedPodcasts[0].ID != "g" || change.RemovedPodcasts[0].ID != "c" {
+		t.Errorf("Podcast changes were not as expected: %+v", change)
+	}
+	if report := changes.String(); !strings.Contains(report, "+ Podcast g") || !strings.Contains(report, "- Podcast c") {
+		t.Errorf("Report was not as expected: %s", report)
+	}
+
+	if changes, _ = ingester.Run(context.Background()); !changes.Empty() {
+		t.Errorf("Expected no changes but got: %+v", changes)
+	}
+}
+
+func TestRunPageLimit(t *testing.T) {
+	client := &fakeClient{
+		pageSize:  1,
+		byIDCalls: map[string]int{},
+		lists:     []map[string]interface{}{list("l1", "Art", "a"), list("l2", "Books", "b")},
+	}
+	store := NewMemoryStore()
+	NewIngester(client, store).Run(context.Background())
+
+	client.lists = []map[string]interface{}{list("l3", "Comedy", "c"), list("l1", "Art", "a")}
+	changes, err := NewIngester(client, store, WithMaxPages(1)).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if len(changes.Added) != 1 || len(changes.Removed) != 0 {
+		t.Errorf("An incomplete run should not report removals: %+v", changes)
+	}
+	snapshot, _, _ := store.Load()
+	if snapshot.Complete || len(snapshot.Lists) != 3 {
+		t.Errorf("Lists not reached should be carried over: %+v", snapshot)
+	}
+}
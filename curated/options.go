@@ -0,0 +1,20 @@
+package curated
+
+// Option allows for options to be passed to the ingester constructor function
+type Option func(in *Ingester)
+
+// WithMaxPages caps the number of list pages walked per run, as a guard against endless pagination.  A run that hits
+// the cap reports no removals.  The default is 1000.
+func WithMaxPages(n int) Option {
+	return func(in *Ingester) {
+		in.maxPages = n
+	}
+}
+
+// WithRefetchAll fetches the podcasts of every list on every run, instead of only for lists whose summary changed.
+// It catches every podcast change at the cost of an api call per list.
+func WithRefetchAll() Option {
+	return func(in *Ingester) {
+		in.refetchAll = true
+	}
+}
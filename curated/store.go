@@ -0,0 +1,91 @@
+package curated
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ListenNotes/podcast-api-go/internal/atomicfile"
+)
+
+// Store keeps the latest snapshot
+type Store interface {
+	Load() (Snapshot, bool, error)
+	Save(snapshot Snapshot) error
+}
+
+// MemoryStore is a Store that keeps the snapshot in memory, state is lost on restart
+type MemoryStore struct {
+	mu       sync.Mutex
+	snapshot *Snapshot
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore will create an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load implements Store
+func (s *MemoryStore) Load() (Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot == nil {
+		return Snapshot{}, false, nil
+	}
+	return *s.snapshot, true, nil
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot = &snapshot
+	return nil
+}
+
+// FileStore is a Store keeping the snapshot in a JSON file
+type FileStore struct {
+	path string
+}
+
+var _ Store = &FileStore{}
+
+// NewFileStore will create a store at path, the file is created on the first save
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements Store
+func (s *FileStore) Load() (Snapshot, bool, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to read curated lists %s: %w", s.path, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to parse curated lists %s: %w", s.path, err)
+	}
+	return snapshot, true, nil
+}
+
+// Save implements Store.  The file is replaced atomically, an interruption never leaves a partial snapshot behind.
+func (s *FileStore) Save(snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode curated lists: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.path, data); err != nil {
+		return fmt.Errorf("failed to write curated lists %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package deletion
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Action is a step of a deletion request recorded in the audit log
+type Action string
+
+// Audit log actions
+const (
+	ActionRequested Action = "requested"
+	ActionSubmitted Action = "submitted"
+	ActionChecked   Action = "checked"
+	ActionDeleted   Action = "deleted"
+	ActionTimedOut  Action = "timed_out"
+	ActionFailed    Action = "failed"
+)
+
+// AuditRecord is a single line of the audit log
+type AuditRecord struct {
+	Time        time.Time `json:"time"`
+	PodcastID   string    `json:"podcast_id"`
+	Action      Action    `json:"action"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	// Status is the status reported by the api, "in review" or "deleted"
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditLog appends records as JSON Lines.  Records are never rewritten, only appended.
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuditLog will create an audit log writing to w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// OpenAuditLog opens, or creates, the audit log file at path for appending.  The caller is responsible for closing
+// the returned file once done with the log.
+func OpenAuditLog(path string) (*AuditLog, *os.File, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	return NewAuditLog(f), f, nil
+}
+
+// Append writes the record as a single line
+func (l *AuditLog) Append(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// ReadAuditLog reads back every record of an audit log
+func ReadAuditLog(r io.Reader) ([]AuditRecord, error) {
+	var records []AuditRecord
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed parsing audit log line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading audit log: %w", err)
+	}
+	return records, nil
+}
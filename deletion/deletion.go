@@ -0,0 +1,164 @@
+// Package deletion requests podcast deletions through DeletePodcast and follows them until they are completed,
+// keeping an append-only audit trail of who asked, when and why.
+//
+//	audit, f, err := deletion.OpenAuditLog("deletions.jsonl")
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//
+//	workflow := deletion.NewWorkflow(client, audit)
+//	ctx = deletion.WithRequester(ctx, "support@example.com")
+//	result, err := workflow.RequestDeletion(ctx, podcastID, "creator takedown request #1234")
+package deletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// ErrTimeout is returned when the podcast still exists once the workflow timeout has passed.  The deletion may still
+// be completed later, Listen Notes reviews deletion requests within 12 hours.
+var ErrTimeout = fmt.Errorf("podcast deletion was not confirmed before the timeout")
+
+const apiStatusDeleted = "deleted"
+
+type requesterKey struct{}
+
+// WithRequester records who asked for the deletion, e.g. a user id or email, in the context
+func WithRequester(ctx context.Context, requester string) context.Context {
+	return context.WithValue(ctx, requesterKey{}, requester)
+}
+
+// Requester returns who asked for the deletion, as set by WithRequester
+func Requester(ctx context.Context) string {
+	requester, _ := ctx.Value(requesterKey{}).(string)
+	return requester
+}
+
+// Result is the outcome of a deletion request
+type Result struct {
+	PodcastID   string
+	RequestedBy string
+	Reason      string
+	RequestedAt time.Time
+	// DeletedAt is set once the podcast is confirmed deleted
+	DeletedAt time.Time
+	Checks    int
+}
+
+// Deleted reports whether the deletion was confirmed
+func (r Result) Deleted() bool {
+	return !r.DeletedAt.IsZero()
+}
+
+// Workflow sends deletion requests and confirms them
+type Workflow struct {
+	client       listennotes.HTTPClient
+	audit        *AuditLog
+	pollInterval time.Duration
+	timeout      time.Duration
+	now          func() time.Time
+}
+
+// NewWorkflow will create a workflow writing its audit trail to audit.
+// You can optionally override some configuration.
+func NewWorkflow(client listennotes.HTTPClient, audit *AuditLog, opts ...Option) *Workflow {
+	w := &Workflow{
+		client:       client,
+		audit:        audit,
+		pollInterval: 10 * time.Minute,
+		timeout:      24 * time.Hour,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// RequestDeletion asks Listen Notes to delete the podcast, sending the reason upstream, then checks the podcast with
+// FetchPodcastByID until it is gone (ErrNotFound), the timeout passes (ErrTimeout) or the context is done.
+// Every step is written to the audit log.
+func (w *Workflow) RequestDeletion(ctx context.Context, podcastID string, reason string) (Result, error) {
+	result := Result{
+		PodcastID:   podcastID,
+		RequestedBy: Requester(ctx),
+		Reason:      reason,
+		RequestedAt: w.now(),
+	}
+
+	if err := w.record(result, ActionRequested, "", nil); err != nil {
+		return result, err
+	}
+
+	resp, err := w.client.DeletePodcast(podcastID, map[string]string{"reason": reason})
+	if err != nil {
+		w.record(result, ActionFailed, "", err)
+		return result, fmt.Errorf("failed to request deletion of %s: %w", podcastID, err)
+	}
+
+	status, _ := resp.Data["status"].(string)
+	if err := w.record(result, ActionSubmitted, status, nil); err != nil {
+		return result, err
+	}
+	if status == apiStatusDeleted {
+		return w.deleted(result)
+	}
+
+	deadline := result.RequestedAt.Add(w.timeout)
+	timer := time.NewTimer(w.pollInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.record(result, ActionFailed, "", ctx.Err())
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+
+		result.Checks++
+		_, err := w.client.FetchPodcastByID(podcastID, nil)
+		if errors.Is(err, listennotes.ErrNotFound) {
+			return w.deleted(result)
+		}
+		if err := w.record(result, ActionChecked, "", err); err != nil {
+			return result, err
+		}
+
+		if !w.now().Before(deadline) {
+			w.record(result, ActionTimedOut, "", nil)
+			return result, ErrTimeout
+		}
+		timer.Reset(w.pollInterval)
+	}
+}
+
+func (w *Workflow) deleted(result Result) (Result, error) {
+	result.DeletedAt = w.now()
+	if err := w.record(result, ActionDeleted, apiStatusDeleted, nil); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (w *Workflow) record(result Result, action Action, status string, actionErr error) error {
+	record := AuditRecord{
+		Time:        w.now(),
+		PodcastID:   result.PodcastID,
+		Action:      action,
+		RequestedBy: result.RequestedBy,
+		Reason:      result.Reason,
+		Status:      status,
+	}
+	if actionErr != nil {
+		record.Error = actionErr.Error()
+	}
+	return w.audit.Append(record)
+}
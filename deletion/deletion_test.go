@@ -0,0 +1,108 @@
+package deletion
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	status       string
+	reason       string
+	existsChecks int
+	checks       int
+}
+
+func (c *fakeClient) DeletePodcast(id string, args map[string]string) (*listennotes.Response, error) {
+	c.reason = args["reason"]
+	return &listennotes.Response{Data: map[string]interface{}{"status": c.status}}, nil
+}
+
+func (c *fakeClient) FetchPodcastByID(id string, args map[string]string) (*listennotes.Response, error) {
+	c.checks++
+	if c.checks > c.existsChecks {
+		return nil, listennotes.ErrNotFound
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"id": id}}, nil
+}
+
+func actions(t *testing.T, buf *bytes.Buffer) []Action {
+	records, err := ReadAuditLog(buf)
+	if err != nil {
+		t.Fatalf("Audit log could not be read: %s", err)
+	}
+	var list []Action
+	for _, r := range records {
+		list = append(list, r.Action)
+	}
+	return list
+}
+
+func TestRequestDeletionConfirmed(t *testing.T) {
+	client := &fakeClient{status: "in review", existsChecks: 2}
+	var buf bytes.Buffer
+	workflow := NewWorkflow(client, NewAuditLog(&buf), WithPollInterval(time.Millisecond))
+
+	ctx := WithRequester(context.Background(), "alice@example.com")
+	result, err := workflow.RequestDeletion(ctx, "abc", "takedown request")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !result.Deleted() || result.Checks != 3 || result.RequestedBy != "alice@example.com" {
+		t.Errorf("Result was not as expected: %+v", result)
+	}
+	if client.reason != "takedown request" {
+		t.Errorf("Reason was not sent upstream: %s", client.reason)
+	}
+
+	expected := []Action{ActionRequested, ActionSubmitted, ActionChecked, ActionChecked, ActionDeleted}
+	got := actions(t, &buf)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected audit actions %v but got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected audit actions %v but got %v", expected, got)
+			break
+		}
+	}
+}
+
+func TestRequestDeletionAlreadyDeleted(t *testing.T) {
+	client := &fakeClient{status: "deleted"}
+	var buf bytes.Buffer
+	result, err := NewWorkflow(client, NewAuditLog(&buf)).RequestDeletion(context.Background(), "abc", "")
+	if err != nil || !result.Deleted() || client.checks != 0 {
+		t.Errorf("An already deleted podcast should not be checked: %+v %v", result, err)
+	}
+}
+
+func TestRequestDeletionTimeout(t *testing.T) {
+	client := &fakeClient{status: "in review", existsChecks: 1000}
+	var buf bytes.Buffer
+	workflow := NewWorkflow(client, NewAuditLog(&buf), WithPollInterval(time.Millisecond), WithTimeout(0))
+
+	if _, err := workflow.RequestDeletion(context.Background(), "abc", ""); err != ErrTimeout {
+		t.Errorf("Expected timeout but got: %v", err)
+	}
+	got := actions(t, &buf)
+	if got[len(got)-1] != ActionTimedOut {
+		t.Errorf("Timeout was not recorded: %v", got)
+	}
+}
+
+func TestRequestDeletionCancelled(t *testing.T) {
+	client := &fakeClient{status: "in review", existsChecks: 1000}
+	var buf bytes.Buffer
+	workflow := NewWorkflow(client, NewAuditLog(&buf), WithPollInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := workflow.RequestDeletion(ctx, "abc", ""); err != context.Canceled {
+		t.Errorf("Expected cancellation but got: %v", err)
+	}
+}
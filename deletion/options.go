@@ -0,0 +1,22 @@
+package deletion
+
+import (
+	"time"
+)
+
+// Option allows for options to be passed to the workflow constructor function
+type Option func(w *Workflow)
+
+// WithPollInterval sets how often the podcast is checked after the request.  Defaults to 10 minutes.
+func WithPollInterval(interval time.Duration) Option {
+	return func(w *Workflow) {
+		w.pollInterval = interval
+	}
+}
+
+// WithTimeout sets how long to wait for the deletion to be confirmed.  Defaults to 24 hours.
+func WithTimeout(timeout time.Duration) Option {
+	return func(w *Workflow) {
+		w.timeout = timeout
+	}
+}
@@ -0,0 +1,344 @@
+// Package description renders the html found in podcast and episode descriptions (show notes) for other media:
+// sanitized html from a safe allow-list, plain text, Markdown and short summaries.  It also extracts the links,
+// email addresses and timestamps mentioned in show notes.
+package description
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// allowedElements are kept by Sanitize, everything else is stripped leaving its text
+var allowedElements = map[string]bool{
+	"p": true, "br": true, "a": true, "strong": true, "b": true, "em": true, "i": true, "u": true, "ul": true,
+	"ol": true, "li": true, "blockquote": true, "code": true, "pre": true,
+}
+
+// Sanitize returns the description as html that only contains allow-listed elements.  Links keep their href when it
+// is an http, https or mailto url, and get rel="nofollow noopener".  All other attributes are removed.
+func Sanitize(description string) string {
+	var b strings.Builder
+	var open []string
+	skip := 0
+
+	for _, t := range tokenize(description) {
+		if skip > 0 {
+			switch {
+			case t.typ == startTagToken && droppedElements[t.data]:
+				skip++
+			case t.typ == endTagToken && droppedElements[t.data]:
+				skip--
+			}
+			continue
+		}
+
+		switch t.typ {
+		case textToken:
+			b.WriteString(html.EscapeString(t.data))
+		case startTagToken, selfClosingTagToken:
+			if droppedElements[t.data] {
+				if t.typ == startTagToken {
+					skip++
+				}
+				continue
+			}
+			if !allowedElements[t.data] {
+				continue
+			}
+			if t.data == "br" {
+				b.WriteString("<br>")
+				continue
+			}
+			if t.typ == selfClosingTagToken {
+				continue
+			}
+			b.WriteString("<" + t.data)
+			if t.data == "a" {
+				if href, ok := safeURL(t.attrs["href"]); ok {
+					b.WriteString(` href="` + html.EscapeString(href) + `" rel="nofollow noopener"`)
+				}
+			}
+			b.WriteString(">")
+			open = append(open, t.data)
+		case endTagToken:
+			// only close what is open, closing any element left open inside it
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != t.data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// PlainText returns the text of the description.  Paragraphs are separated by a blank line, line breaks and list
+// items start a new line.
+func PlainText(description string) string {
+	return render(description, false)
+}
+
+// Markdown returns the description as Markdown
+func Markdown(description string) string {
+	return render(description, true)
+}
+
+// Summary returns the plain text of the description on a single line, truncated to at most maxChars characters at a
+// word boundary.  A truncated summary ends with "…".
+func Summary(description string, maxChars int) string {
+	text := strings.Join(strings.Fields(PlainText(description)), " ")
+	if utf8.RuneCountInString(text) <= maxChars {
+		return text
+	}
+	if maxChars <= 1 {
+		return "…"
+	}
+
+	// leave room for the ellipsis
+	cut := len(text)
+	count := 0
+	for i := range text {
+		if count == maxChars-1 {
+			cut = i
+			break
+		}
+		count++
+	}
+
+	truncated := text[:cut]
+	if !unicode.IsSpace(rune(text[cut])) {
+		if space := strings.LastIndexByte(truncated, ' '); space > 0 {
+			truncated = truncated[:space]
+		}
+	}
+	return strings.TrimRightFunc(truncated, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+// Breaks between pieces of text, a larger break wins over a smaller one
+const (
+	noBreak = iota
+	spaceBreak
+	lineBreak
+	paragraphBreak
+)
+
+type renderer struct {
+	markdown bool
+	b        strings.Builder
+	pending  int
+	lists    []string
+	hrefs    []string
+}
+
+func render(description string, markdown bool) string {
+	r := &renderer{markdown: markdown}
+	skip := 0
+	for _, t := range tokenize(description) {
+		if skip > 0 {
+			switch {
+			case t.typ == startTagToken && droppedElements[t.data]:
+				skip++
+			case t.typ == endTagToken && droppedElements[t.data]:
+				skip--
+			}
+			continue
+		}
+		if t.typ == startTagToken && droppedElements[t.data] {
+			skip++
+			continue
+		}
+		r.token(t)
+	}
+	return strings.TrimSpace(r.b.String())
+}
+
+func (r *renderer) token(t token) {
+	switch t.typ {
+	case textToken:
+		r.text(t.data)
+	case startTagToken, selfClosingTagToken:
+		r.start(t)
+	case endTagToken:
+		r.end(t.data)
+	}
+}
+
+func (r *renderer) text(s string) {
+	if strings.TrimSpace(s) == "" {
+		if s != "" {
+			r.breakAtLeast(spaceBreak)
+		}
+		return
+	}
+
+	if startsWithSpace(s) {
+		r.breakAtLeast(spaceBreak)
+	}
+	words := strings.Fields(s)
+	for i, word := range words {
+		if i > 0 {
+			r.breakAtLeast(spaceBreak)
+		}
+		if r.markdown {
+			word = escapeMarkdown(word)
+		}
+		r.write(word)
+	}
+	if endsWithSpace(s) {
+		r.breakAtLeast(spaceBreak)
+	}
+}
+
+func (r *renderer) start(t token) {
+	switch t.data {
+	case "br":
+		if r.markdown && r.b.Len() > 0 {
+			r.b.WriteString("  ")
+		}
+		r.breakAtLeast(lineBreak)
+	case "ul", "ol":
+		r.lists = append(r.lists, t.data)
+		r.breakAtLeast(paragraphBreak)
+	case "li":
+		r.breakAtLeast(lineBreak)
+		r.write(r.bullet())
+	case "strong", "b":
+		if r.markdown {
+			r.write("**")
+		}
+	case "em", "i":
+		if r.markdown {
+			r.write("*")
+		}
+	case "a":
+		href, _ := safeURL(t.attrs["href"])
+		r.hrefs = append(r.hrefs, href)
+		if r.markdown && href != "" {
+			r.write("[")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.breakAtLeast(paragraphBreak)
+		if r.markdown {
+			r.write(strings.Repeat("#", int(t.data[1]-'0')) + " ")
+		}
+	default:
+		if blockElements[t.data] {
+			r.breakAtLeast(paragraphBreak)
+		}
+	}
+}
+
+func (r *renderer) end(name string) {
+	switch name {
+	case "ul", "ol":
+		if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+		r.breakAtLeast(paragraphBreak)
+	case "li":
+		r.breakAtLeast(lineBreak)
+	case "strong", "b":
+		if r.markdown {
+			r.b.WriteString("**")
+		}
+	case "em", "i":
+		if r.markdown {
+			r.b.WriteString("*")
+		}
+	case "a":
+		if len(r.hrefs) == 0 {
+			return
+		}
+		href := r.hrefs[len(r.hrefs)-1]
+		r.hrefs = r.hrefs[:len(r.hrefs)-1]
+		if r.markdown && href != "" {
+			r.b.WriteString("](" + href + ")")
+		}
+	default:
+		if blockElements[name] || (len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6') {
+			r.breakAtLeast(paragraphBreak)
+		}
+	}
+}
+
+func (r *renderer) bullet() string {
+	indent := ""
+	if len(r.lists) > 1 {
+		indent = strings.Repeat("  ", len(r.lists)-1)
+	}
+	if len(r.lists) > 0 && r.lists[len(r.lists)-1] == "ol" && r.markdown {
+		return indent + "1. "
+	}
+	return indent + "- "
+}
+
+func (r *renderer) breakAtLeast(level int) {
+	if level > r.pending {
+		r.pending = level
+	}
+}
+
+// write outputs s after the pending break.  Breaks before the first text are dropped.
+func (r *renderer) write(s string) {
+	if r.b.Len() > 0 {
+		switch r.pending {
+		case spaceBreak:
+			r.b.WriteString(" ")
+		case lineBreak:
+			r.b.WriteString("\n")
+		case paragraphBreak:
+			r.b.WriteString("\n\n")
+		}
+	}
+	r.pending = noBreak
+	r.b.WriteString(s)
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`")
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// safeURL only allows absolute http, https and mailto urls
+func safeURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+		return raw, true
+	case "mailto":
+		return raw, true
+	default:
+		return "", false
+	}
+}
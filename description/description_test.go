@@ -0,0 +1,105 @@
+package description
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const showNotes = `<p>Ben and James discuss <strong>Spotify</strong> &amp; podcasts.</p>
+<p><strong>Links</strong></p>
+<ul>
+<li>Ben Thompson: Spotify&#8217;s Surprise — <a href="https://stratechery.com/2021/spotifys-surprise/" onclick="steal()">Stratechery</a></li>
+<li><a href="javascript:alert(1)">Bad link</a></li>
+</ul>
+<script>alert("x")</script>
+<p>00:00 Intro<br />12:34 Interview<br/>1:02:03 Wrap up</p>
+<p>Contact: hello@example.com or visit https://example.com/show.</p>`
+
+func TestSanitize(t *testing.T) {
+	sanitized := Sanitize(showNotes)
+	for _, unwanted := range []string{"script", "onclick", "javascript", "alert"} {
+		if strings.Contains(sanitized, unwanted) {
+			t.Errorf("Sanitized html should not contain %s:\n%s", unwanted, sanitized)
+		}
+	}
+	expected := `<a href="https://stratechery.com/2021/spotifys-surprise/" rel="nofollow noopener">Stratechery</a>`
+	if !strings.Contains(sanitized, expected) {
+		t.Errorf("Safe link was not kept:\n%s", sanitized)
+	}
+	if !strings.Contains(sanitized, "<strong>Spotify</strong> &amp; podcasts") {
+		t.Errorf("Allowed formatting was not kept:\n%s", sanitized)
+	}
+
+	if s := Sanitize(`<p><b>unclosed <div>x</p>`); s != `<p><b>unclosed x</b></p>` {
+		t.Errorf("Unbalanced html was not fixed: %s", s)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	expected := "Ben and James discuss Spotify & podcasts.\n\n" +
+		"Links\n\n" +
+		"- Ben Thompson: Spotify’s Surprise — Stratechery\n" +
+		"- Bad link\n\n" +
+		"00:00 Intro\n12:34 Interview\n1:02:03 Wrap up\n\n" +
+		"Contact: hello@example.com or visit https://example.com/show."
+	if text := PlainText(showNotes); text != expected {
+		t.Errorf("Plain text was not as expected:\n%s", text)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md := Markdown(showNotes)
+	if !strings.HasPrefix(md, "Ben and James discuss **Spotify** & podcasts.\n\n**Links**\n\n") {
+		t.Errorf("Markdown was not as expected:\n%s", md)
+	}
+	if !strings.Contains(md, "- Ben Thompson: Spotify’s Surprise — [Stratechery](https://stratechery.com/2021/spotifys-surprise/)\n- Bad link") {
+		t.Errorf("Markdown links were not as expected:\n%s", md)
+	}
+	if !strings.Contains(md, "00:00 Intro  \n12:34 Interview") {
+		t.Errorf("Markdown line breaks were not as expected:\n%s", md)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	if s := Summary(showNotes, 30); s != "Ben and James discuss Spotify…" {
+		t.Errorf("Summary was not as expected: %q", s)
+	}
+	if s := Summary("<p>Short</p>", 30); s != "Short" {
+		t.Errorf("Short summary should not be truncated: %q", s)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	extracted := Extract(showNotes)
+
+	if len(extracted.Links) != 2 ||
+		extracted.Links[0].URL != "https://stratechery.com/2021/spotifys-surprise/" || extracted.Links[0].Text != "Stratechery" ||
+		extracted.Links[1].URL != "https://example.com/show" {
+		t.Errorf("Links were not as expected: %+v", extracted.Links)
+	}
+	if len(extracted.Emails) != 1 || extracted.Emails[0] != "hello@example.com" {
+		t.Errorf("Emails were not as expected: %v", extracted.Emails)
+	}
+
+	expected := []Timestamp{
+		{Text: "00:00", Offset: 0, Label: "Intro"},
+		{Text: "12:34", Offset: 12*time.Minute + 34*time.Second, Label: "Interview"},
+		{Text: "1:02:03", Offset: time.Hour + 2*time.Minute + 3*time.Second, Label: "Wrap up"},
+	}
+	if len(extracted.Timestamps) != len(expected) {
+		t.Fatalf("Timestamps were not as expected: %+v", extracted.Timestamps)
+	}
+	for i, e := range expected {
+		if extracted.Timestamps[i] != e {
+			t.Errorf("Timestamp %d was %+v, expected %+v", i, extracted.Timestamps[i], e)
+		}
+	}
+}
+
+func TestTimestampsInline(t *testing.T) {
+	timestamps := Timestamps("Chapters: 00:00 Intro, 12:30 Interview with Jane")
+	if len(timestamps) != 2 || timestamps[0].Label != "Intro" || timestamps[1].Label != "Interview with Jane" {
+		t.Errorf("Inline timestamps were not as expected: %+v", timestamps)
+	}
+}
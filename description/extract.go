@@ -0,0 +1,122 @@
+package description
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Link is a url found in a description
+type Link struct {
+	URL string `json:"url"`
+	// Text is the link text for <a> elements, empty for urls found in the text
+	Text string `json:"text"`
+}
+
+// Timestamp is a time reference like "12:34" or "1:02:03" found in show notes
+type Timestamp struct {
+	Text   string        `json:"text"`
+	Offset time.Duration `json:"offset"`
+	// Label is the rest of the line the timestamp was found on, e.g. "Interview" for "12:30 Interview"
+	Label string `json:"label"`
+}
+
+// Extracted is the structured data found in a description
+type Extracted struct {
+	Links      []Link      `json:"links"`
+	Emails     []string    `json:"emails"`
+	Timestamps []Timestamp `json:"timestamps"`
+}
+
+var (
+	bareURLPattern   = regexp.MustCompile(`https?://[^\s<>"']+`)
+	emailPattern     = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	timestampPattern = regexp.MustCompile(`(?:^|[^\d:])((?:\d{1,2}:)?[0-5]?\d:[0-5]\d)(?:$|[^\d:])`)
+	labelTrim        = " \t-–—:|)].,"
+)
+
+// Extract finds the links, email addresses and timestamps of a description.  Duplicates are only reported once, in
+// order of appearance.
+func Extract(description string) Extracted {
+	extracted := Extracted{}
+	seenURLs := map[string]bool{}
+	addLink := func(link Link) {
+		if link.URL == "" || seenURLs[link.URL] {
+			return
+		}
+		seenURLs[link.URL] = true
+		extracted.Links = append(extracted.Links, link)
+	}
+
+	// links from anchors first, they carry a text
+	var anchor *Link
+	for _, t := range tokenize(description) {
+		switch {
+		case t.typ == startTagToken && t.data == "a":
+			href, _ := safeURL(t.attrs["href"])
+			anchor = &Link{URL: href}
+		case t.typ == textToken && anchor != nil:
+			anchor.Text += t.data
+		case t.typ == endTagToken && t.data == "a" && anchor != nil:
+			anchor.Text = strings.Join(strings.Fields(anchor.Text), " ")
+			if strings.HasPrefix(anchor.URL, "mailto:") {
+				anchor.URL = ""
+			}
+			addLink(*anchor)
+			anchor = nil
+		}
+	}
+
+	text := PlainText(description)
+	for _, raw := range bareURLPattern.FindAllString(text, -1) {
+		addLink(Link{URL: strings.TrimRight(raw, ".,;:!?)]}")})
+	}
+
+	seenEmails := map[string]bool{}
+	for _, email := range emailPattern.FindAllString(text+" "+description, -1) {
+		email = strings.ToLower(email)
+		if !seenEmails[email] {
+			seenEmails[email] = true
+			extracted.Emails = append(extracted.Emails, email)
+		}
+	}
+
+	extracted.Timestamps = Timestamps(text)
+	return extracted
+}
+
+// Timestamps finds the timestamps of plain text, e.g. PlainText of a description
+func Timestamps(text string) []Timestamp {
+	var timestamps []Timestamp
+	for _, line := range strings.Split(text, "\n") {
+		matches := timestampPattern.FindAllStringSubmatchIndex(line, -1)
+		for i, m := range matches {
+			labelEnd := len(line)
+			if i+1 < len(matches) {
+				labelEnd = matches[i+1][2]
+			}
+			label := line[m[3]:labelEnd]
+			// a timestamp at the end of its line labels itself with the text before it, e.g. "Intro - 00:00"
+			if strings.Trim(label, labelTrim) == "" && i == 0 {
+				label = line[:m[2]]
+			}
+			timestamps = append(timestamps, Timestamp{
+				Text:   line[m[2]:m[3]],
+				Offset: parseClock(line[m[2]:m[3]]),
+				Label:  strings.Trim(label, labelTrim+"(["),
+			})
+		}
+	}
+	return timestamps
+}
+
+// parseClock parses "mm:ss" and "hh:mm:ss"
+func parseClock(clock string) time.Duration {
+	var d time.Duration
+	for _, part := range strings.Split(clock, ":") {
+		n, _ := strconv.Atoi(part)
+		d = d*60 + time.Duration(n)*time.Second
+	}
+	return d
+}
@@ -0,0 +1,158 @@
+package description
+
+import (
+	"html"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	textToken tokenType = iota
+	startTagToken
+	endTagToken
+	selfClosingTagToken
+)
+
+// token is a piece of a description.  For tags data is the lower case tag name, for text it is the unescaped text.
+type token struct {
+	typ   tokenType
+	data  string
+	attrs map[string]string
+}
+
+// tokenize is a forgiving tokenizer for the html found in show notes.  Comments and doctypes are dropped, anything
+// that does not look like a tag is kept as text.
+func tokenize(s string) []token {
+	var tokens []token
+	var text strings.Builder
+	flushText := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, token{typ: textToken, data: html.UnescapeString(text.String())})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != '<' || i+1 >= len(s) {
+			text.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		next := s[i+1]
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := strings.Index(s[i+4:], "-->")
+			flushText()
+			if end < 0 {
+				return tokens
+			}
+			i += 4 + end + 3
+		case next == '!' || next == '?':
+			end := strings.IndexByte(s[i:], '>')
+			flushText()
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case next == '/' || isLetter(next):
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				text.WriteString(s[i:])
+				i = len(s)
+				continue
+			}
+			flushText()
+			tokens = append(tokens, parseTag(s[i+1:i+end]))
+			i += end + 1
+		default:
+			text.WriteByte(s[i])
+			i++
+		}
+	}
+	flushText()
+	return tokens
+}
+
+func parseTag(raw string) token {
+	t := token{typ: startTagToken}
+	if strings.HasPrefix(raw, "/") {
+		t.typ = endTagToken
+		raw = raw[1:]
+	}
+	if strings.HasSuffix(raw, "/") {
+		t.typ = selfClosingTagToken
+		raw = raw[:len(raw)-1]
+	}
+
+	nameEnd := strings.IndexAny(raw, " \t\r\n")
+	if nameEnd < 0 {
+		nameEnd = len(raw)
+	}
+	t.data = strings.ToLower(raw[:nameEnd])
+	t.attrs = parseAttrs(raw[nameEnd:])
+	if t.typ == startTagToken && voidElements[t.data] {
+		t.typ = selfClosingTagToken
+	}
+	return t
+}
+
+func parseAttrs(raw string) map[string]string {
+	attrs := map[string]string{}
+	for {
+		raw = strings.TrimLeft(raw, " \t\r\n")
+		if raw == "" {
+			return attrs
+		}
+
+		nameEnd := strings.IndexAny(raw, "= \t\r\n")
+		if nameEnd < 0 {
+			attrs[strings.ToLower(raw)] = ""
+			return attrs
+		}
+		name := strings.ToLower(raw[:nameEnd])
+		raw = strings.TrimLeft(raw[nameEnd:], " \t\r\n")
+		if !strings.HasPrefix(raw, "=") {
+			attrs[name] = ""
+			continue
+		}
+		raw = strings.TrimLeft(raw[1:], " \t\r\n")
+
+		var value string
+		if raw != "" && (raw[0] == '"' || raw[0] == '\'') {
+			end := strings.IndexByte(raw[1:], raw[0])
+			if end < 0 {
+				value, raw = raw[1:], ""
+			} else {
+				value, raw = raw[1:end+1], raw[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(raw, " \t\r\n")
+			if end < 0 {
+				end = len(raw)
+			}
+			value, raw = raw[:end], raw[end:]
+		}
+		attrs[name] = html.UnescapeString(value)
+	}
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+var voidElements = map[string]bool{
+	"br": true, "hr": true, "img": true, "input": true, "meta": true, "link": true, "source": true, "wbr": true,
+}
+
+// blockElements end a paragraph when they open or close
+var blockElements = map[string]bool{
+	"p": true, "div": true, "ul": true, "ol": true, "li": true, "blockquote": true, "pre": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "table": true, "tr": true, "section": true,
+}
+
+// droppedElements are removed along with their content
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true, "template": true,
+}
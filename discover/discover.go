@@ -0,0 +1,259 @@
+// Package discover adds client-side filtering to JustListen, for "surprise me" features.
+//
+// JustListen returns a single random episode per call.  A Session calls it until an episode meets the constraints,
+// within a budget of api calls, and never returns the same episode twice:
+//
+//	d := discover.NewDiscoverer(client, discover.WithBudget(15))
+//	session := d.NewSession()
+//	pick, err := session.Next(discover.Constraints{
+//		Languages:      []string{"English"},
+//		MaxLength:      30 * time.Minute,
+//		ExcludeExplicit: true,
+//	})
+//
+// The daily pick is the same for everyone sharing a seed and a PickStore on a given day.  JustListen cannot be
+// replayed, so the pick is found once and kept in the store.
+package discover
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// ErrBudgetExhausted is returned when no episode met the constraints within the call budget
+var ErrBudgetExhausted = errors.New("no episode met the constraints within the call budget")
+
+// Constraints are the client-side filters of a pick.  Zero values do not filter.
+type Constraints struct {
+	// Languages of the podcast, e.g. "English", any of them matches
+	Languages []string
+	// GenreIDs of the podcast, any of them matches
+	GenreIDs        []int
+	MinLength       time.Duration
+	MaxLength       time.Duration
+	ExcludeExplicit bool
+	// MinListenScore needs a PRO plan, without one no episode has a listen score to match
+	MinListenScore int
+	// SkipInvalidAudio drops episodes flagged with maybe_audio_invalid
+	SkipInvalidAudio bool
+}
+
+// needsPodcast reports whether checking the constraints needs the full podcast, which costs an extra call
+func (c Constraints) needsPodcast() bool {
+	return len(c.Languages) > 0 || len(c.GenreIDs) > 0
+}
+
+// Pick is an episode that met the constraints
+type Pick struct {
+	Episode listennotes.Episode
+	// Podcast is the full podcast when the constraints needed it, otherwise the summary JustListen returns
+	Podcast listennotes.Podcast
+	// Calls is the number of api calls it took
+	Calls int
+}
+
+// Discoverer finds random episodes meeting constraints
+type Discoverer struct {
+	client listennotes.HTTPClient
+	budget int
+	picks  PickStore
+
+	mu       sync.Mutex
+	podcasts map[string]listennotes.Podcast
+}
+
+// NewDiscoverer will create a discoverer with reasonable defaults.
+// You can optionally override some configuration.
+func NewDiscoverer(client listennotes.HTTPClient, opts ...Option) *Discoverer {
+	d := &Discoverer{
+		client:   client,
+		budget:   20,
+		picks:    NewMemoryPickStore(),
+		podcasts: map[string]listennotes.Podcast{},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Session is a sequence of picks without repeats.  A session is not safe for concurrent use.
+type Session struct {
+	d    *Discoverer
+	seen map[string]bool
+}
+
+// NewSession will start a session
+func (d *Discoverer) NewSession() *Session {
+	return &Session{d: d, seen: map[string]bool{}}
+}
+
+// Next calls JustListen until an episode not returned before in the session meets the constraints.  It returns
+// ErrBudgetExhausted when the call budget runs out first.  Episodes without a podcast are skipped when the
+// constraints need the podcast.
+func (s *Session) Next(c Constraints) (Pick, error) {
+	args := map[string]string{}
+	if c.ExcludeExplicit {
+		args["safe_mode"] = "1"
+	}
+
+	calls := 0
+	for calls < s.d.budget {
+		calls++
+		resp, err := s.d.client.JustListen(args)
+		if err != nil {
+			return Pick{}, fmt.Errorf("failed to fetch a random episode: %w", err)
+		}
+		var episode listennotes.Episode
+		if err := resp.Decode(&episode); err != nil {
+			return Pick{}, err
+		}
+
+		if episode.ID == "" || s.seen[episode.ID] {
+			continue
+		}
+		s.seen[episode.ID] = true
+		if !matchesEpisode(episode, c) {
+			continue
+		}
+
+		var podcast listennotes.Podcast
+		if episode.Podcast != nil {
+			podcast = *episode.Podcast
+		}
+		if c.needsPodcast() {
+			// the podcast constraints cannot be checked without the podcast
+			if podcast.ID == "" {
+				continue
+			}
+			full, ok := s.d.cachedPodcast(podcast.ID)
+			if !ok {
+				if calls >= s.d.budget {
+					break
+				}
+				calls++
+				var err error
+				if full, err = s.d.fetchPodcast(podcast.ID); err != nil {
+					return Pick{}, err
+				}
+			}
+			podcast = full
+			if !matchesPodcast(podcast, c) {
+				continue
+			}
+		}
+		return Pick{Episode: episode, Podcast: podcast, Calls: calls}, nil
+	}
+	return Pick{}, ErrBudgetExhausted
+}
+
+// DailyPick returns the pick of the day for the seed, e.g. a user id or "global".  The first call of a day finds it,
+// later calls return the stored pick.  The pick is random, not derived from the seed, so separate processes only
+// return the same pick when they share a PickStore, see WithPickStore.  The default store is per process.
+func (d *Discoverer) DailyPick(seed string, day time.Time, c Constraints) (Pick, error) {
+	key := seed + " " + day.Format("2006-01-02")
+	if pick, ok, err := d.picks.Get(key); err != nil {
+		return Pick{}, fmt.Errorf("failed to load daily pick: %w", err)
+	} else if ok {
+		return pick, nil
+	}
+
+	pick, err := d.NewSession().Next(c)
+	if err != nil {
+		return Pick{}, err
+	}
+	// another caller may have stored a pick in the meantime, theirs wins so everyone gets the same one
+	stored, err := d.picks.PutIfAbsent(key, pick)
+	if err != nil {
+		return Pick{}, fmt.Errorf("failed to store daily pick: %w", err)
+	}
+	return stored, nil
+}
+
+// cachedPodcast returns the full podcast if it was fetched before
+func (d *Discoverer) cachedPodcast(id string) (listennotes.Podcast, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	podcast, ok := d.podcasts[id]
+	return podcast, ok
+}
+
+// fetchPodcast fetches the full podcast and caches it
+func (d *Discoverer) fetchPodcast(id string) (listennotes.Podcast, error) {
+	resp, err := d.client.FetchPodcastByID(id, nil)
+	if err != nil {
+		return listennotes.Podcast{}, fmt.Errorf("failed to fetch podcast %s: %w", id, err)
+	}
+	var podcast listennotes.Podcast
+	if err := resp.Decode(&podcast); err != nil {
+		return listennotes.Podcast{}, err
+	}
+	podcast.Episodes = nil
+
+	d.mu.Lock()
+	d.podcasts[id] = podcast
+	d.mu.Unlock()
+	return podcast, nil
+}
+
+func matchesEpisode(e listennotes.Episode, c Constraints) bool {
+	if c.ExcludeExplicit && e.ExplicitContent {
+		return false
+	}
+	if c.SkipInvalidAudio && e.MaybeAudioInvalid {
+		return false
+	}
+	if c.MinLength > 0 && e.AudioLength() < c.MinLength {
+		return false
+	}
+	if c.MaxLength > 0 && e.AudioLength() > c.MaxLength {
+		return false
+	}
+	if c.MinListenScore > 0 {
+		if e.Podcast == nil {
+			return false
+		}
+		if score, ok := e.Podcast.ListenScore.Value(); !ok || score < c.MinListenScore {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesPodcast(p listennotes.Podcast, c Constraints) bool {
+	if c.ExcludeExplicit && p.ExplicitContent {
+		return false
+	}
+	if len(c.Languages) > 0 {
+		found := false
+		for _, language := range c.Languages {
+			if language == p.Language {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(c.GenreIDs) > 0 {
+		found := false
+		for _, wanted := range c.GenreIDs {
+			for _, g := range p.GenreIDs {
+				if g == wanted {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
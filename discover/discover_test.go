@@ -0,0 +1,148 @@
+package discover
+
+import (
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	episodes     []map[string]interface{}
+	podcasts     map[string]map[string]interface{}
+	calls        int
+	podcastCalls int
+	lastSafeMode string
+}
+
+func (c *fakeClient) JustListen(args map[string]string) (*listennotes.Response, error) {
+	c.lastSafeMode = args["safe_mode"]
+	episode := c.episodes[c.calls%len(c.episodes)]
+	c.calls++
+	return &listennotes.Response{Data: episode}, nil
+}
+
+func (c *fakeClient) FetchPodcastByID(id string, args map[string]string) (*listennotes.Response, error) {
+	c.podcastCalls++
+	return &listennotes.Response{Data: c.podcasts[id]}, nil
+}
+
+func episode(id string, seconds int, explicit bool, podcastID string, score int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               id,
+		"audio_length_sec": float64(seconds),
+		"explicit_content": explicit,
+		"podcast":          map[string]interface{}{"id": podcastID, "listen_score": float64(score)},
+	}
+}
+
+func TestNext(t *testing.T) {
+	client := &fakeClient{
+		episodes: []map[string]interface{}{
+			episode("e1", 3600, false, "p1", 50),
+			episode("e2", 600, true, "p1", 50),
+			episode("e3", 900, false, "p2", 30),
+			episode("e4", 1200, false, "p2", 60),
+			episode("e5", 1200, false, "p1", 60),
+		},
+		podcasts: map[string]map[string]interface{}{
+			"p1": {"id": "p1", "language": "English", "genre_ids": []interface{}{float64(68)}},
+			"p2": {"id": "p2", "language": "Spanish", "genre_ids": []interface{}{float64(68)}},
+		},
+	}
+	session := NewDiscoverer(client).NewSession()
+	c := Constraints{
+		Languages:       []string{"English"},
+		MaxLength:       30 * time.Minute,
+		ExcludeExplicit: true,
+		MinListenScore:  40,
+	}
+
+	pick, err := session.Next(c)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if pick.Episode.ID != "e5" || pick.Podcast.Language != "English" {
+		t.Errorf("Pick was not as expected: %+v", pick)
+	}
+	// e1..e5 and the lookups of p2 and p1
+	if pick.Calls != 7 || client.podcastCalls != 2 {
+		t.Errorf("Expected 7 calls with 2 podcast lookups but got %d and %d", pick.Calls, client.podcastCalls)
+	}
+	if client.lastSafeMode != "1" {
+		t.Errorf("Safe mode should be requested when excluding explicit episodes")
+	}
+
+	// every episode has been seen in the session
+	if _, err := session.Next(Constraints{}); err != ErrBudgetExhausted {
+		t.Errorf("Expected the budget to run out on repeats but got: %v", err)
+	}
+	if client.podcastCalls != 2 {
+		t.Errorf("Podcasts should not be fetched without language or genre constraints")
+	}
+}
+
+func TestNextBudget(t *testing.T) {
+	client := &fakeClient{episodes: []map[string]interface{}{episode("e1", 3600, false, "p1", 50)}}
+	session := NewDiscoverer(client, WithBudget(3)).NewSession()
+	if _, err := session.Next(Constraints{MaxLength: time.Minute}); err != ErrBudgetExhausted {
+		t.Errorf("Expected the budget to be exhausted but got: %v", err)
+	}
+	if client.calls != 3 {
+		t.Errorf("Expected 3 calls but got %d", client.calls)
+	}
+}
+
+func TestDailyPick(t *testing.T) {
+	client := &fakeClient{episodes: []map[string]interface{}{
+		episode("e1", 600, false, "p1", 50),
+		episode("e2", 600, false, "p1", 50),
+	}}
+	d := NewDiscoverer(client)
+	day := time.Date(2021, 5, 3, 8, 0, 0, 0, time.UTC)
+
+	first, err := d.DailyPick("global", day, Constraints{})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	again, _ := d.DailyPick("global", day.Add(10*time.Hour), Constraints{})
+	if again.Episode.ID != first.Episode.ID || client.calls != 1 {
+		t.Errorf("The same day should return the same pick: %s and %s", first.Episode.ID, again.Episode.ID)
+	}
+	next, _ := d.DailyPick("global", day.AddDate(0, 0, 1), Constraints{})
+	if next.Episode.ID == first.Episode.ID || client.calls != 2 {
+		t.Errorf("A new day should get a new pick: %s", next.Episode.ID)
+	}
+}
+
+func TestNextBudgetPodcastLookup(t *testing.T) {
+	client := &fakeClient{
+		episodes: []map[string]interface{}{episode("e1", 600, false, "p1", 50)},
+		podcasts: map[string]map[string]interface{}{"p1": {"id": "p1", "language": "English"}},
+	}
+	session := NewDiscoverer(client, WithBudget(1)).NewSession()
+	if _, err := session.Next(Constraints{Languages: []string{"English"}}); err != ErrBudgetExhausted {
+		t.Errorf("Expected the budget to be exhausted but got: %v", err)
+	}
+	if client.calls != 1 || client.podcastCalls != 0 {
+		t.Errorf("The podcast lookup should not exceed the budget: %d calls and %d lookups", client.calls, client.podcastCalls)
+	}
+}
+
+func TestNextWithoutPodcast(t *testing.T) {
+	orphan := episode("e1", 600, false, "", 0)
+	delete(orphan, "podcast")
+	client := &fakeClient{
+		episodes: []map[string]interface{}{orphan, episode("e2", 600, false, "p1", 50)},
+		podcasts: map[string]map[string]interface{}{"p1": {"id": "p1", "language": "English"}},
+	}
+	session := NewDiscoverer(client).NewSession()
+	pick, err := session.Next(Constraints{Languages: []string{"English"}})
+	if err != nil || pick.Episode.ID != "e2" {
+		t.Fatalf("Expected the episode with a podcast but got %+v: %v", pick, err)
+	}
+	if client.podcastCalls != 1 {
+		t.Errorf("Episodes without a podcast should be skipped: %d lookups", client.podcastCalls)
+	}
+}
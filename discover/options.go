@@ -0,0 +1,20 @@
+package discover
+
+// Option allows for options to be passed to the discoverer constructor function
+type Option func(d *Discoverer)
+
+// WithBudget caps the api calls spent on a single pick, podcast lookups for language and genre constraints included.
+// The default is 20.
+func WithBudget(calls int) Option {
+	return func(d *Discoverer) {
+		d.budget = calls
+	}
+}
+
+// WithPickStore sets where daily picks are kept, in memory by default.  Use a shared store so that every instance of
+// the app serves the same daily pick.
+func WithPickStore(store PickStore) Option {
+	return func(d *Discoverer) {
+		d.picks = store
+	}
+}
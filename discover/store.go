@@ -0,0 +1,46 @@
+package discover
+
+import (
+	"sync"
+)
+
+// PickStore keeps the daily picks.  Implementations must be safe for concurrent use.
+type PickStore interface {
+	Get(key string) (Pick, bool, error)
+	// PutIfAbsent stores the pick unless the key has one already, and returns the stored pick
+	PutIfAbsent(key string, pick Pick) (Pick, error)
+}
+
+// MemoryPickStore is a PickStore that keeps picks in memory, state is lost on restart
+type MemoryPickStore struct {
+	mu    sync.Mutex
+	picks map[string]Pick
+}
+
+var _ PickStore = &MemoryPickStore{}
+
+// NewMemoryPickStore will create an empty in-memory store
+func NewMemoryPickStore() *MemoryPickStore {
+	return &MemoryPickStore{picks: map[string]Pick{}}
+}
+
+// Get implements PickStore
+func (s *MemoryPickStore) Get(key string) (Pick, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pick, ok := s.picks[key]
+	return pick, ok, nil
+}
+
+// PutIfAbsent implements PickStore
+func (s *MemoryPickStore) PutIfAbsent(key string, pick Pick) (Pick, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.picks[key]; ok {
+		return existing, nil
+	}
+	s.picks[key] = pick
+	return pick, nil
+}
@@ -0,0 +1,362 @@
+// Package diskcache provides a persistent, disk backed response cache for the listennotes client.
+//
+// The cache is an http.RoundTripper, plug it into the client through listennotes.WithHTTPClient:
+//
+//	cache, err := diskcache.New("/var/cache/listennotes", diskcache.WithTTL(time.Hour))
+//	if err != nil {
+//		return err
+//	}
+//	client := listennotes.NewClient(apiKey, listennotes.WithHTTPClient(&http.Client{
+//		Timeout:   30 * time.Second,
+//		Transport: cache,
+//	}))
+//
+// Only successful GET responses are cached.  Stale entries can be served while they are revalidated in the background
+// (stale-while-revalidate), and when the api fails with a server error or the network is down (stale-if-error).
+package diskcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/atomicfile"
+)
+
+// SchemaVersion is the version of the on-disk entry format.  Entries written with another version are ignored and
+// removed, so bumping it invalidates the whole cache.
+const SchemaVersion = 2
+
+// Response header set on responses served from the cache
+const (
+	HeaderKeyCache = "X-Cache"
+
+	CacheHit   = "HIT"
+	CacheStale = "STALE"
+	CacheMiss  = "MISS"
+)
+
+const fileExtension = ".json"
+
+// Entry is the metadata of a cached response
+type Entry struct {
+	Version  int           `json:"version"`
+	Method   string        `json:"method"`
+	URL      string        `json:"url"`
+	Path     string        `json:"path"`
+	StoredAt time.Time     `json:"stored_at"`
+	TTL      time.Duration `json:"ttl"`
+	Status   int           `json:"status"`
+	Header   http.Header   `json:"header"`
+}
+
+// Age is how long ago the entry was stored
+func (e Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.StoredAt)
+}
+
+// Fresh reports whether the entry is still within its ttl
+func (e Entry) Fresh(now time.Time) bool {
+	return e.Age(now) < e.TTL
+}
+
+type record struct {
+	Entry
+	Body []byte `json:"body"`
+}
+
+// Cache is a disk backed http.RoundTripper
+type Cache struct {
+	dir                  string
+	transport            http.RoundTripper
+	ttl                  time.Duration
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+	onError              func(error)
+	now                  func() time.Time
+
+	mu           sync.Mutex
+	revalidating map[string]bool
+}
+
+var _ http.RoundTripper = &Cache{}
+
+// New will create a cache storing its entries in dir, creating it if needed.
+// You can optionally override some configuration.
+func New(dir string, opts ...Option) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+
+	c := &Cache{
+		dir:          dir,
+		transport:    http.DefaultTransport,
+		ttl:          time.Hour,
+		onError:      func(error) {},
+		now:          time.Now,
+		revalidating: map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Cache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return c.transport.RoundTrip(req)
+	}
+
+	key := entryKey(req)
+	cached, _ := c.read(key)
+	now := c.now()
+
+	if cached != nil {
+		age := cached.Age(now)
+		if age < cached.TTL {
+			return cached.response(req, CacheHit), nil
+		}
+		if age < cached.TTL+c.staleWhileRevalidate {
+			resp := cached.response(req, CacheStale)
+			c.revalidate(key, req, cached)
+			return resp, nil
+		}
+	}
+
+	resp, err := c.fetch(key, req, cached)
+	if err != nil || resp.StatusCode >= 500 {
+		if cached != nil && cached.Age(now) < cached.TTL+c.staleIfError {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return cached.response(req, CacheStale), nil
+		}
+	}
+	return resp, err
+}
+
+// fetch sends the request upstream, revalidating the cached entry if there is one, and stores a successful response.
+// The cache is an optimization, failing to store the response does not fail the request.
+func (c *Cache) fetch(key string, req *http.Request, cached *record) (*http.Response, error) {
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("Etag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		for k, v := range resp.Header {
+			cached.Header[k] = v
+		}
+		cached.StoredAt = c.now()
+		cached.TTL = c.ttl
+		c.store(key, cached)
+		return cached.response(req, CacheHit), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &record{
+		Entry: Entry{
+			Version:  SchemaVersion,
+			Method:   req.Method,
+			URL:      req.URL.String(),
+			Path:     endpointPath(req.URL.Path),
+			StoredAt: c.now(),
+			TTL:      c.ttl,
+			Status:   resp.StatusCode,
+			Header:   resp.Header,
+		},
+		Body: body,
+	}
+	c.store(key, rec)
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.Header.Set(HeaderKeyCache, CacheMiss)
+	return resp, nil
+}
+
+// revalidate refreshes the entry in the background, at most once at a time per entry
+func (c *Cache) revalidate(key string, req *http.Request, cached *record) {
+	c.mu.Lock()
+	if c.revalidating[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[key] = true
+	c.mu.Unlock()
+
+	// the caller is served right away, so the revalidation must outlive its request
+	bgReq := req.Clone(context.Background())
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, key)
+			c.mu.Unlock()
+		}()
+		if resp, err := c.fetch(key, bgReq, cached); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+}
+
+// List returns the metadata of all entries for an endpoint.  The endpoint is matched on whole path segments, so
+// "podcasts" lists "podcasts/{id}" and "podcasts/{id}/recommendations" entries.  An empty endpoint lists everything.
+func (c *Cache) List(endpoint string) ([]Entry, error) {
+	var entries []Entry
+	err := c.walk(endpoint, func(_ string, rec *record) error {
+		entries = append(entries, rec.Entry)
+		return nil
+	})
+	return entries, err
+}
+
+// Purge removes all entries for an endpoint, matched like List, and returns how many were removed
+func (c *Cache) Purge(endpoint string) (int, error) {
+	removed := 0
+	err := c.walk(endpoint, func(filename string, _ *record) error {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+func (c *Cache) walk(endpoint string, fn func(filename string, rec *record) error) error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory %s: %w", c.dir, err)
+	}
+
+	endpoint = strings.Trim(endpoint, "/")
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), fileExtension) {
+			continue
+		}
+		rec, err := c.read(strings.TrimSuffix(file.Name(), fileExtension))
+		if err != nil || rec == nil {
+			continue
+		}
+		if endpoint != "" && rec.Path != endpoint && !strings.HasPrefix(rec.Path, endpoint+"/") {
+			continue
+		}
+		if err := fn(filepath.Join(c.dir, file.Name()), rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read loads an entry, a missing entry is not an error.  Entries of another schema version are removed.
+func (c *Cache) read(key string) (*record, error) {
+	filename := c.filename(key)
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &record{}
+	if err := json.Unmarshal(data, rec); err != nil || rec.Version != SchemaVersion {
+		os.Remove(filename)
+		return nil, nil
+	}
+	return rec, nil
+}
+
+// store writes the entry, a failure is reported to the error callback and the response is still served
+func (c *Cache) store(key string, rec *record) {
+	if err := c.write(key, rec); err != nil {
+		c.onError(err)
+	}
+}
+
+// write stores an entry atomically, so that concurrent readers never see a partial file
+func (c *Cache) write(key string, rec *record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := atomicfile.WriteFile(c.filename(key), data); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) filename(key string) string {
+	return filepath.Join(c.dir, key+fileExtension)
+}
+
+func (r *record) response(req *http.Request, cacheStatus string) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(HeaderKeyCache, cacheStatus)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// entryKey identifies a request.  The api key is part of it, playlists and PRO-only fields depend on the account, so a
+// cache directory shared by several keys never serves one account's responses to another.  Only the hash of the key
+// ends up on disk.  The schema version is part of the key so that a new version never reads old files.
+func entryKey(req *http.Request) string {
+	apiKey := req.Header.Get(listennotes.RequestHeaderKeyAPI)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("v%d %s %s %s", SchemaVersion, req.Method, req.URL.String(), apiKey)))
+	return hex.EncodeToString(sum[:])
+}
+
+// endpointPath returns the request path relative to the api base url, e.g. "podcasts/{id}"
+func endpointPath(path string) string {
+	const apiPrefix = "/api/v2/"
+	if i := strings.Index(path, apiPrefix); i >= 0 {
+		path = path[i+len(apiPrefix):]
+	}
+	return strings.Trim(path, "/")
+}
@@ -0,0 +1,228 @@
+package diskcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type testServer struct {
+	*httptest.Server
+	hits   int32
+	status int32
+}
+
+func newTestServer() *testServer {
+	ts := &testServer{status: 200}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&ts.hits, 1)
+		w.Header().Set(listennotes.ResponseHeaderKeyUsage, "42")
+		w.WriteHeader(int(atomic.LoadInt32(&ts.status)))
+		w.Write([]byte(`{"hit": ` + strconv.Itoa(int(n)) + `}`))
+	}))
+	return ts
+}
+
+func newTestClient(t *testing.T, ts *testServer, opts ...Option) (listennotes.HTTPClient, *Cache, *time.Time) {
+	cache, err := New(t.TempDir(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	client := listennotes.NewClient("", listennotes.WithBaseURL(ts.URL+"/api/v2"), listennotes.WithHTTPClient(&http.Client{
+		Transport: cache,
+	}))
+	return client, cache, &now
+}
+
+func TestCacheHit(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	client, _, _ := newTestClient(t, ts, WithTTL(time.Minute))
+
+	client.FetchPodcastByID("abc", nil)
+	resp, err := client.FetchPodcastByID("abc", nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if ts.hits != 1 {
+		t.Errorf("Expected a single upstream request but got %d", ts.hits)
+	}
+	if resp.Data["hit"] != float64(1) || resp.Stats.Usage != 42 {
+		t.Errorf("Cached response was not as stored: %v %v", resp.Data, resp.Stats)
+	}
+}
+
+func TestExpiredAndStaleIfError(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	client, _, now := newTestClient(t, ts, WithTTL(time.Minute), WithStaleIfError(time.Hour))
+
+	client.FetchPodcastByID("abc", nil)
+	*now = now.Add(2 * time.Minute)
+	atomic.StoreInt32(&ts.status, 500)
+
+	resp, err := client.FetchPodcastByID("abc", nil)
+	if err != nil {
+		t.Fatalf("Expected stale response but got: %s", err)
+	}
+	if resp.Data["hit"] != float64(1) || ts.hits != 2 {
+		t.Errorf("Expected the stale response after an upstream attempt: %v %d", resp.Data, ts.hits)
+	}
+
+	*now = now.Add(2 * time.Hour)
+	if _, err := client.FetchPodcastByID("abc", nil); err != listennotes.ErrInternalServerError {
+		t.Errorf("Expected the error once past the stale window but got: %v", err)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	client, cache, now := newTestClient(t, ts, WithTTL(time.Minute), WithStaleWhileRevalidate(time.Hour))
+
+	client.FetchPodcastByID("abc", nil)
+	*now = now.Add(2 * time.Minute)
+
+	resp, _ := client.FetchPodcastByID("abc", nil)
+	if resp.Data["hit"] != float64(1) {
+		t.Errorf("Expected the stale response: %v", resp.Data)
+	}
+
+	for i := 0; i < 100 && atomic.LoadInt32(&ts.hits) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i := 0; i < 100; i++ {
+		cache.mu.Lock()
+		n := len(cache.revalidating)
+		cache.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, _ = client.FetchPodcastByID("abc", nil)
+	if resp.Data["hit"] != float64(2) {
+		t.Errorf("Expected the revalidated response: %v", resp.Data)
+	}
+}
+
+func TestListAndPurge(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	client, cache, _ := newTestClient(t, ts)
+
+	client.FetchPodcastByID("abc", nil)
+	client.FetchRecommendationsForPodcast("abc", nil)
+	client.Search(map[string]string{"q": "star wars"})
+
+	entries, err := cache.List("podcasts")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 podcasts entries but got %d: %v", len(entries), err)
+	}
+
+	removed, err := cache.Purge("podcasts")
+	if err != nil || removed != 2 {
+		t.Errorf("Expected 2 entries purged but got %d: %v", removed, err)
+	}
+
+	entries, _ = cache.List("")
+	if len(entries) != 1 || entries[0].Path != "search" {
+		t.Errorf("Only the search entry should remain: %v", entries)
+	}
+}
+
+func TestOtherVersionIgnored(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	client, cache, _ := newTestClient(t, ts)
+
+	client.FetchPodcastByID("abc", nil)
+	files, _ := filepath.Glob(filepath.Join(cache.dir, "*"+fileExtension))
+	if len(files) != 1 {
+		t.Fatalf("Expected one cache file but got %d", len(files))
+	}
+	os.WriteFile(files[0], []byte(`{"version": 0}`), 0o644)
+
+	client.FetchPodcastByID("abc", nil)
+	if ts.hits != 2 {
+		t.Errorf("Entry of another version should not be served")
+	}
+}
+
+func TestEntriesPerAPIKey(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+	cache, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClient := func(apiKey string) listennotes.HTTPClient {
+		return listennotes.NewClient(apiKey, listennotes.WithBaseURL(ts.URL+"/api/v2"), listennotes.WithHTTPClient(&http.Client{
+			Transport: cache,
+		}))
+	}
+	alice, bob := newClient("alice-key"), newClient("bob-key")
+
+	alice.FetchMyPlaylists(nil)
+	resp, _ := bob.FetchMyPlaylists(nil)
+	if ts.hits != 2 || resp.Data["hit"] != float64(2) {
+		t.Errorf("A response cached for one key should not be served to another: %v", resp.Data)
+	}
+	resp, _ = alice.FetchMyPlaylists(nil)
+	if ts.hits != 2 || resp.Data["hit"] != float64(1) {
+		t.Errorf("Expected the response cached for the same key: %v", resp.Data)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cache.dir, "*"+fileExtension))
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "alice-key") {
+			t.Errorf("The api key should not be stored in the cache")
+		}
+	}
+}
+
+func TestUnwritableCache(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	// a file in place of the cache directory fails every write, even for root
+	dir := filepath.Join(t.TempDir(), "cache")
+	var errs []error
+	cache, err := New(dir, WithErrorFunc(func(err error) { errs = append(errs, err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := listennotes.NewClient("", listennotes.WithBaseURL(ts.URL+"/api/v2"), listennotes.WithHTTPClient(&http.Client{
+		Transport: cache,
+	}))
+	resp, err := client.FetchPodcastByID("abc", nil)
+	if err != nil {
+		t.Fatalf("A failed cache write should not fail the request: %s", err)
+	}
+	if resp.Data["hit"] != float64(1) {
+		t.Errorf("Expected the upstream response but got %v", resp.Data)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected the write error to be reported but got %v", errs)
+	}
+}
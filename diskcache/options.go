@@ -0,0 +1,48 @@
+package diskcache
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option allows for options to be passed to the cache constructor function
+type Option func(c *Cache)
+
+// WithTransport allows providing the underlying transport used for requests that are not served from the cache.
+// If not provided http.DefaultTransport is used.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Cache) {
+		c.transport = transport
+	}
+}
+
+// WithTTL sets how long a response is served from the cache without contacting the api.  Defaults to an hour.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithStaleWhileRevalidate allows serving a response for up to window past its ttl, while a fresh copy is fetched in
+// the background.
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(c *Cache) {
+		c.staleWhileRevalidate = window
+	}
+}
+
+// WithStaleIfError allows serving a response for up to window past its ttl when the api fails with a server error
+// (listennotes.ErrInternalServerError) or cannot be reached.
+func WithStaleIfError(window time.Duration) Option {
+	return func(c *Cache) {
+		c.staleIfError = window
+	}
+}
+
+// WithErrorFunc is called with the errors of writing cache entries, e.g. to log a full disk.  The response is served
+// either way, the errors are dropped if not provided.
+func WithErrorFunc(fn func(error)) Option {
+	return func(c *Cache) {
+		c.onError = fn
+	}
+}
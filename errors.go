@@ -13,6 +13,9 @@ var (
 	ErrInternalServerError = fmt.Errorf("something wrong on our end (unexpected server errors)")
 )
 
+// ErrNoAPIKeyAvailable is returned when every key of a KeyPool is out of rotation
+var ErrNoAPIKeyAvailable = fmt.Errorf("no api key available, every key in the pool is out of rotation")
+
 var errMap = map[int]error{
 	200: nil,
 	400: ErrBadRequest,
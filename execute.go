@@ -1,7 +1,9 @@
 package listennotes
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -32,6 +34,18 @@ func (r *Response) ToJSON() string {
 }
 
 func (c *standardHTTPClient) get(path string, args map[string]string) (*Response, error) {
+	if c.inflight != nil {
+		// a waiter gives up when its own request would have timed out, the shared call keeps going for the others
+		ctx := context.Background()
+		if c.httpClient.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.httpClient.Timeout)
+			defer cancel()
+		}
+		return c.inflight.do(ctx, requestKey("GET", path, args), func() (*Response, error) {
+			return c.exec("GET", path, args, url.Values{})
+		})
+	}
 	return c.exec("GET", path, args, url.Values{})
 }
 
@@ -48,6 +62,42 @@ func (c *standardHTTPClient) exec(
 	path string,
 	args map[string]string,
 	formFields url.Values,
+) (*Response, error) {
+	if c.credentials != nil {
+		apiKey, err := c.credentials.APIKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get api key for %s: %w", path, err)
+		}
+		resp, err := c.send(apiKey, method, path, args, formFields)
+		if errors.Is(err, ErrUnauthorized) {
+			c.credentials.invalidate()
+		}
+		return resp, err
+	}
+
+	if c.keyPool == nil {
+		return c.send(c.apiKey, method, path, args, formFields)
+	}
+
+	// a key that gets taken out of rotation by its response fails over to the next available key
+	for attempt := 0; ; attempt++ {
+		apiKey, err := c.keyPool.acquire()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.send(apiKey, method, path, args, formFields)
+		if err == nil || attempt >= c.keyPool.size()-1 || c.keyPool.available(apiKey) {
+			return resp, err
+		}
+	}
+}
+
+func (c *standardHTTPClient) send(
+	apiKey string,
+	method string,
+	path string,
+	args map[string]string,
+	formFields url.Values,
 ) (*Response, error) {
 	url := fmt.Sprintf("%s/%s", c.baseURL, path)
 
@@ -60,7 +110,7 @@ func (c *standardHTTPClient) exec(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request to %s: %w", path, err)
 	}
-	req.Header.Add(RequestHeaderKeyAPI, c.apiKey)
+	req.Header.Add(RequestHeaderKeyAPI, apiKey)
 
 	if len(formFields) > 0 {
 		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
@@ -78,6 +128,10 @@ func (c *standardHTTPClient) exec(
 	}
 	defer resp.Body.Close()
 
+	if c.keyPool != nil {
+		c.keyPool.report(apiKey, resp.StatusCode, parseStats(resp))
+	}
+
 	// map any generic status code errors
 	if mappedError, ok := errMap[resp.StatusCode]; ok && mappedError != nil {
 		return nil, mappedError
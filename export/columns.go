@@ -0,0 +1,168 @@
+package export
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindIntList
+	kindStringList
+	// kindListenScore is an int column, null when the plan has no access to the score
+	kindListenScore
+)
+
+var listenScoreType = reflect.TypeOf(listennotes.ListenScore{})
+
+// column is a flattened field of a typed model, e.g. "podcast.title" of an episode
+type column struct {
+	name string
+	kind kind
+	// path are the struct field indexes leading to the field, pointers are followed
+	path []int
+}
+
+var (
+	episodeColumns = flatten(reflect.TypeOf(listennotes.Episode{}), "", nil)
+	podcastColumns = flatten(reflect.TypeOf(listennotes.Podcast{}), "", nil)
+)
+
+// EpisodeColumns are the names of every column an episode flattens into, in the default order
+func EpisodeColumns() []string {
+	return columnNames(episodeColumns)
+}
+
+// PodcastColumns are the names of every column a podcast flattens into, in the default order
+func PodcastColumns() []string {
+	return columnNames(podcastColumns)
+}
+
+// flatten lists the columns of a struct by their json names.  Nested structs are prefixed with their field name,
+// lists of structs, e.g. the episodes of a podcast, are left out.
+func flatten(t reflect.Type, prefix string, path []int) []column {
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+		name = prefix + name
+		fieldPath := append(append([]int{}, path...), i)
+
+		typ := field.Type
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ == listenScoreType {
+			columns = append(columns, column{name, kindListenScore, fieldPath})
+			continue
+		}
+		switch typ.Kind() {
+		case reflect.String:
+			columns = append(columns, column{name, kindString, fieldPath})
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			columns = append(columns, column{name, kindInt, fieldPath})
+		case reflect.Float32, reflect.Float64:
+			columns = append(columns, column{name, kindFloat, fieldPath})
+		case reflect.Bool:
+			columns = append(columns, column{name, kindBool, fieldPath})
+		case reflect.Slice:
+			switch typ.Elem().Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				columns = append(columns, column{name, kindIntList, fieldPath})
+			case reflect.String:
+				columns = append(columns, column{name, kindStringList, fieldPath})
+			}
+		case reflect.Struct:
+			columns = append(columns, flatten(typ, name+".", fieldPath)...)
+		}
+	}
+	return columns
+}
+
+// value returns the column value of v, nil when a pointer on the way is nil.  Lists are []int64 or []string.
+func (c column) value(v reflect.Value) interface{} {
+	for _, i := range c.path {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch c.kind {
+	case kindString:
+		return v.String()
+	case kindInt:
+		return v.Int()
+	case kindListenScore:
+		if score, ok := v.Interface().(listennotes.ListenScore).Value(); ok {
+			return int64(score)
+		}
+		return nil
+	case kindFloat:
+		return v.Float()
+	case kindBool:
+		return v.Bool()
+	case kindIntList:
+		list := make([]int64, v.Len())
+		for i := range list {
+			list[i] = v.Index(i).Int()
+		}
+		return list
+	case kindStringList:
+		list := make([]string, v.Len())
+		for i := range list {
+			list[i] = v.Index(i).String()
+		}
+		return list
+	}
+	return nil
+}
+
+func columnNames(columns []column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// selectColumns picks the named columns in the given order, every column when names is empty
+func selectColumns(all []column, names []string) ([]column, error) {
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	byName := map[string]column{}
+	for _, c := range all {
+		byName[c.name] = c
+	}
+	selected := make([]column, 0, len(names))
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
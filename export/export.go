@@ -0,0 +1,242 @@
+// Package export writes typed podcasts and episodes to flat files: CSV, JSON Lines and Parquet.  Episodes can be
+// written as M3U8 and XSPF playlists as well, for desktop players.
+//
+// Rows are read from an iterator and written one at a time, so memory stays bounded however long the result set
+// is.  Nested fields are flattened into columns such as "podcast.title", lists such as "genre_ids" become a single
+// column:
+//
+//	exporter := export.NewExporter(export.CSV, export.WithColumns("id", "title", "podcast.title", "pub_date_ms"))
+//	it := export.NewSearchIterator(client, map[string]string{"q": "star wars", "type": "episode"})
+//	n, err := exporter.WriteEpisodes(os.Stdout, it)
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Format is an output file format
+type Format string
+
+// Output formats
+const (
+	CSV       Format = "csv"
+	JSONLines Format = "jsonl"
+	Parquet   Format = "parquet"
+)
+
+// EpisodeSource is an iterator of episodes, e.g. a listennotes.PodcastEpisodeIterator
+type EpisodeSource interface {
+	Next() bool
+	Episode() listennotes.Episode
+	Err() error
+}
+
+// PodcastSource is an iterator of podcasts, e.g. a PodcastPager
+type PodcastSource interface {
+	Next() bool
+	Podcast() listennotes.Podcast
+	Err() error
+}
+
+// Exporter writes iterators to files of a format
+type Exporter struct {
+	format       Format
+	columns      []string
+	separator    string
+	rowGroupSize int
+	// title and skipInvalidAudio are only used by playlist formats
+	title            string
+	skipInvalidAudio bool
+}
+
+// NewExporter will create an exporter for the format with reasonable defaults.
+// You can optionally override some configuration.
+func NewExporter(format Format, opts ...Option) *Exporter {
+	e := &Exporter{
+		format:       format,
+		separator:    ";",
+		rowGroupSize: 1000,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// WriteEpisodes writes every episode of the source to w and returns the number of rows written.  When the source
+// fails the rows read before are still written as a complete file, along with the error.
+func (e *Exporter) WriteEpisodes(w io.Writer, src EpisodeSource) (int, error) {
+	if e.format.playlist() {
+		return e.writePlaylist(w, src)
+	}
+	return e.write(w, episodeColumns, func() (interface{}, bool) {
+		if !src.Next() {
+			return nil, false
+		}
+		episode := src.Episode()
+		return &episode, true
+	}, src.Err)
+}
+
+// WritePodcasts writes every podcast of the source to w and returns the number of rows written.  When the source
+// fails the rows read before are still written as a complete file, along with the error.
+func (e *Exporter) WritePodcasts(w io.Writer, src PodcastSource) (int, error) {
+	if e.format.playlist() {
+		return 0, fmt.Errorf("%s playlists only hold episodes", e.format)
+	}
+	return e.write(w, podcastColumns, func() (interface{}, bool) {
+		if !src.Next() {
+			return nil, false
+		}
+		podcast := src.Podcast()
+		return &podcast, true
+	}, src.Err)
+}
+
+func (e *Exporter) write(w io.Writer, all []column, next func() (interface{}, bool), srcErr func() error) (int, error) {
+	columns, err := selectColumns(all, e.columns)
+	if err != nil {
+		return 0, err
+	}
+
+	enc, err := e.newEncoder(w, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	row := make([]interface{}, len(columns))
+	for {
+		item, ok := next()
+		if !ok {
+			break
+		}
+		v := reflect.ValueOf(item)
+		for i, c := range columns {
+			row[i] = c.value(v)
+		}
+		if err := enc.write(row); err != nil {
+			return rows, fmt.Errorf("failed to write row %d: %w", rows+1, err)
+		}
+		rows++
+	}
+	if err := srcErr(); err != nil {
+		// finish the file so that the rows written so far are flushed and readable
+		enc.close()
+		return rows, fmt.Errorf("failed to read the %s export source after %d rows: %w", e.format, rows, err)
+	}
+	if err := enc.close(); err != nil {
+		return rows, fmt.Errorf("failed to finish %s export: %w", e.format, err)
+	}
+	return rows, nil
+}
+
+// encoder writes rows of values, in column order, to a file format
+type encoder interface {
+	write(row []interface{}) error
+	close() error
+}
+
+func (e *Exporter) newEncoder(w io.Writer, columns []column) (encoder, error) {
+	switch e.format {
+	case CSV:
+		return newCSVEncoder(w, columns, e.separator)
+	case JSONLines:
+		return &jsonlEncoder{w: w, columns: columns}, nil
+	case Parquet:
+		return newParquetEncoder(w, columns, e.rowGroupSize)
+	}
+	return nil, fmt.Errorf("unknown export format %q", e.format)
+}
+
+type csvEncoder struct {
+	w         *csv.Writer
+	separator string
+	record    []string
+}
+
+func newCSVEncoder(w io.Writer, columns []column, separator string) (*csvEncoder, error) {
+	enc := &csvEncoder{w: csv.NewWriter(w), separator: separator, record: make([]string, len(columns))}
+	if err := enc.w.Write(columnNames(columns)); err != nil {
+		return nil, err
+	}
+	return enc, nil
+}
+
+func (enc *csvEncoder) write(row []interface{}) error {
+	for i, value := range row {
+		enc.record[i] = formatValue(value, enc.separator)
+	}
+	return enc.w.Write(enc.record)
+}
+
+func (enc *csvEncoder) close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+func formatValue(value interface{}, separator string) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []int64:
+		parts := make([]string, len(v))
+		for i, n := range v {
+			parts[i] = strconv.FormatInt(n, 10)
+		}
+		return strings.Join(parts, separator)
+	case []string:
+		return strings.Join(v, separator)
+	}
+	return ""
+}
+
+// jsonlEncoder writes a flat object per line, keys in column order
+type jsonlEncoder struct {
+	w       io.Writer
+	columns []column
+	line    []byte
+}
+
+func (enc *jsonlEncoder) write(row []interface{}) error {
+	enc.line = append(enc.line[:0], '{')
+	for i, value := range row {
+		if i > 0 {
+			enc.line = append(enc.line, ',')
+		}
+		key, err := json.Marshal(enc.columns[i].name)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		enc.line = append(enc.line, key...)
+		enc.line = append(enc.line, ':')
+		enc.line = append(enc.line, data...)
+	}
+	enc.line = append(enc.line, '}', '\n')
+	_, err := enc.w.Write(enc.line)
+	return err
+}
+
+func (enc *jsonlEncoder) close() error {
+	return nil
+}
@@ -0,0 +1,356 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type sliceEpisodes struct {
+	episodes []listennotes.Episode
+	index    int
+	err      error
+}
+
+func (s *sliceEpisodes) Next() bool {
+	s.index++
+	return s.index <= len(s.episodes)
+}
+
+func (s *sliceEpisodes) Episode() listennotes.Episode {
+	return s.episodes[s.index-1]
+}
+
+func (s *sliceEpisodes) Err() error {
+	return s.err
+}
+
+func testEpisodes() *sliceEpisodes {
+	return &sliceEpisodes{episodes: []listennotes.Episode{
+		{
+			ID:              "e1",
+			Title:           "First, \"quoted\"",
+			AudioLengthSec:  600,
+			ExplicitContent: true,
+			Podcast:         &listennotes.Podcast{ID: "p1", Title: "A podcast", GenreIDs: []int{68, 82}},
+		},
+		{ID: "e2", Title: "Second"},
+	}}
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := NewExporter(CSV, WithColumns("id", "title", "podcast.title", "podcast.genre_ids", "explicit_content"))
+	n, err := exporter.WriteEpisodes(&buf, testEpisodes())
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 rows but got %d: %v", n, err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV but got: %s", err)
+	}
+	expected := [][]string{
+		{"id", "title", "podcast.title", "podcast.genre_ids", "explicit_content"},
+		{"e1", "First, \"quoted\"", "A podcast", "68;82", "true"},
+		{"e2", "Second", "", "", "false"},
+	}
+	if fmt.Sprint(records) != fmt.Sprint(expected) {
+		t.Errorf("CSV was not as expected: %v", records)
+	}
+
+	if _, err := NewExporter(CSV, WithColumns("nope")).WriteEpisodes(&buf, testEpisodes()); err == nil {
+		t.Errorf("Expected an error for an unknown column")
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := NewExporter(JSONLines, WithColumns("id", "audio_length_sec", "podcast.id", "podcast.genre_ids", "podcast.listen_score"))
+	if _, err := exporter.WriteEpisodes(&buf, testEpisodes()); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines but got: %s", buf.String())
+	}
+	if lines[0] != `{"id":"e1","audio_length_sec":600,"podcast.id":"p1","podcast.genre_ids":[68,82],"podcast.listen_score":null}` {
+		t.Errorf("Line was not as expected: %s", lines[0])
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil || row["podcast.id"] != nil {
+		t.Errorf("Fields of a missing podcast should be null: %s", lines[1])
+	}
+}
+
+func TestParquet(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := NewExporter(Parquet, WithRowGroupSize(1), WithColumns("id", "audio_length_sec", "podcast.genre_ids"))
+	if _, err := exporter.WriteEpisodes(&buf, testEpisodes()); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	data := buf.Bytes()
+	if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("Parquet magic bytes are missing")
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := readThriftStruct(bufio.NewReader(bytes.NewReader(data[len(data)-8-size : len(data)-8])))
+
+	if meta[3] != int64(2) {
+		t.Errorf("Expected 2 rows but got %v", meta[3])
+	}
+	schema := meta[2].([]interface{})
+	var names []string
+	for _, element := range schema[1:] {
+		names = append(names, element.(map[int16]interface{})[4].(string))
+	}
+	if strings.Join(names, ",") != "id,audio_length_sec,podcast.genre_ids" {
+		t.Errorf("Schema was not as expected: %v", names)
+	}
+
+	// the first row group has a single row, the genre ids of its third column are repeated values
+	groups := meta[4].([]interface{})
+	if len(groups) != 2 {
+		t.Fatalf("Expected a row group per row but got %d", len(groups))
+	}
+	chunk := groups[0].(map[int16]interface{})[1].([]interface{})[2].(map[int16]interface{})
+	offset := chunk[3].(map[int16]interface{})[9].(int64)
+	r := bufio.NewReader(bytes.NewReader(data[offset:]))
+	header := readThriftStruct(r)
+	if header[5].(map[int16]interface{})[1] != int32(2) {
+		t.Errorf("Expected 2 values in the genre ids page: %v", header)
+	}
+	page := make([]byte, header[3].(int32))
+	r.Read(page)
+	values := page[len(page)-16:]
+	if binary.LittleEndian.Uint64(values) != 68 || binary.LittleEndian.Uint64(values[8:]) != 82 {
+		t.Errorf("Genre ids were not as expected: %v", values)
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func goldenEpisodes() *sliceEpisodes {
+	return &sliceEpisodes{episodes: []listennotes.Episode{
+		{
+			ID:              "e1",
+			Title:           "First, \"quoted\"",
+			AudioLengthSec:  600,
+			PubDateMs:       1579507216184,
+			ExplicitContent: true,
+			Podcast: &listennotes.Podcast{
+				ID:          "p1",
+				Title:       "A podcast",
+				GenreIDs:    []int{68, 82},
+				ListenScore: listennotes.NewListenScore(55),
+			},
+		},
+		{ID: "e2", Title: "No podcast"},
+		{
+			ID:             "e3",
+			Title:          "Ünïcode ✓",
+			AudioLengthSec: 1200,
+			Podcast:        &listennotes.Podcast{ID: "p2", Title: "FREE plan", Language: "English"},
+		},
+	}}
+}
+
+// TestParquetGolden compares the Parquet writer with testdata/episodes.parquet.  The golden file is read back with
+// parquet-go, an independent implementation, by the module in testdata/parquetcheck, which checks every row against
+// the JSON Lines export of the same episodes.  After changing the writer:
+//
+//	go test ./export -run TestParquetGolden -update
+//	cd export/testdata/parquetcheck && go run . ../episodes.parquet ../episodes.jsonl
+func TestParquetGolden(t *testing.T) {
+	golden := map[Format]string{Parquet: "testdata/episodes.parquet", JSONLines: "testdata/episodes.jsonl"}
+	for format, path := range golden {
+		var buf bytes.Buffer
+		if _, err := NewExporter(format, WithRowGroupSize(2)).WriteEpisodes(&buf, goldenEpisodes()); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+
+		if *update {
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				t.Fatalf("Failed to update %s: %s", path, err)
+			}
+			continue
+		}
+		expected, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %s", path, err)
+		}
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("%s output differs from %s, check it with testdata/parquetcheck before updating", format, path)
+		}
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	for _, format := range []Format{CSV, Parquet} {
+		src := testEpisodes()
+		src.err = listennotes.ErrTooManyRequests
+
+		var buf bytes.Buffer
+		n, err := NewExporter(format, WithColumns("id")).WriteEpisodes(&buf, src)
+		if !errors.Is(err, listennotes.ErrTooManyRequests) || n != 2 {
+			t.Errorf("Expected the source error after 2 rows but got %d: %v", n, err)
+		}
+		if strings.Contains(err.Error(), "row 3") {
+			t.Errorf("The error should not name a row that was never read: %s", err)
+		}
+
+		data := buf.String()
+		switch format {
+		case CSV:
+			if data != "id\ne1\ne2\n" {
+				t.Errorf("Expected the rows read so far to be flushed: %q", data)
+			}
+		case Parquet:
+			if !strings.HasPrefix(data, "PAR1") || !strings.HasSuffix(data, "PAR1") || len(data) <= 8 {
+				t.Errorf("Expected a complete Parquet file with its footer")
+			}
+		}
+	}
+}
+
+// readThriftStruct decodes a Thrift compact struct into its fields by id, enough to check the Parquet metadata
+func readThriftStruct(r *bufio.Reader) map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		b, _ := r.ReadByte()
+		if b == 0 {
+			return fields
+		}
+		typ := b & 0x0f
+		if delta := int16(b >> 4); delta != 0 {
+			last += delta
+		} else {
+			v, _ := binary.ReadUvarint(r)
+			last = int16(v>>1) ^ -int16(v&1)
+		}
+		fields[last] = readThriftValue(r, typ)
+	}
+}
+
+func readThriftValue(r *bufio.Reader, typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 5:
+		v, _ := binary.ReadVarint(r)
+		return int32(v)
+	case 6:
+		v, _ := binary.ReadVarint(r)
+		return v
+	case 8:
+		n, _ := binary.ReadUvarint(r)
+		s := make([]byte, n)
+		r.Read(s)
+		return string(s)
+	case 9:
+		b, _ := r.ReadByte()
+		size := uint64(b >> 4)
+		if size == 15 {
+			size, _ = binary.ReadUvarint(r)
+		}
+		var list []interface{}
+		for i := uint64(0); i < size; i++ {
+			list = append(list, readThriftValue(r, b&0x0f))
+		}
+		return list
+	case 12:
+		return readThriftStruct(r)
+	}
+	panic("unsupported thrift type " + strconv.Itoa(int(typ)))
+}
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	results []map[string]interface{}
+	offsets []string
+}
+
+func (c *fakeClient) Search(args map[string]string) (*listennotes.Response, error) {
+	c.offsets = append(c.offsets, args["offset"])
+	offset, _ := strconv.Atoi(args["offset"])
+	end := offset + 2
+	if end > len(c.results) {
+		end = len(c.results)
+	}
+	var results []interface{}
+	for _, r := range c.results[offset:end] {
+		results = append(results, r)
+	}
+	return &listennotes.Response{Data: map[string]interface{}{
+		"results":     results,
+		"next_offset": float64(end),
+		"total":       float64(len(c.results)),
+	}}, nil
+}
+
+func TestSearchIterator(t *testing.T) {
+	client := &fakeClient{}
+	for i := 1; i <= 5; i++ {
+		client.results = append(client.results, map[string]interface{}{
+			"id":             fmt.Sprintf("e%d", i),
+			"title_original": fmt.Sprintf("Episode %d", i),
+			"podcast":        map[string]interface{}{"id": "p1", "title_original": "A podcast"},
+		})
+	}
+
+	var buf bytes.Buffer
+	it := NewSearchIterator(client, map[string]string{"q": "star wars", "type": "episode"})
+	n, err := NewExporter(CSV, WithColumns("id", "title", "podcast.title")).WriteEpisodes(&buf, it)
+	if err != nil || n != 5 {
+		t.Fatalf("Expected 5 rows but got %d: %v", n, err)
+	}
+	if strings.Join(client.offsets, ",") != "0,2,4" {
+		t.Errorf("Offsets were not as expected: %v", client.offsets)
+	}
+	if !strings.Contains(buf.String(), "e5,Episode 5,A podcast") {
+		t.Errorf("Search results were not mapped: %s", buf.String())
+	}
+}
+
+func TestPodcastPager(t *testing.T) {
+	var pages []int
+	pager := NewPodcastPager(func(page int) (*listennotes.Response, error) {
+		pages = append(pages, page)
+		return &listennotes.Response{Data: map[string]interface{}{
+			"has_next": page < 2,
+			"podcasts": []interface{}{map[string]interface{}{"id": fmt.Sprintf("p%d", page), "genre_ids": []interface{}{float64(page)}}},
+		}}, nil
+	}, "podcasts")
+
+	var buf bytes.Buffer
+	n, err := NewExporter(CSV, WithColumns("id", "genre_ids")).WritePodcasts(&buf, pager)
+	if err != nil || n != 2 || len(pages) != 2 {
+		t.Fatalf("Expected 2 rows from 2 pages but got %d from %v: %v", n, pages, err)
+	}
+	if buf.String() != "id,genre_ids\np1,1\np2,2\n" {
+		t.Errorf("CSV was not as expected: %s", buf.String())
+	}
+}
+
+func TestColumns(t *testing.T) {
+	columns := strings.Join(EpisodeColumns(), ",")
+	if !strings.Contains(columns, "podcast.title") || strings.Contains(columns, "podcast.episodes") {
+		t.Errorf("Episode columns were not as expected: %s", columns)
+	}
+}
@@ -0,0 +1,41 @@
+package export
+
+// Option allows for options to be passed to the exporter constructor function
+type Option func(e *Exporter)
+
+// WithColumns selects the columns to write and their order, by their flattened names, e.g. "podcast.title".  Every
+// column is written by default, see EpisodeColumns and PodcastColumns.
+func WithColumns(names ...string) Option {
+	return func(e *Exporter) {
+		e.columns = names
+	}
+}
+
+// WithListSeparator sets how CSV joins the values of list columns such as "genre_ids".  The default is ";".
+func WithListSeparator(separator string) Option {
+	return func(e *Exporter) {
+		e.separator = separator
+	}
+}
+
+// WithRowGroupSize sets how many rows Parquet buffers per row group, which bounds the memory used.  The default is
+// 1000.
+func WithRowGroupSize(rows int) Option {
+	return func(e *Exporter) {
+		e.rowGroupSize = rows
+	}
+}
+
+// WithPlaylistTitle sets the title of M3U8 and XSPF playlists
+func WithPlaylistTitle(title string) Option {
+	return func(e *Exporter) {
+		e.title = title
+	}
+}
+
+// WithoutInvalidAudio leaves episodes flagged with maybe_audio_invalid out of M3U8 and XSPF playlists
+func WithoutInvalidAudio() Option {
+	return func(e *Exporter) {
+		e.skipInvalidAudio = true
+	}
+}
@@ -0,0 +1,315 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Parquet physical types, repetitions and encodings used by the writer
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetOptional = 1
+	parquetRepeated = 2
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetUTF8 = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// parquetEncoder writes an uncompressed Parquet file with a row group every rowGroupSize rows.  Scalar columns are
+// optional, so that the fields of a missing nested podcast are null, lists are repeated columns.
+type parquetEncoder struct {
+	w            *countingWriter
+	columns      []column
+	buffers      []*columnBuffer
+	rowGroupSize int
+	rows         int
+	totalRows    int64
+	rowGroups    []rowGroup
+}
+
+type columnBuffer struct {
+	defs   []byte
+	reps   []byte
+	values bytes.Buffer
+	bools  []bool
+}
+
+type rowGroup struct {
+	rows   int64
+	size   int64
+	chunks []columnChunk
+}
+
+type columnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newParquetEncoder(w io.Writer, columns []column, rowGroupSize int) (*parquetEncoder, error) {
+	if rowGroupSize <= 0 {
+		rowGroupSize = 1000
+	}
+	enc := &parquetEncoder{
+		w:            &countingWriter{w: w},
+		columns:      columns,
+		buffers:      make([]*columnBuffer, len(columns)),
+		rowGroupSize: rowGroupSize,
+	}
+	for i := range enc.buffers {
+		enc.buffers[i] = &columnBuffer{}
+	}
+	if _, err := enc.w.Write(parquetMagic); err != nil {
+		return nil, err
+	}
+	return enc, nil
+}
+
+func (enc *parquetEncoder) write(row []interface{}) error {
+	for i, value := range row {
+		enc.buffers[i].add(enc.columns[i], value)
+	}
+	enc.rows++
+	if enc.rows >= enc.rowGroupSize {
+		return enc.flush()
+	}
+	return nil
+}
+
+func (b *columnBuffer) add(c column, value interface{}) {
+	repeated := c.kind == kindIntList || c.kind == kindStringList
+	if value == nil {
+		b.defs = append(b.defs, 0)
+		if repeated {
+			b.reps = append(b.reps, 0)
+		}
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		b.defs = append(b.defs, 1)
+		b.addString(v)
+	case int64:
+		b.defs = append(b.defs, 1)
+		binary.Write(&b.values, binary.LittleEndian, v)
+	case float64:
+		b.defs = append(b.defs, 1)
+		binary.Write(&b.values, binary.LittleEndian, math.Float64bits(v))
+	case bool:
+		b.defs = append(b.defs, 1)
+		b.bools = append(b.bools, v)
+	case []int64:
+		if len(v) == 0 {
+			b.defs, b.reps = append(b.defs, 0), append(b.reps, 0)
+		}
+		for i, n := range v {
+			b.defs, b.reps = append(b.defs, 1), append(b.reps, repetition(i))
+			binary.Write(&b.values, binary.LittleEndian, n)
+		}
+	case []string:
+		if len(v) == 0 {
+			b.defs, b.reps = append(b.defs, 0), append(b.reps, 0)
+		}
+		for i, s := range v {
+			b.defs, b.reps = append(b.defs, 1), append(b.reps, repetition(i))
+			b.addString(s)
+		}
+	}
+}
+
+func (b *columnBuffer) addString(s string) {
+	binary.Write(&b.values, binary.LittleEndian, uint32(len(s)))
+	b.values.WriteString(s)
+}
+
+// repetition is the repetition level of the i-th element of a list, 0 starts a new row
+func repetition(i int) byte {
+	if i == 0 {
+		return 0
+	}
+	return 1
+}
+
+// flush writes the buffered rows as a row group with a single data page per column
+func (enc *parquetEncoder) flush() error {
+	if enc.rows == 0 {
+		return nil
+	}
+
+	group := rowGroup{rows: int64(enc.rows)}
+	for i, b := range enc.buffers {
+		var page bytes.Buffer
+		if enc.columns[i].kind == kindIntList || enc.columns[i].kind == kindStringList {
+			writeLevels(&page, b.reps)
+		}
+		writeLevels(&page, b.defs)
+		if len(b.bools) > 0 {
+			page.Write(packBools(b.bools))
+		}
+		page.Write(b.values.Bytes())
+
+		header := &thriftWriter{}
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(page.Len()))
+		header.structField(5)
+		header.i32(1, int32(len(b.defs)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.end()
+		header.buf.WriteByte(0)
+
+		chunk := columnChunk{offset: enc.w.n, numValues: int64(len(b.defs))}
+		if _, err := enc.w.Write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if _, err := enc.w.Write(page.Bytes()); err != nil {
+			return err
+		}
+		chunk.size = enc.w.n - chunk.offset
+		group.size += chunk.size
+		group.chunks = append(group.chunks, chunk)
+
+		enc.buffers[i] = &columnBuffer{}
+	}
+
+	enc.rowGroups = append(enc.rowGroups, group)
+	enc.totalRows += group.rows
+	enc.rows = 0
+	return nil
+}
+
+// writeLevels writes levels of bit width 1 in the RLE hybrid encoding, prefixed by their length
+func writeLevels(page *bytes.Buffer, levels []byte) {
+	var runs bytes.Buffer
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		run := uint64(j-i) << 1
+		for run >= 0x80 {
+			runs.WriteByte(byte(run) | 0x80)
+			run >>= 7
+		}
+		runs.WriteByte(byte(run))
+		runs.WriteByte(levels[i])
+		i = j
+	}
+	binary.Write(page, binary.LittleEndian, uint32(runs.Len()))
+	page.Write(runs.Bytes())
+}
+
+// packBools bit packs booleans, least significant bit first
+func packBools(bools []bool) []byte {
+	packed := make([]byte, (len(bools)+7)/8)
+	for i, v := range bools {
+		if v {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return packed
+}
+
+func (enc *parquetEncoder) close() error {
+	if err := enc.flush(); err != nil {
+		return err
+	}
+
+	meta := &thriftWriter{}
+	meta.i32(1, 1)
+	meta.list(2, thriftStruct, len(enc.columns)+1)
+	meta.begin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(enc.columns)))
+	meta.end()
+	for _, c := range enc.columns {
+		typ, repetition := parquetType(c)
+		meta.begin()
+		meta.i32(1, typ)
+		meta.i32(3, repetition)
+		meta.binary(4, c.name)
+		if c.kind == kindString || c.kind == kindStringList {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.end()
+	}
+	meta.i64(3, enc.totalRows)
+	meta.list(4, thriftStruct, len(enc.rowGroups))
+	for _, group := range enc.rowGroups {
+		meta.begin()
+		meta.list(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			typ, _ := parquetType(enc.columns[i])
+			meta.begin()
+			meta.i64(2, chunk.offset)
+			meta.structField(3)
+			meta.i32(1, typ)
+			meta.list(2, thriftI32, 2)
+			meta.varint32(parquetPlain)
+			meta.varint32(parquetRLE)
+			meta.list(3, thriftBinary, 1)
+			meta.string(enc.columns[i].name)
+			meta.i32(4, 0) // UNCOMPRESSED
+			meta.i64(5, chunk.numValues)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.end()
+			meta.end()
+		}
+		meta.i64(2, group.size)
+		meta.i64(3, group.rows)
+		meta.end()
+	}
+	meta.binary(6, "github.com/ListenNotes/podcast-api-go")
+	meta.buf.WriteByte(0)
+
+	if _, err := enc.w.Write(meta.buf.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(enc.w, binary.LittleEndian, uint32(meta.buf.Len())); err != nil {
+		return err
+	}
+	_, err := enc.w.Write(parquetMagic)
+	return err
+}
+
+func parquetType(c column) (typ int32, repetition int32) {
+	switch c.kind {
+	case kindInt, kindListenScore:
+		return parquetInt64, parquetOptional
+	case kindFloat:
+		return parquetDouble, parquetOptional
+	case kindBool:
+		return parquetBoolean, parquetOptional
+	case kindIntList:
+		return parquetInt64, parquetRepeated
+	case kindStringList:
+		return parquetByteArray, parquetRepeated
+	}
+	return parquetByteArray, parquetOptional
+}
@@ -0,0 +1,182 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Playlist formats, for episodes only.  Episodes without an audio url are left out of playlists.
+const (
+	M3U8 Format = "m3u8"
+	XSPF Format = "xspf"
+)
+
+func (f Format) playlist() bool {
+	return f == M3U8 || f == XSPF
+}
+
+// track is what a playlist entry needs of an episode
+type track struct {
+	audio    string
+	title    string
+	podcast  string
+	image    string
+	info     string
+	duration int
+}
+
+func newTrack(e listennotes.Episode) track {
+	t := track{
+		audio:    e.Audio,
+		title:    e.Title,
+		image:    e.Image,
+		info:     e.ListennotesURL,
+		duration: e.AudioLengthSec,
+	}
+	if e.Podcast != nil {
+		t.podcast = e.Podcast.Title
+		if t.image == "" {
+			t.image = e.Podcast.Image
+		}
+	}
+	return t
+}
+
+// playlistEncoder writes the tracks of a playlist format
+type playlistEncoder interface {
+	write(t track) error
+	close() error
+}
+
+func (e *Exporter) writePlaylist(w io.Writer, src EpisodeSource) (int, error) {
+	var enc playlistEncoder
+	switch e.format {
+	case M3U8:
+		enc = newM3U8Encoder(w, e.title)
+	case XSPF:
+		enc = newXSPFEncoder(w, e.title)
+	}
+
+	rows := 0
+	for src.Next() {
+		episode := src.Episode()
+		if episode.Audio == "" || (e.skipInvalidAudio && episode.MaybeAudioInvalid) {
+			continue
+		}
+		if err := enc.write(newTrack(episode)); err != nil {
+			return rows, fmt.Errorf("failed to write entry %d: %w", rows+1, err)
+		}
+		rows++
+	}
+	if err := src.Err(); err != nil {
+		return rows, fmt.Errorf("failed to read entry %d: %w", rows+1, err)
+	}
+	if err := enc.close(); err != nil {
+		return rows, fmt.Errorf("failed to finish %s export: %w", e.format, err)
+	}
+	return rows, nil
+}
+
+// m3u8Encoder writes an extended M3U playlist in UTF-8
+type m3u8Encoder struct {
+	w *bufio.Writer
+}
+
+func newM3U8Encoder(w io.Writer, title string) *m3u8Encoder {
+	enc := &m3u8Encoder{w: bufio.NewWriter(w)}
+	enc.w.WriteString("#EXTM3U\n")
+	if title != "" {
+		enc.w.WriteString("#PLAYLIST:" + oneLine(title) + "\n")
+	}
+	return enc
+}
+
+func (enc *m3u8Encoder) write(t track) error {
+	duration := -1
+	if t.duration > 0 {
+		duration = t.duration
+	}
+	name := oneLine(t.title)
+	if t.podcast != "" {
+		name = oneLine(t.podcast) + " - " + name
+	}
+
+	enc.w.WriteString("#EXTINF:" + strconv.Itoa(duration) + "," + name + "\n")
+	if t.podcast != "" {
+		enc.w.WriteString("#EXTALB:" + oneLine(t.podcast) + "\n")
+	}
+	if t.image != "" {
+		enc.w.WriteString("#EXTIMG:" + oneLine(t.image) + "\n")
+	}
+	_, err := enc.w.WriteString(oneLine(t.audio) + "\n")
+	return err
+}
+
+func (enc *m3u8Encoder) close() error {
+	return enc.w.Flush()
+}
+
+// oneLine keeps a value from breaking the line based M3U format
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// xspfTrack is a track element of XSPF, https://xspf.org/spec
+type xspfTrack struct {
+	XMLName  xml.Name `xml:"track"`
+	Location string   `xml:"location"`
+	Title    string   `xml:"title,omitempty"`
+	Creator  string   `xml:"creator,omitempty"`
+	Info     string   `xml:"info,omitempty"`
+	Image    string   `xml:"image,omitempty"`
+	// Duration is in milliseconds
+	Duration int `xml:"duration,omitempty"`
+}
+
+// xspfEncoder writes the tracks one at a time, between the opening and closing tags of the playlist
+type xspfEncoder struct {
+	w   *bufio.Writer
+	enc *xml.Encoder
+}
+
+func newXSPFEncoder(w io.Writer, title string) *xspfEncoder {
+	enc := &xspfEncoder{w: bufio.NewWriter(w)}
+	enc.enc = xml.NewEncoder(enc.w)
+	enc.w.WriteString(xml.Header)
+	enc.w.WriteString(`<playlist version="1" xmlns="http://xspf.org/ns/0/">` + "\n")
+	if title != "" {
+		enc.w.WriteString("  <title>")
+		xml.EscapeText(enc.w, []byte(title))
+		enc.w.WriteString("</title>\n")
+	}
+	enc.w.WriteString("  <trackList>\n")
+	return enc
+}
+
+func (enc *xspfEncoder) write(t track) error {
+	enc.w.WriteString("    ")
+	err := enc.enc.Encode(xspfTrack{
+		Location: t.audio,
+		Title:    t.title,
+		Creator:  t.podcast,
+		Info:     t.info,
+		Image:    t.image,
+		Duration: t.duration * 1000,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = enc.w.WriteString("\n")
+	return err
+}
+
+func (enc *xspfEncoder) close() error {
+	enc.w.WriteString("  </trackList>\n</playlist>\n")
+	return enc.w.Flush()
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+func playlistEpisodes() *sliceEpisodes {
+	podcast := &listennotes.Podcast{Title: "A podcast", Image: "https://example.com/p.jpg"}
+	return &sliceEpisodes{episodes: []listennotes.Episode{
+		{ID: "e1", Title: "First\nepisode", Audio: "https://example.com/1.mp3", AudioLengthSec: 600, Podcast: podcast},
+		{ID: "e2", Title: "Broken", Audio: "https://example.com/2.mp3", MaybeAudioInvalid: true, Podcast: podcast},
+		{ID: "e3", Title: "No audio"},
+		{ID: "e4", Title: "Unknown length", Audio: "https://example.com/4.mp3", Image: "https://example.com/4.jpg"},
+	}}
+}
+
+func TestM3U8(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := NewExporter(M3U8, WithPlaylistTitle("Weekend"), WithoutInvalidAudio()).WriteEpisodes(&buf, playlistEpisodes())
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 entries but got %d: %v", n, err)
+	}
+
+	expected := "#EXTM3U\n#PLAYLIST:Weekend\n" +
+		"#EXTINF:600,A podcast - First episode\n#EXTALB:A podcast\n#EXTIMG:https://example.com/p.jpg\nhttps://example.com/1.mp3\n" +
+		"#EXTINF:-1,Unknown length\n#EXTIMG:https://example.com/4.jpg\nhttps://example.com/4.mp3\n"
+	if buf.String() != expected {
+		t.Errorf("Playlist was not as expected:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if n, _ := NewExporter(M3U8).WriteEpisodes(&buf, playlistEpisodes()); n != 3 {
+		t.Errorf("Episodes with invalid audio should be kept by default: %d", n)
+	}
+}
+
+func TestXSPF(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewExporter(XSPF, WithPlaylistTitle("Rock & roll")).WriteEpisodes(&buf, playlistEpisodes()); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	var playlist struct {
+		Title  string      `xml:"title"`
+		Tracks []xspfTrack `xml:"trackList>track"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &playlist); err != nil {
+		t.Fatalf("Expected valid XML but got: %s\n%s", err, buf.String())
+	}
+	if playlist.Title != "Rock & roll" || len(playlist.Tracks) != 3 {
+		t.Fatalf("Playlist was not as expected: %+v", playlist)
+	}
+	first := playlist.Tracks[0]
+	if first.Location != "https://example.com/1.mp3" || first.Creator != "A podcast" || first.Duration != 600000 ||
+		first.Image != "https://example.com/p.jpg" {
+		t.Errorf("Track was not as expected: %+v", first)
+	}
+	if !strings.Contains(buf.String(), `xmlns="http://xspf.org/ns/0/"`) {
+		t.Errorf("Playlist namespace is missing")
+	}
+}
+
+type batchClient struct {
+	listennotes.HTTPClient
+	batches []string
+}
+
+func (c *batchClient) BatchFetchEpisodes(args map[string]string) (*listennotes.Response, error) {
+	c.batches = append(c.batches, args["ids"])
+	var episodes []interface{}
+	for _, id := range strings.Split(args["ids"], ",") {
+		episodes = append(episodes, map[string]interface{}{"id": id, "audio": "https://example.com/" + id + ".mp3"})
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"episodes": episodes}}, nil
+}
+
+func TestBatchEpisodeIterator(t *testing.T) {
+	var ids []string
+	for i := 0; i < 12; i++ {
+		ids = append(ids, string(rune('a'+i)))
+	}
+	client := &batchClient{}
+
+	var buf bytes.Buffer
+	n, err := NewExporter(M3U8).WriteEpisodes(&buf, NewBatchEpisodeIterator(client, ids))
+	if err != nil || n != 12 {
+		t.Fatalf("Expected 12 entries but got %d: %v", n, err)
+	}
+	if len(client.batches) != 2 || client.batches[1] != "k,l" {
+		t.Errorf("Batches were not as expected: %v", client.batches)
+	}
+}
+
+func TestPlaylistPodcasts(t *testing.T) {
+	if _, err := NewExporter(XSPF).WritePodcasts(&bytes.Buffer{}, NewPodcastPager(nil, "podcasts")); err == nil {
+		t.Errorf("Expected an error exporting podcasts to a playlist")
+	}
+}
@@ -0,0 +1,322 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/searchpage"
+)
+
+// PageFunc fetches a page of a paginated endpoint, pages start at 1
+type PageFunc func(page int) (*listennotes.Response, error)
+
+// PodcastPager is a PodcastSource over an endpoint paginated with page and has_next, e.g. FetchBestPodcasts or
+// FetchPodcastsByDomain.  It only holds the current page in memory.
+type PodcastPager struct {
+	fetch PageFunc
+	field string
+
+	page    []listennotes.Podcast
+	index   int
+	number  int
+	hasNext bool
+	err     error
+}
+
+// NewPodcastPager will create an iterator over the podcasts in field of every page, e.g. "podcasts":
+//
+//	pager := export.NewPodcastPager(func(page int) (*listennotes.Response, error) {
+//		return client.FetchBestPodcasts(map[string]string{"genre_id": "93", "page": strconv.Itoa(page)})
+//	}, "podcasts")
+func NewPodcastPager(fetch PageFunc, field string) *PodcastPager {
+	return &PodcastPager{fetch: fetch, field: field, index: -1, hasNext: true}
+}
+
+// Next advances to the next podcast, fetching a new page when needed
+func (p *PodcastPager) Next() bool {
+	if p.err != nil {
+		return false
+	}
+
+	p.index++
+	for p.index >= len(p.page) {
+		if !p.hasNext {
+			return false
+		}
+		p.number++
+		resp, err := p.fetch(p.number)
+		if err != nil {
+			p.err = err
+			return false
+		}
+
+		page := map[string]json.RawMessage{}
+		if err := resp.Decode(&page); err != nil {
+			p.err = err
+			return false
+		}
+		var podcasts []listennotes.Podcast
+		if raw, ok := page[p.field]; ok {
+			if err := json.Unmarshal(raw, &podcasts); err != nil {
+				p.err = fmt.Errorf("failed to decode %s of page %d: %w", p.field, p.number, err)
+				return false
+			}
+		}
+		var hasNext bool
+		json.Unmarshal(page["has_next"], &hasNext)
+		p.hasNext = hasNext && len(podcasts) > 0
+		p.page = podcasts
+		p.index = 0
+	}
+	return true
+}
+
+// Podcast is the current podcast
+func (p *PodcastPager) Podcast() listennotes.Podcast {
+	if p.index < 0 || p.index >= len(p.page) {
+		return listennotes.Podcast{}
+	}
+	return p.page[p.index]
+}
+
+// Err is the error that stopped the iteration, if any
+func (p *PodcastPager) Err() error {
+	return p.err
+}
+
+// searchResult holds the fields of both episode and podcast search results
+type searchResult struct {
+	ID             string                  `json:"id"`
+	Title          string                  `json:"title_original"`
+	Description    string                  `json:"description_original"`
+	Publisher      string                  `json:"publisher_original"`
+	Image          string                  `json:"image"`
+	Thumbnail      string                  `json:"thumbnail"`
+	Audio          string                  `json:"audio"`
+	AudioLengthSec int                     `json:"audio_length_sec"`
+	Link           string                  `json:"link"`
+	ListennotesURL string                  `json:"listennotes_url"`
+	RSS            string                  `json:"rss"`
+	Website        string                  `json:"website"`
+	PubDateMs      int64                   `json:"pub_date_ms"`
+	TotalEpisodes  int                     `json:"total_episodes"`
+	GenreIDs       []int                   `json:"genre_ids"`
+	Explicit       bool                    `json:"explicit_content"`
+	ListenScore    listennotes.ListenScore `json:"listen_score"`
+	ItunesID       int64                   `json:"itunes_id"`
+	Podcast        *struct {
+		ID             string                  `json:"id"`
+		Title          string                  `json:"title_original"`
+		Publisher      string                  `json:"publisher_original"`
+		Image          string                  `json:"image"`
+		Thumbnail      string                  `json:"thumbnail"`
+		ListennotesURL string                  `json:"listennotes_url"`
+		GenreIDs       []int                   `json:"genre_ids"`
+		ListenScore    listennotes.ListenScore `json:"listen_score"`
+	} `json:"podcast"`
+}
+
+// SearchIterator walks the results of Search, following next_offset.  It is an EpisodeSource for type "episode" and a
+// PodcastSource for type "podcast", mapping the original, not highlighted, titles onto the typed models.
+type SearchIterator struct {
+	pager *searchpage.Pager
+	page  []searchResult
+	index int
+	err   error
+}
+
+// NewSearchIterator will create an iterator over the search results.  args are passed to every page request, set
+// "offset" to start further down.
+func NewSearchIterator(client listennotes.HTTPClient, args map[string]string) *SearchIterator {
+	return &SearchIterator{pager: searchpage.New(client, args), index: -1}
+}
+
+// Next advances to the next result, fetching a new page when needed
+func (it *SearchIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.page) {
+		if !it.pager.More() {
+			return false
+		}
+		it.page = nil
+		if err := it.pager.Next(&it.page); err != nil {
+			it.err = err
+			return false
+		}
+		it.index = 0
+	}
+	return true
+}
+
+// Episode is the current result as an episode
+func (it *SearchIterator) Episode() listennotes.Episode {
+	if it.index < 0 || it.index >= len(it.page) {
+		return listennotes.Episode{}
+	}
+	r := it.page[it.index]
+	episode := listennotes.Episode{
+		ID:              r.ID,
+		Title:           r.Title,
+		Description:     r.Description,
+		Audio:           r.Audio,
+		AudioLengthSec:  r.AudioLengthSec,
+		Image:           r.Image,
+		Thumbnail:       r.Thumbnail,
+		Link:            r.Link,
+		ListennotesURL:  r.ListennotesURL,
+		PubDateMs:       r.PubDateMs,
+		ExplicitContent: r.Explicit,
+	}
+	if r.Podcast != nil {
+		episode.Podcast = &listennotes.Podcast{
+			ID:             r.Podcast.ID,
+			Title:          r.Podcast.Title,
+			Publisher:      r.Podcast.Publisher,
+			Image:          r.Podcast.Image,
+			Thumbnail:      r.Podcast.Thumbnail,
+			ListennotesURL: r.Podcast.ListennotesURL,
+			GenreIDs:       r.Podcast.GenreIDs,
+			ListenScore:    r.Podcast.ListenScore,
+		}
+	}
+	return episode
+}
+
+// Podcast is the current result as a podcast
+func (it *SearchIterator) Podcast() listennotes.Podcast {
+	if it.index < 0 || it.index >= len(it.page) {
+		return listennotes.Podcast{}
+	}
+	r := it.page[it.index]
+	return listennotes.Podcast{
+		ID:              r.ID,
+		Title:           r.Title,
+		Publisher:       r.Publisher,
+		Description:     r.Description,
+		Image:           r.Image,
+		Thumbnail:       r.Thumbnail,
+		Website:         r.Website,
+		RSS:             r.RSS,
+		ListennotesURL:  r.ListennotesURL,
+		ItunesID:        r.ItunesID,
+		GenreIDs:        r.GenreIDs,
+		TotalEpisodes:   r.TotalEpisodes,
+		ExplicitContent: r.Explicit,
+		ListenScore:     r.ListenScore,
+	}
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// PlaylistEpisodes is an EpisodeSource over the episodes of a playlist.  Podcast items and episodes that were deleted
+// from the podcast database are skipped.
+type PlaylistEpisodes struct {
+	it *listennotes.PlaylistItemIterator
+}
+
+// NewPlaylistEpisodes will create a source over the episode items of the iterator, e.g.
+//
+//	it := listennotes.NewPlaylistItemIterator(client, playlistID, listennotes.PlaylistEpisodeList, nil)
+//	exporter.WriteEpisodes(w, export.NewPlaylistEpisodes(it))
+func NewPlaylistEpisodes(it *listennotes.PlaylistItemIterator) *PlaylistEpisodes {
+	return &PlaylistEpisodes{it: it}
+}
+
+// Next advances to the next episode item
+func (p *PlaylistEpisodes) Next() bool {
+	for p.it.Next() {
+		if item := p.it.Item(); item.Episode != nil && !item.Deleted() {
+			return true
+		}
+	}
+	return false
+}
+
+// Episode is the episode of the current item
+func (p *PlaylistEpisodes) Episode() listennotes.Episode {
+	if episode := p.it.Item().Episode; episode != nil {
+		return *episode
+	}
+	return listennotes.Episode{}
+}
+
+// Err is the error that stopped the iteration, if any
+func (p *PlaylistEpisodes) Err() error {
+	return p.it.Err()
+}
+
+// batchSize is the number of ids BatchFetchEpisodes accepts per call
+const batchSize = 10
+
+// BatchEpisodeIterator is an EpisodeSource fetching episodes by id with BatchFetchEpisodes, a batch at a time
+type BatchEpisodeIterator struct {
+	client listennotes.HTTPClient
+	ids    []string
+
+	page  []listennotes.Episode
+	index int
+	err   error
+}
+
+// NewBatchEpisodeIterator will create an iterator over the episodes with the ids.  Ids the api does not know are
+// skipped.
+func NewBatchEpisodeIterator(client listennotes.HTTPClient, ids []string) *BatchEpisodeIterator {
+	return &BatchEpisodeIterator{client: client, ids: ids, index: -1}
+}
+
+// Next advances to the next episode, fetching the next batch when needed
+func (it *BatchEpisodeIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.page) {
+		if len(it.ids) == 0 {
+			return false
+		}
+		batch := it.ids
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		it.ids = it.ids[len(batch):]
+
+		resp, err := it.client.BatchFetchEpisodes(map[string]string{"ids": strings.Join(batch, ",")})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		var page struct {
+			Episodes []listennotes.Episode `json:"episodes"`
+		}
+		if err := resp.Decode(&page); err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page.Episodes
+		it.index = 0
+	}
+	return true
+}
+
+// Episode is the current episode
+func (it *BatchEpisodeIterator) Episode() listennotes.Episode {
+	if it.index < 0 || it.index >= len(it.page) {
+		return listennotes.Episode{}
+	}
+	return it.page[it.index]
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *BatchEpisodeIterator) Err() error {
+	return it.err
+}
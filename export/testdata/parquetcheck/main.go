@@ -0,0 +1,172 @@
+// Command parquetcheck reads a Parquet file with parquet-go and checks every row against a JSON Lines file of the
+// same rows.  It validates the export package's Parquet writer with an independent reader:
+//
+//	go run . ../episodes.parquet ../episodes.jsonl
+//
+// It is a separate module so that the export package does not depend on parquet-go.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: parquetcheck file.parquet file.jsonl")
+		os.Exit(2)
+	}
+	if err := check(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func check(parquetPath, jsonPath string) error {
+	actual, err := readParquet(parquetPath)
+	if err != nil {
+		return err
+	}
+	expected, err := readJSONLines(jsonPath)
+	if err != nil {
+		return err
+	}
+
+	if len(actual) != len(expected) {
+		return fmt.Errorf("expected %d rows but read %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		for name, want := range expected[i] {
+			got, ok := actual[i][name]
+			if !ok {
+				return fmt.Errorf("row %d has no column %s", i, name)
+			}
+			if !reflect.DeepEqual(normalize(got), normalize(want)) {
+				return fmt.Errorf("row %d column %s: expected %#v but read %#v", i, name, want, got)
+			}
+		}
+		if len(actual[i]) != len(expected[i]) {
+			return fmt.Errorf("row %d has %d columns, expected %d", i, len(actual[i]), len(expected[i]))
+		}
+	}
+	fmt.Printf("%d rows of %s match %s\n", len(actual), parquetPath, jsonPath)
+	return nil
+}
+
+// readParquet reads the rows as column name to value, lists for repeated columns and nil for nulls
+func readParquet(path string) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	file, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	leaves := file.Schema().Columns()
+	var rows []map[string]interface{}
+	for _, group := range file.RowGroups() {
+		reader := group.Rows()
+		buf := make([]parquet.Row, 16)
+		for {
+			n, err := reader.ReadRows(buf)
+			for _, row := range buf[:n] {
+				rows = append(rows, decodeRow(file.Schema(), leaves, row))
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return nil, fmt.Errorf("failed to read rows of %s: %w", path, err)
+			}
+		}
+		reader.Close()
+	}
+	if int64(len(rows)) != file.NumRows() {
+		return nil, fmt.Errorf("read %d rows but the metadata has %d", len(rows), file.NumRows())
+	}
+	return rows, nil
+}
+
+func decodeRow(schema *parquet.Schema, leaves [][]string, row parquet.Row) map[string]interface{} {
+	decoded := map[string]interface{}{}
+	for i, path := range leaves {
+		name := path[0]
+		leaf, _ := schema.Lookup(path...)
+		repeated := leaf.Node.Repeated()
+		if repeated {
+			decoded[name] = []interface{}{}
+		} else {
+			decoded[name] = nil
+		}
+		for _, v := range row {
+			if v.Column() != i || v.IsNull() {
+				continue
+			}
+			if repeated {
+				decoded[name] = append(decoded[name].([]interface{}), value(v))
+			} else {
+				decoded[name] = value(v)
+			}
+		}
+	}
+	return decoded
+}
+
+func value(v parquet.Value) interface{} {
+	switch v.Kind() {
+	case parquet.Boolean:
+		return v.Boolean()
+	case parquet.Int32:
+		return float64(v.Int32())
+	case parquet.Int64:
+		return float64(v.Int64())
+	case parquet.Double:
+		return v.Double()
+	case parquet.ByteArray:
+		return string(v.ByteArray())
+	}
+	return fmt.Sprintf("unexpected %s value", v.Kind())
+}
+
+// normalize treats a null list like an empty one, JSON Lines writes null for the lists of a missing podcast
+func normalize(v interface{}) interface{} {
+	if list, ok := v.([]interface{}); ok && len(list) == 0 {
+		return nil
+	}
+	return v
+}
+
+func readJSONLines(path string) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rows []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var row map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
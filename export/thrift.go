@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+)
+
+// Thrift compact protocol type ids
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Thrift compact protocol, just enough of it for Parquet metadata
+type thriftWriter struct {
+	buf   bytes.Buffer
+	last  int16
+	stack []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.uvarint(uint64(uint16((id << 1) ^ (id >> 15))))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	for v >= 0x80 {
+		t.buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	t.buf.WriteByte(byte(v))
+}
+
+func (t *thriftWriter) varint32(v int32) {
+	t.uvarint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (t *thriftWriter) varint64(v int64) {
+	t.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) string(s string) {
+	t.uvarint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint32(v)
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint64(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.string(s)
+}
+
+// list writes a list field header, the elements follow
+func (t *thriftWriter) list(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.uvarint(uint64(size))
+	}
+}
+
+// structField starts a struct valued field, closed with end
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.begin()
+}
+
+// begin starts a struct that is a list element
+func (t *thriftWriter) begin() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+// end closes a struct started with begin or structField
+func (t *thriftWriter) end() {
+	t.buf.WriteByte(0)
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
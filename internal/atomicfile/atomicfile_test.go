@@ -0,0 +1,27 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFile(filename, []byte(content)); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		data, _ := os.ReadFile(filename)
+		if string(data) != content {
+			t.Errorf("Expected %q but got %q", content, data)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Temporary files should not be left behind: %v", entries)
+	}
+}
@@ -0,0 +1,18 @@
+// Package feedurl compares rss feed urls
+package feedurl
+
+import (
+	"strings"
+)
+
+// Normalize allows matching rss urls that only differ by scheme, case of the host or a trailing slash
+func Normalize(rss string) string {
+	rss = strings.TrimSpace(rss)
+	rss = strings.TrimPrefix(rss, "https://")
+	rss = strings.TrimPrefix(rss, "http://")
+	rss = strings.TrimSuffix(rss, "/")
+	if i := strings.Index(rss, "/"); i >= 0 {
+		return strings.ToLower(rss[:i]) + rss[i:]
+	}
+	return strings.ToLower(rss)
+}
@@ -0,0 +1,16 @@
+package feedurl
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	a := Normalize(" https://Feeds.Example.com/Show.xml/ ")
+	b := Normalize("http://feeds.example.com/Show.xml")
+	if a != b || a != "feeds.example.com/Show.xml" {
+		t.Errorf("Expected the urls to match but got %q and %q", a, b)
+	}
+	if Normalize("https://feeds.example.com/show.xml") == b {
+		t.Errorf("The case of the path should be kept")
+	}
+}
@@ -0,0 +1,79 @@
+// Package lru provides a small in-memory LRU cache whose entries expire after a fixed ttl.
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// Cache is a size bounded LRU cache with a fixed ttl.  It is safe for concurrent use.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// New will create a cache whose entries expire ttl after they are set.  maxEntries bounds the cache size, the least
+// recently used entry is evicted first.  Zero means no bound.  now is the clock used for expiry, nil means time.Now.
+func New(ttl time.Duration, maxEntries int, now func() time.Time) *Cache {
+	if now == nil {
+		now = time.Now
+	}
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        now,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+// Get returns the value stored for key, if it has not expired
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	if c.now().After(e.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return e.value, true
+}
+
+// Set stores value for key, evicting the least recently used entries beyond the size bound
+func (c *Cache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &entry{key: key, value: value, expires: c.now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(e)
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
@@ -0,0 +1,39 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := New(time.Minute, 0, func() time.Time { return now })
+
+	cache.Set("a", 1)
+	if value, ok := cache.Get("a"); !ok || value.(int) != 1 {
+		t.Errorf("Expected the stored value, got %v %v", value, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("Expected the entry to expire")
+	}
+}
+
+func TestEviction(t *testing.T) {
+	cache := New(time.Minute, 2, nil)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected %s to be kept", key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Package searchpage walks the pages of Search results by following next_offset.
+package searchpage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type page struct {
+	NextOffset int               `json:"next_offset"`
+	Total      int               `json:"total"`
+	Results    []json.RawMessage `json:"results"`
+}
+
+// Pager fetches consecutive pages of Search results
+type Pager struct {
+	client listennotes.HTTPClient
+	args   map[string]string
+	offset int
+	done   bool
+}
+
+// New will create a pager over the search results.  args are passed to every page request, set "offset" to start
+// further down.
+func New(client listennotes.HTTPClient, args map[string]string) *Pager {
+	p := &Pager{client: client, args: args}
+	if offset, err := strconv.Atoi(args["offset"]); err == nil {
+		p.offset = offset
+	}
+	return p
+}
+
+// More reports whether there are pages left to fetch
+func (p *Pager) More() bool {
+	return !p.done
+}
+
+// Next fetches the next page and decodes its results into results, a pointer to a slice.  Api errors are returned
+// as is, the pager stays on the page so that Next can be called again.
+func (p *Pager) Next(results interface{}) error {
+	args := map[string]string{}
+	for k, v := range p.args {
+		args[k] = v
+	}
+	args["offset"] = strconv.Itoa(p.offset)
+	resp, err := p.client.Search(args)
+	if err != nil {
+		return err
+	}
+	page := &page{}
+	if err := resp.Decode(page); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(page.Results)
+	if err != nil {
+		return fmt.Errorf("failed to decode search results: %w", err)
+	}
+	if err := json.Unmarshal(raw, results); err != nil {
+		return fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	// a page that does not move the offset forward would loop forever
+	p.done = len(page.Results) == 0 || page.NextOffset <= p.offset || page.NextOffset >= page.Total
+	p.offset = page.NextOffset
+	return nil
+}
@@ -0,0 +1,70 @@
+package searchpage
+
+import (
+	"strconv"
+	"testing"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	total   int
+	stuck   bool
+	offsets []string
+}
+
+func (c *fakeClient) Search(args map[string]string) (*listennotes.Response, error) {
+	c.offsets = append(c.offsets, args["offset"])
+	offset, _ := strconv.Atoi(args["offset"])
+	next := offset + 2
+	if c.stuck {
+		next = offset
+	}
+	var results []interface{}
+	for i := offset; i < offset+2 && i < c.total; i++ {
+		results = append(results, map[string]interface{}{"id": strconv.Itoa(i)})
+	}
+	return &listennotes.Response{Data: map[string]interface{}{
+		"results":     results,
+		"next_offset": next,
+		"total":       c.total,
+	}}, nil
+}
+
+func TestPager(t *testing.T) {
+	client := &fakeClient{total: 5}
+	pager := New(client, map[string]string{"q": "star", "offset": "1"})
+
+	var ids []string
+	for pager.More() {
+		var results []struct {
+			ID string `json:"id"`
+		}
+		if err := pager.Next(&results); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+	}
+	if len(ids) != 4 || ids[0] != "1" || ids[3] != "4" {
+		t.Errorf("Unexpected results: %v", ids)
+	}
+	if len(client.offsets) != 2 || client.offsets[1] != "3" {
+		t.Errorf("Unexpected offsets: %v", client.offsets)
+	}
+}
+
+func TestPagerStuckOffset(t *testing.T) {
+	client := &fakeClient{total: 10, stuck: true}
+	pager := New(client, map[string]string{"q": "star"})
+
+	var results []interface{}
+	for pager.More() && len(client.offsets) < 5 {
+		pager.Next(&results)
+	}
+	if len(client.offsets) != 1 {
+		t.Errorf("A page that does not move the offset should be the last: %v", client.offsets)
+	}
+}
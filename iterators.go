@@ -0,0 +1,111 @@
+package listennotes
+
+import (
+	"strconv"
+)
+
+// PodcastEpisodeIterator walks every episode of a podcast, fetching pages with FetchPodcastByID and following
+// next_episode_pub_date.  Use it like a bufio.Scanner:
+//
+//	it := listennotes.NewPodcastEpisodeIterator(client, podcastID, nil)
+//	for it.Next() {
+//		fmt.Println(it.Episode().Title)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PodcastEpisodeIterator struct {
+	client    HTTPClient
+	podcastID string
+	args      map[string]string
+
+	podcast  Podcast
+	page     []Episode
+	index    int
+	nextDate int64
+	started  bool
+	err      error
+}
+
+// NewPodcastEpisodeIterator will create an iterator over the podcast episodes.  args are passed to every page
+// request, e.g. "sort" to walk the oldest episodes first.
+func NewPodcastEpisodeIterator(client HTTPClient, podcastID string, args map[string]string) *PodcastEpisodeIterator {
+	return &PodcastEpisodeIterator{
+		client:    client,
+		podcastID: podcastID,
+		args:      args,
+		index:     -1,
+	}
+}
+
+// Next advances to the next episode, fetching a new page when needed.  It returns false once every episode has been
+// visited or an error happened.
+func (it *PodcastEpisodeIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.page) {
+		if it.started && it.nextDate == 0 {
+			return false
+		}
+		if !it.fetch() {
+			return false
+		}
+	}
+	return true
+}
+
+// Episode is the current episode
+func (it *PodcastEpisodeIterator) Episode() Episode {
+	if it.index < 0 || it.index >= len(it.page) {
+		return Episode{}
+	}
+	return it.page[it.index]
+}
+
+// Podcast is the podcast being walked, available after the first call to Next.  Its Episodes field only holds the
+// current page.
+func (it *PodcastEpisodeIterator) Podcast() Podcast {
+	return it.podcast
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *PodcastEpisodeIterator) Err() error {
+	return it.err
+}
+
+func (it *PodcastEpisodeIterator) fetch() bool {
+	args := map[string]string{}
+	for k, v := range it.args {
+		args[k] = v
+	}
+	if it.started {
+		args["next_episode_pub_date"] = strconv.FormatInt(it.nextDate, 10)
+	}
+
+	resp, err := it.client.FetchPodcastByID(it.podcastID, args)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	var podcast Podcast
+	if err := resp.Decode(&podcast); err != nil {
+		it.err = err
+		return false
+	}
+
+	// a page that does not move the cursor forward would loop forever
+	if it.started && podcast.NextEpisodePubDate == it.nextDate {
+		podcast.NextEpisodePubDate = 0
+	}
+
+	it.started = true
+	it.podcast = podcast
+	it.page = podcast.Episodes
+	it.index = 0
+	it.nextDate = podcast.NextEpisodePubDate
+	return true
+}
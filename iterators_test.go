@@ -0,0 +1,58 @@
+package listennotes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPodcastEpisodeIterator(t *testing.T) {
+	pages := map[string]string{
+		"":    `{"id": "abc", "title": "A podcast", "episodes": [{"id": "e1"}, {"id": "e2"}], "next_episode_pub_date": 200}`,
+		"200": `{"id": "abc", "title": "A podcast", "episodes": [{"id": "e3"}], "next_episode_pub_date": 100}`,
+		"100": `{"id": "abc", "title": "A podcast", "episodes": [], "next_episode_pub_date": 100}`,
+	}
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next := r.URL.Query().Get("next_episode_pub_date")
+		requests = append(requests, next)
+		if r.URL.Query().Get("sort") != "oldest_first" {
+			t.Errorf("args were not passed to every page: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(pages[next]))
+	}))
+	defer ts.Close()
+
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL))
+	it := NewPodcastEpisodeIterator(client, "abc", map[string]string{"sort": "oldest_first"})
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Episode().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if len(ids) != 3 || ids[0] != "e1" || ids[2] != "e3" {
+		t.Errorf("Episodes were not as expected: %v", ids)
+	}
+	if len(requests) != 3 {
+		t.Errorf("Expected 3 page requests but got %v", requests)
+	}
+	if it.Podcast().Title != "A podcast" {
+		t.Errorf("Podcast was not as expected: %+v", it.Podcast())
+	}
+}
+
+func TestPodcastEpisodeIteratorError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL))
+	it := NewPodcastEpisodeIterator(client, "abc", nil)
+	if it.Next() || it.Err() != ErrNotFound {
+		t.Errorf("Expected the iteration to stop with not found: %v", it.Err())
+	}
+}
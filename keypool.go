@@ -0,0 +1,189 @@
+package listennotes
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultKeyDisableDuration is how long a key is out of rotation when the api did not report its next billing date
+const defaultKeyDisableDuration = time.Hour
+
+// KeyPolicy decides which key of a KeyPool is used for the next request
+type KeyPolicy int
+
+// Available key policies
+const (
+	// RoundRobin cycles through the available keys in order
+	RoundRobin KeyPolicy = iota
+	// LeastUsed picks the available key with the lowest reported usage
+	LeastUsed
+	// PriorityFailover always picks the first available key, later keys are only used when earlier ones are out of
+	// rotation
+	PriorityFailover
+)
+
+// KeyStats is the usage and quota accounting of a single key in a KeyPool
+type KeyStats struct {
+	// Key is the api key with all but its last four characters masked
+	Key             string
+	Requests        int
+	Failures        int
+	FreeQuota       int
+	Usage           int
+	NextBillingDate time.Time
+	// DisabledUntil is set while the key is out of rotation
+	DisabledUntil time.Time
+}
+
+type pooledKey struct {
+	apiKey string
+	stats  KeyStats
+}
+
+// KeyPool spreads requests across several api keys.  A key answering with ErrUnauthorized, or with
+// ErrTooManyRequests once its quota is used up, is taken out of rotation until its next billing date.
+type KeyPool struct {
+	policy KeyPolicy
+	now    func() time.Time
+
+	mu   sync.Mutex
+	keys []*pooledKey
+	next int
+}
+
+// NewKeyPool will create a pool over the given keys.  For PriorityFailover the keys are in priority order.
+func NewKeyPool(policy KeyPolicy, apiKeys ...string) *KeyPool {
+	pool := &KeyPool{
+		policy: policy,
+		now:    time.Now,
+	}
+	for _, apiKey := range apiKeys {
+		pool.keys = append(pool.keys, &pooledKey{
+			apiKey: apiKey,
+			stats:  KeyStats{Key: maskKey(apiKey)},
+		})
+	}
+	return pool
+}
+
+// Snapshot returns the current accounting of every key, in the order the keys were given
+func (p *KeyPool) Snapshot() []KeyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make([]KeyStats, len(p.keys))
+	for i, k := range p.keys {
+		snapshot[i] = k.stats
+	}
+	return snapshot
+}
+
+func (p *KeyPool) size() int {
+	return len(p.keys)
+}
+
+// acquire picks the key for the next request according to the pool policy
+func (p *KeyPool) acquire() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var picked *pooledKey
+	switch p.policy {
+	case LeastUsed:
+		for _, k := range p.keys {
+			if !k.enabled(now) {
+				continue
+			}
+			if picked == nil || k.stats.Usage < picked.stats.Usage ||
+				(k.stats.Usage == picked.stats.Usage && k.stats.Requests < picked.stats.Requests) {
+				picked = k
+			}
+		}
+	case PriorityFailover:
+		for _, k := range p.keys {
+			if k.enabled(now) {
+				picked = k
+				break
+			}
+		}
+	default:
+		for i := 0; i < len(p.keys); i++ {
+			k := p.keys[(p.next+i)%len(p.keys)]
+			if k.enabled(now) {
+				picked = k
+				p.next = (p.next + i + 1) % len(p.keys)
+				break
+			}
+		}
+	}
+
+	if picked == nil {
+		return "", ErrNoAPIKeyAvailable
+	}
+	picked.stats.Requests++
+	return picked.apiKey, nil
+}
+
+// available reports whether the key is currently in rotation
+func (p *KeyPool) available(apiKey string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, k := range p.keys {
+		if k.apiKey == apiKey {
+			return k.enabled(p.now())
+		}
+	}
+	return false
+}
+
+// report records the outcome of a request made with the key
+func (p *KeyPool) report(apiKey string, statusCode int, stats ResponseStatistics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, k := range p.keys {
+		if k.apiKey != apiKey {
+			continue
+		}
+
+		if stats.FreeQuota > 0 {
+			k.stats.FreeQuota = stats.FreeQuota
+		}
+		if stats.Usage > 0 {
+			k.stats.Usage = stats.Usage
+		}
+		if !stats.NextBillingDate.IsZero() {
+			k.stats.NextBillingDate = stats.NextBillingDate
+		}
+
+		if statusCode != 200 {
+			k.stats.Failures++
+		}
+
+		// a 429 is either the quota being used up, or simply sending too fast.  Only the first one is worth waiting
+		// for a new billing period.
+		quotaExhausted := statusCode == 429 && k.stats.FreeQuota > 0 && k.stats.Usage >= k.stats.FreeQuota
+		if statusCode == 401 || quotaExhausted {
+			now := p.now()
+			if k.stats.NextBillingDate.After(now) {
+				k.stats.DisabledUntil = k.stats.NextBillingDate
+			} else {
+				k.stats.DisabledUntil = now.Add(defaultKeyDisableDuration)
+			}
+		}
+		return
+	}
+}
+
+func (k *pooledKey) enabled(now time.Time) bool {
+	return !now.Before(k.stats.DisabledUntil)
+}
+
+func maskKey(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "****"
+	}
+	return "****" + apiKey[len(apiKey)-4:]
+}
@@ -0,0 +1,122 @@
+package listennotes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newKeyPoolTestServer(t *testing.T, statusByKey map[string]int, usageByKey map[string]string) (*httptest.Server, map[string]int) {
+	seen := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.Header.Get(RequestHeaderKeyAPI)
+		seen[apiKey]++
+		w.Header().Set(ResponseHeaderKeyFreeQuota, "100")
+		if usage, ok := usageByKey[apiKey]; ok {
+			w.Header().Set(ResponseHeaderKeyUsage, usage)
+		}
+		w.Header().Set(ResponseHeaderKeyNextBillingDate, "2030-01-02T00:00:00.000000+00:00")
+		if status, ok := statusByKey[apiKey]; ok {
+			w.WriteHeader(status)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, seen
+}
+
+func TestKeyPoolRoundRobin(t *testing.T) {
+	ts, seen := newKeyPoolTestServer(t, nil, nil)
+	pool := NewKeyPool(RoundRobin, "key-a", "key-b", "key-c")
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithKeyPool(pool))
+
+	for i := 0; i < 6; i++ {
+		if _, err := client.Search(nil); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+	}
+	for _, k := range []string{"key-a", "key-b", "key-c"} {
+		if seen[k] != 2 {
+			t.Errorf("Expected 2 requests for %s but got %d", k, seen[k])
+		}
+	}
+
+	snapshot := pool.Snapshot()
+	if snapshot[0].Key != "****ey-a" || snapshot[0].Requests != 2 || snapshot[0].FreeQuota != 100 {
+		t.Errorf("Snapshot was not as expected: %+v", snapshot[0])
+	}
+}
+
+func TestKeyPoolFailover(t *testing.T) {
+	ts, seen := newKeyPoolTestServer(t,
+		map[string]int{"key-a": 401, "key-b": 429},
+		map[string]string{"key-b": "100"},
+	)
+	pool := NewKeyPool(PriorityFailover, "key-a", "key-b", "key-c")
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithKeyPool(pool))
+
+	if _, err := client.Search(nil); err != nil {
+		t.Fatalf("Expected failover to the last key but got: %s", err)
+	}
+	if seen["key-a"] != 1 || seen["key-b"] != 1 || seen["key-c"] != 1 {
+		t.Errorf("Expected one attempt per key: %v", seen)
+	}
+
+	client.Search(nil)
+	if seen["key-c"] != 2 {
+		t.Errorf("Disabled keys should stay out of rotation: %v", seen)
+	}
+
+	snapshot := pool.Snapshot()
+	billing, _ := time.Parse(TimeFormat, "2030-01-02T00:00:00.000000+00:00")
+	if !snapshot[0].DisabledUntil.Equal(billing) || !snapshot[1].DisabledUntil.Equal(billing) {
+		t.Errorf("Keys should be out of rotation until the next billing date: %+v", snapshot)
+	}
+}
+
+func TestKeyPoolRateLimitKeepsKey(t *testing.T) {
+	ts, _ := newKeyPoolTestServer(t, map[string]int{"key-a": 429}, map[string]string{"key-a": "5"})
+	pool := NewKeyPool(PriorityFailover, "key-a", "key-b")
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithKeyPool(pool))
+
+	if _, err := client.Search(nil); err != ErrTooManyRequests {
+		t.Errorf("Expected rate limit error but got: %v", err)
+	}
+	if !pool.Snapshot()[0].DisabledUntil.IsZero() {
+		t.Errorf("A rate limited key with quota left should stay in rotation")
+	}
+}
+
+func TestKeyPoolLeastUsed(t *testing.T) {
+	ts, seen := newKeyPoolTestServer(t, nil, map[string]string{"key-a": "50", "key-b": "10"})
+	pool := NewKeyPool(LeastUsed, "key-a", "key-b")
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithKeyPool(pool))
+
+	for i := 0; i < 5; i++ {
+		client.Search(nil)
+	}
+	if seen["key-a"] != 1 || seen["key-b"] != 4 {
+		t.Errorf("Expected the least used key to be preferred once usage is known: %v", seen)
+	}
+}
+
+func TestKeyPoolExhausted(t *testing.T) {
+	ts, _ := newKeyPoolTestServer(t, map[string]int{"key-a": 401}, nil)
+	pool := NewKeyPool(RoundRobin, "key-a")
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL), WithKeyPool(pool))
+
+	if _, err := client.Search(nil); err != ErrUnauthorized {
+		t.Errorf("Expected unauthorized but got: %v", err)
+	}
+	if _, err := client.Search(nil); err != ErrNoAPIKeyAvailable {
+		t.Errorf("Expected no key available but got: %v", err)
+	}
+}
+
+func TestKeyPoolUsesProductionURL(t *testing.T) {
+	client := NewClient("", WithKeyPool(NewKeyPool(RoundRobin, "key-a"))).(*standardHTTPClient)
+	if client.baseURL != BaseURLProduction {
+		t.Errorf("A client with a key pool should use the production url: %s", client.baseURL)
+	}
+}
@@ -0,0 +1,341 @@
+// Package match finds the Listen Notes episode behind a show name and episode title from another platform, e.g. the
+// listening history exported from a podcast app.
+//
+// Candidates come from SearchEpisodeTitles, narrowed to the show when its id is known, and from Search as a fallback.
+// Each candidate is scored on title similarity, show name similarity, publish date proximity and duration, and the
+// best one above the confidence threshold is the match:
+//
+//	matcher := match.NewMatcher(client, match.WithConcurrency(8))
+//	matches := matcher.MatchAll(ctx, []match.Query{
+//		{Key: "row-1", ShowName: "Conversations with Tyler", EpisodeTitle: "Jerusalem Demsas on The Dispossessed"},
+//	})
+//	match.WriteReport(os.Stdout, matches)
+package match
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Signal weights of the confidence score.  Signals missing from the query are left out and the others rescaled.
+const (
+	weightTitle    = 0.5
+	weightShow     = 0.25
+	weightDate     = 0.15
+	weightDuration = 0.1
+)
+
+// Query is the external metadata of an episode
+type Query struct {
+	// Key identifies the query in the report, e.g. the row of the imported history
+	Key      string
+	ShowName string
+	// ShowID is the Listen Notes podcast id, ShowITunesID and ShowSpotifyID are the show's ids on other platforms.
+	// Any of them narrows the title search to the show.
+	ShowID        string
+	ShowITunesID  string
+	ShowSpotifyID string
+	EpisodeTitle  string
+	// PubDate and Duration are optional
+	PubDate  time.Time
+	Duration time.Duration
+}
+
+func (q Query) hasShowID() bool {
+	return q.showID() != ""
+}
+
+// showID returns the most specific show id of the query
+func (q Query) showID() string {
+	switch {
+	case q.ShowID != "":
+		return q.ShowID
+	case q.ShowITunesID != "":
+		return q.ShowITunesID
+	default:
+		return q.ShowSpotifyID
+	}
+}
+
+// Candidate is a Listen Notes episode scored against a query
+type Candidate struct {
+	EpisodeID    string
+	Title        string
+	PodcastID    string
+	PodcastTitle string
+	PubDate      time.Time
+	Duration     time.Duration
+
+	// Scores of the signals between 0 and 1, -1 when the signal was not available
+	TitleScore    float64
+	ShowScore     float64
+	DateScore     float64
+	DurationScore float64
+	Confidence    float64
+	// Reasons explain the scores in words
+	Reasons []string
+}
+
+// Match is the outcome of matching a query
+type Match struct {
+	Query Query
+	// Best is the best candidate when it reached the confidence threshold
+	Best *Candidate
+	// Candidates are the scored candidates, best first
+	Candidates []Candidate
+	Err        error
+}
+
+type searchResponse struct {
+	Results []struct {
+		ID             string `json:"id"`
+		Title          string `json:"title_original"`
+		PubDateMs      int64  `json:"pub_date_ms"`
+		AudioLengthSec int    `json:"audio_length_sec"`
+		Podcast        struct {
+			ID    string `json:"id"`
+			Title string `json:"title_original"`
+		} `json:"podcast"`
+	} `json:"results"`
+}
+
+// Matcher matches external episode metadata to Listen Notes episodes
+type Matcher struct {
+	client        listennotes.HTTPClient
+	minConfidence float64
+	concurrency   int
+	maxCandidates int
+	searchArgs    map[string]string
+}
+
+// NewMatcher will create a matcher with reasonable defaults.
+// You can optionally override some configuration.
+func NewMatcher(client listennotes.HTTPClient, opts ...Option) *Matcher {
+	m := &Matcher{
+		client:        client,
+		minConfidence: 0.7,
+		concurrency:   4,
+		maxCandidates: 5,
+		searchArgs:    map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Match matches a single query.  SearchEpisodeTitles is tried first, Search only when that finds no confident match.
+func (m *Matcher) Match(q Query) (Match, error) {
+	if q.EpisodeTitle == "" {
+		return Match{Query: q}, fmt.Errorf("query %s has no episode title", q.Key)
+	}
+
+	args := map[string]string{"q": q.EpisodeTitle}
+	if q.hasShowID() {
+		// podcast_id takes the Listen Notes, iTunes or Spotify id of the show
+		args["podcast_id"] = q.showID()
+	}
+	resp, err := m.client.SearchEpisodeTitles(args)
+	if err != nil {
+		return Match{Query: q}, fmt.Errorf("failed to search episode titles for %s: %w", q.Key, err)
+	}
+	candidates, err := m.candidates(resp)
+	if err != nil {
+		return Match{Query: q}, err
+	}
+
+	// the Listen Notes id of a show given by its iTunes or Spotify id is learned from the narrowed title search
+	showID := q.ShowID
+	if showID == "" && q.hasShowID() {
+		showID = commonPodcast(candidates)
+	}
+	for i := range candidates {
+		score(q, showID, &candidates[i])
+	}
+
+	if !m.confident(candidates) && q.ShowName != "" {
+		args := map[string]string{}
+		for k, v := range m.searchArgs {
+			args[k] = v
+		}
+		args["q"] = q.ShowName + " " + q.EpisodeTitle
+		args["type"] = "episode"
+		resp, err := m.client.Search(args)
+		if err != nil {
+			return Match{Query: q}, fmt.Errorf("failed to search for %s: %w", q.Key, err)
+		}
+		more, err := m.candidates(resp)
+		if err != nil {
+			return Match{Query: q}, err
+		}
+		for i := range more {
+			score(q, showID, &more[i])
+		}
+		candidates = mergeCandidates(candidates, more)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Confidence > candidates[j].Confidence })
+	if len(candidates) > m.maxCandidates {
+		candidates = candidates[:m.maxCandidates]
+	}
+
+	result := Match{Query: q, Candidates: candidates}
+	if m.confident(candidates) {
+		best := candidates[0]
+		result.Best = &best
+	}
+	return result, nil
+}
+
+// MatchAll matches the queries with bounded concurrency.  The matches are in the order of the queries, a failed query
+// has its error in Match.Err.  Queries not started when the context is done fail with the context error.
+func (m *Matcher) MatchAll(ctx context.Context, queries []Query) []Match {
+	matches := make([]Match, len(queries))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < m.concurrency && w < len(queries); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					matches[i] = Match{Query: queries[i], Err: err}
+					continue
+				}
+				match, err := m.Match(queries[i])
+				match.Err = err
+				matches[i] = match
+			}
+		}()
+	}
+	for i := range queries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return matches
+}
+
+func (m *Matcher) confident(candidates []Candidate) bool {
+	best := 0.0
+	for _, c := range candidates {
+		best = math.Max(best, c.Confidence)
+	}
+	return len(candidates) > 0 && best >= m.minConfidence
+}
+
+// candidates decodes the search results, unscored
+func (m *Matcher) candidates(resp *listennotes.Response) ([]Candidate, error) {
+	search := &searchResponse{}
+	if err := resp.Decode(search); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(search.Results))
+	for _, r := range search.Results {
+		c := Candidate{
+			EpisodeID:    r.ID,
+			Title:        r.Title,
+			PodcastID:    r.Podcast.ID,
+			PodcastTitle: r.Podcast.Title,
+			Duration:     time.Duration(r.AudioLengthSec) * time.Second,
+		}
+		if r.PubDateMs > 0 {
+			c.PubDate = time.Unix(0, r.PubDateMs*int64(time.Millisecond)).UTC()
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// commonPodcast returns the podcast id shared by every candidate, empty when there are none or they differ
+func commonPodcast(candidates []Candidate) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	for _, c := range candidates[1:] {
+		if c.PodcastID != candidates[0].PodcastID {
+			return ""
+		}
+	}
+	return candidates[0].PodcastID
+}
+
+// score fills in the signal scores, confidence and reasons of the candidate.  showID is the Listen Notes id of the
+// query's show when known.
+func score(q Query, showID string, c *Candidate) {
+	c.TitleScore = titleSimilarity(q.EpisodeTitle, c.Title)
+	c.Reasons = append(c.Reasons, fmt.Sprintf("title %.0f%% similar", c.TitleScore*100))
+	total, weights := c.TitleScore*weightTitle, weightTitle
+
+	c.ShowScore = -1
+	switch {
+	case showID != "" && showID == c.PodcastID:
+		c.ShowScore = 1
+		c.Reasons = append(c.Reasons, "show id matches")
+	case q.ShowName != "":
+		c.ShowScore = titleSimilarity(q.ShowName, c.PodcastTitle)
+		c.Reasons = append(c.Reasons, fmt.Sprintf("show %q %.0f%% similar", c.PodcastTitle, c.ShowScore*100))
+	case showID != "":
+		c.ShowScore = 0
+		c.Reasons = append(c.Reasons, "different show")
+	}
+	if c.ShowScore >= 0 {
+		total += c.ShowScore * weightShow
+		weights += weightShow
+	}
+
+	c.DateScore = -1
+	if !q.PubDate.IsZero() && !c.PubDate.IsZero() {
+		days := math.Abs(q.PubDate.Sub(c.PubDate).Hours()) / 24
+		// time zones and feed updates shift dates by a day, beyond that confidence drops over a month
+		c.DateScore = clamp(1 - (days-1)/29)
+		c.Reasons = append(c.Reasons, fmt.Sprintf("published %.1f days apart", days))
+		total += c.DateScore * weightDate
+		weights += weightDate
+	}
+
+	c.DurationScore = -1
+	if q.Duration > 0 && c.Duration > 0 {
+		diff := math.Abs(q.Duration.Seconds() - c.Duration.Seconds())
+		relative := diff / math.Max(q.Duration.Seconds(), c.Duration.Seconds())
+		// ads inserted per platform make durations differ a little, half the length is a different episode
+		if diff <= 60 {
+			c.DurationScore = 1
+		} else {
+			c.DurationScore = clamp(1 - (relative-0.05)/0.45)
+		}
+		c.Reasons = append(c.Reasons, fmt.Sprintf("duration differs by %.0f%%", relative*100))
+		total += c.DurationScore * weightDuration
+		weights += weightDuration
+	}
+
+	c.Confidence = total / weights
+}
+
+// mergeCandidates appends the candidates that are not in the list yet
+func mergeCandidates(list, more []Candidate) []Candidate {
+	seen := map[string]bool{}
+	for _, c := range list {
+		seen[c.EpisodeID] = true
+	}
+	for _, c := range more {
+		if !seen[c.EpisodeID] {
+			seen[c.EpisodeID] = true
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
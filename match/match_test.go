@@ -0,0 +1,195 @@
+package match
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+var pubDate = time.Date(2023, 9, 6, 12, 0, 0, 0, time.UTC)
+
+func result(id, title, podcastID, podcastTitle string, pub time.Time, seconds int) interface{} {
+	return map[string]interface{}{
+		"id":               id,
+		"title_original":   title,
+		"pub_date_ms":      float64(pub.UnixNano() / int64(time.Millisecond)),
+		"audio_length_sec": float64(seconds),
+		"podcast":          map[string]interface{}{"id": podcastID, "title_original": podcastTitle},
+	}
+}
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	titles   map[string][]interface{}
+	search   map[string][]interface{}
+	mu       sync.Mutex
+	requests []map[string]string
+}
+
+func (c *fakeClient) record(args map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, args)
+}
+
+func (c *fakeClient) SearchEpisodeTitles(args map[string]string) (*listennotes.Response, error) {
+	c.record(args)
+	return &listennotes.Response{Data: map[string]interface{}{"results": c.titles[args["q"]]}}, nil
+}
+
+func (c *fakeClient) Search(args map[string]string) (*listennotes.Response, error) {
+	c.record(args)
+	if args["q"] == "fail" {
+		return nil, listennotes.ErrInternalServerError
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"results": c.search[args["q"]]}}, nil
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	if s := titleSimilarity("Jerusalem Demsas on The Dispossessed", "Jerusalem Demsas on The Dispossessed, Gulliver's Travels"); s < 0.7 {
+		t.Errorf("Expected a longer title to be similar: %v", s)
+	}
+	if s := titleSimilarity("#12: The Big Short!", "12 the big short"); s != 1 {
+		t.Errorf("Punctuation and case should not matter: %v", s)
+	}
+	if s := titleSimilarity("Cooking with gas", "The history of Rome"); s > 0.3 {
+		t.Errorf("Expected different titles to differ: %v", s)
+	}
+}
+
+func TestMatchWithinShow(t *testing.T) {
+	client := &fakeClient{titles: map[string][]interface{}{
+		"Jerusalem Demsas on The Dispossessed": {
+			result("other", "Jerusalem Demsas on housing", "cda", "Conversations with Tyler", pubDate.AddDate(-1, 0, 0), 1000),
+			result("e1", "Jerusalem Demsas on The Dispossessed, Gulliver's Travels, and Of Boys and Men", "cda",
+				"Conversations with Tyler", pubDate, 3788),
+		},
+	}}
+	matcher := NewMatcher(client)
+
+	m, err := matcher.Match(Query{
+		ShowITunesID: "983795625",
+		EpisodeTitle: "Jerusalem Demsas on The Dispossessed",
+		PubDate:      pubDate.Add(6 * time.Hour),
+		Duration:     3800 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if m.Best == nil || m.Best.EpisodeID != "e1" || m.Status() != StatusMatched {
+		t.Fatalf("Expected e1 to match but got %+v", m)
+	}
+	if m.Best.DateScore != 1 || m.Best.DurationScore != 1 || m.Best.ShowScore != 1 || len(m.Best.Reasons) != 4 {
+		t.Errorf("Unexpected scores: %+v", m.Best)
+	}
+	if len(client.requests) != 1 || client.requests[0]["podcast_id"] != "983795625" {
+		t.Errorf("Expected a single title search within the show: %v", client.requests)
+	}
+}
+
+func candidate(m Match, episodeID string) Candidate {
+	for _, c := range m.Candidates {
+		if c.EpisodeID == episodeID {
+			return c
+		}
+	}
+	return Candidate{}
+}
+
+func TestShowScore(t *testing.T) {
+	client := &fakeClient{
+		titles: map[string][]interface{}{
+			"Episode one": {
+				result("e1", "Episode one", "p1", "Show", pubDate, 600),
+				result("e2", "Episode one", "p2", "Other", pubDate, 600),
+			},
+			"Episode two": {result("e3", "Trailer", "p1", "Show", pubDate, 60)},
+		},
+		search: map[string][]interface{}{
+			"Show Episode two": {result("e4", "Episode two", "p2", "Show Clips", pubDate, 600)},
+		},
+	}
+	matcher := NewMatcher(client)
+
+	m, _ := matcher.Match(Query{ShowID: "p1", EpisodeTitle: "Episode one"})
+	if candidate(m, "e1").ShowScore != 1 || candidate(m, "e2").ShowScore != 0 || m.Best.EpisodeID != "e1" {
+		t.Errorf("Only the episode of the show should score on the show: %+v", m.Candidates)
+	}
+
+	// the show behind a Spotify id is unknown when the title search returns several podcasts
+	m, _ = matcher.Match(Query{ShowSpotifyID: "sp1", EpisodeTitle: "Episode one"})
+	if candidate(m, "e1").ShowScore != -1 || candidate(m, "e2").ShowScore != -1 {
+		t.Errorf("Expected no show score without a resolved show: %+v", m.Candidates)
+	}
+
+	// the fallback search is not narrowed to the show resolved from the iTunes id
+	m, _ = matcher.Match(Query{ShowITunesID: "111", ShowName: "Show", EpisodeTitle: "Episode two"})
+	if candidate(m, "e3").ShowScore != 1 || candidate(m, "e4").ShowScore >= 1 {
+		t.Errorf("Expected only the resolved show to match by id: %+v", m.Candidates)
+	}
+}
+
+func TestMatchFallsBackToSearch(t *testing.T) {
+	client := &fakeClient{search: map[string][]interface{}{
+		"The Daily The Sunday Read": {result("e2", "The Sunday Read: A Story", "d1", "The Daily", pubDate, 1800)},
+	}}
+	matcher := NewMatcher(client, WithMinConfidence(0.6))
+
+	m, err := matcher.Match(Query{ShowName: "The Daily", EpisodeTitle: "The Sunday Read"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if m.Best == nil || m.Best.EpisodeID != "e2" || m.Best.DateScore != -1 {
+		t.Errorf("Expected the search result to match but got %+v", m)
+	}
+	if len(client.requests) != 2 || client.requests[1]["type"] != "episode" {
+		t.Errorf("Expected a fallback search: %v", client.requests)
+	}
+}
+
+func TestMatchAllAndReport(t *testing.T) {
+	client := &fakeClient{titles: map[string][]interface{}{
+		"Episode one": {result("e1", "Episode one", "p1", "Show", pubDate, 600)},
+		"Unrelated":   {result("x", "Something else entirely", "p9", "Other show", pubDate.AddDate(0, -6, 0), 60)},
+	}}
+	matcher := NewMatcher(client, WithConcurrency(2))
+
+	queries := []Query{
+		{Key: "1", ShowName: "Show", EpisodeTitle: "Episode one"},
+		{Key: "2", ShowName: "Show", EpisodeTitle: "Unrelated", PubDate: pubDate},
+		{Key: "3", ShowName: "Nothing", EpisodeTitle: "Missing"},
+		{Key: "4", ShowName: "Show", EpisodeTitle: ""},
+	}
+	matches := matcher.MatchAll(context.Background(), queries)
+
+	statuses := []string{StatusMatched, StatusUncertain, StatusNotFound, StatusFailed}
+	for i, m := range matches {
+		if m.Query.Key != queries[i].Key || m.Status() != statuses[i] {
+			t.Errorf("Expected query %s to be %s but got %s: %+v", queries[i].Key, statuses[i], m.Status(), m)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, matches); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 5 {
+		t.Fatalf("Expected a header and 4 rows but got %v: %v", rows, err)
+	}
+	if rows[1][4] != "e1" || rows[1][7] != "1.00" || !strings.Contains(rows[1][12], "title 100% similar") {
+		t.Errorf("Unexpected matched row: %v", rows[1])
+	}
+	if rows[2][3] != StatusUncertain || rows[2][10] != "0.00" || rows[2][11] != "" {
+		t.Errorf("Unexpected uncertain row: %v", rows[2])
+	}
+	if !strings.Contains(rows[4][12], "no episode title") {
+		t.Errorf("Unexpected failed row: %v", rows[4])
+	}
+}
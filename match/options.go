@@ -0,0 +1,34 @@
+package match
+
+// Option allows for options to be passed to the matcher constructor function
+type Option func(m *Matcher)
+
+// WithMinConfidence sets the confidence a candidate needs to be the match.  The default is 0.7.
+func WithMinConfidence(confidence float64) Option {
+	return func(m *Matcher) {
+		m.minConfidence = confidence
+	}
+}
+
+// WithConcurrency sets how many queries MatchAll runs at once.  The default is 4.
+func WithConcurrency(n int) Option {
+	return func(m *Matcher) {
+		if n > 0 {
+			m.concurrency = n
+		}
+	}
+}
+
+// WithMaxCandidates sets how many scored candidates are kept per match for the report.  The default is 5.
+func WithMaxCandidates(n int) Option {
+	return func(m *Matcher) {
+		m.maxCandidates = n
+	}
+}
+
+// WithSearchArgs passes extra arguments, e.g. "language", to the fallback Search
+func WithSearchArgs(args map[string]string) Option {
+	return func(m *Matcher) {
+		m.searchArgs = args
+	}
+}
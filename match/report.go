@@ -0,0 +1,78 @@
+package match
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// reportHeader are the columns of the match report
+var reportHeader = []string{
+	"key", "show", "episode_title", "status", "episode_id", "matched_title", "matched_show", "confidence",
+	"title_score", "show_score", "date_score", "duration_score", "explanation",
+}
+
+// Match statuses of the report
+const (
+	StatusMatched   = "matched"
+	StatusUncertain = "uncertain"
+	StatusNotFound  = "not_found"
+	StatusFailed    = "failed"
+)
+
+// Status summarizes the outcome: matched, uncertain when candidates were found below the confidence threshold,
+// not_found or failed
+func (m Match) Status() string {
+	switch {
+	case m.Err != nil:
+		return StatusFailed
+	case m.Best != nil:
+		return StatusMatched
+	case len(m.Candidates) > 0:
+		return StatusUncertain
+	default:
+		return StatusNotFound
+	}
+}
+
+// WriteReport writes the matches as CSV, one row per query.  Uncertain matches list their best candidate, so that
+// they can be reviewed by hand, and the explanation column says why each candidate scored the way it did.
+func WriteReport(w io.Writer, matches []Match) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportHeader); err != nil {
+		return fmt.Errorf("failed to write match report: %w", err)
+	}
+
+	for _, m := range matches {
+		row := []string{m.Query.Key, m.Query.ShowName, m.Query.EpisodeTitle, m.Status()}
+		switch {
+		case m.Err != nil:
+			row = append(row, "", "", "", "", "", "", "", "", m.Err.Error())
+		case len(m.Candidates) > 0:
+			c := m.Candidates[0]
+			row = append(row, c.EpisodeID, c.Title, c.PodcastTitle, formatScore(c.Confidence),
+				formatScore(c.TitleScore), formatScore(c.ShowScore), formatScore(c.DateScore),
+				formatScore(c.DurationScore), strings.Join(c.Reasons, "; "))
+		default:
+			row = append(row, "", "", "", "", "", "", "", "", "no candidates found")
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write match report: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write match report: %w", err)
+	}
+	return nil
+}
+
+// formatScore leaves unavailable signals empty
+func formatScore(score float64) string {
+	if score < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", score)
+}
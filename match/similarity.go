@@ -0,0 +1,100 @@
+package match
+
+import (
+	"strings"
+	"unicode"
+)
+
+// titleSimilarity compares two titles between 0 and 1.  It averages the overlap of their words, which forgives
+// reordering and extra words like episode numbers, with their edit distance, which forgives typos.
+func titleSimilarity(a, b string) float64 {
+	a, b = normalizeTitle(a), normalizeTitle(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	return (wordOverlap(a, b) + editSimilarity(a, b)) / 2
+}
+
+// normalizeTitle lower cases the title and reduces it to letters and digits separated by single spaces
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// wordOverlap is the Dice coefficient of the word sets
+func wordOverlap(a, b string) float64 {
+	wordsA := map[string]bool{}
+	for _, w := range strings.Fields(a) {
+		wordsA[w] = true
+	}
+	wordsB := map[string]bool{}
+	for _, w := range strings.Fields(b) {
+		wordsB[w] = true
+	}
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	common := 0
+	for w := range wordsA {
+		if wordsB[w] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wordsA)+len(wordsB))
+}
+
+// editSimilarity is one minus the Levenshtein distance relative to the longer string
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
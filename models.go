@@ -0,0 +1,215 @@
+package listennotes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Podcast is the typed form of the podcast objects returned by the api.  Fields that are missing from a response,
+// e.g. PRO-only fields on a FREE plan, are left empty.
+type Podcast struct {
+	ID                    string      `json:"id"`
+	Title                 string      `json:"title"`
+	Publisher             string      `json:"publisher"`
+	Description           string      `json:"description"`
+	Image                 string      `json:"image"`
+	Thumbnail             string      `json:"thumbnail"`
+	Website               string      `json:"website"`
+	RSS                   string      `json:"rss"`
+	Email                 string      `json:"email"`
+	Language              string      `json:"language"`
+	Country               string      `json:"country"`
+	Type                  string      `json:"type"`
+	ListennotesURL        string      `json:"listennotes_url"`
+	ItunesID              int64       `json:"itunes_id"`
+	GenreIDs              []int       `json:"genre_ids"`
+	TotalEpisodes         int         `json:"total_episodes"`
+	ExplicitContent       bool        `json:"explicit_content"`
+	AudioLengthSec        int         `json:"audio_length_sec"`
+	UpdateFrequencyHours  int         `json:"update_frequency_hours"`
+	ListenScore           ListenScore `json:"listen_score"`
+	ListenScoreGlobalRank string      `json:"listen_score_global_rank"`
+	LatestEpisodeID       string      `json:"latest_episode_id"`
+	LatestPubDateMs       int64       `json:"latest_pub_date_ms"`
+	EarliestPubDateMs     int64       `json:"earliest_pub_date_ms"`
+	Episodes              []Episode   `json:"episodes,omitempty"`
+	NextEpisodePubDate    int64       `json:"next_episode_pub_date,omitempty"`
+}
+
+// Episode is the typed form of the episode objects returned by the api
+type Episode struct {
+	ID                string   `json:"id"`
+	Title             string   `json:"title"`
+	Description       string   `json:"description"`
+	Audio             string   `json:"audio"`
+	AudioLengthSec    int      `json:"audio_length_sec"`
+	Image             string   `json:"image"`
+	Thumbnail         string   `json:"thumbnail"`
+	Link              string   `json:"link"`
+	ListennotesURL    string   `json:"listennotes_url"`
+	PubDateMs         int64    `json:"pub_date_ms"`
+	ExplicitContent   bool     `json:"explicit_content"`
+	MaybeAudioInvalid bool     `json:"maybe_audio_invalid"`
+	Transcript        string   `json:"transcript,omitempty"`
+	Podcast           *Podcast `json:"podcast,omitempty"`
+}
+
+// ListenScore is the listen score of a podcast.  PRO plans get a number, the FREE plan gets a notice string instead,
+// which decodes to an unavailable score.
+type ListenScore struct {
+	score     int
+	available bool
+}
+
+// NewListenScore will create an available listen score
+func NewListenScore(score int) ListenScore {
+	return ListenScore{score: score, available: true}
+}
+
+// Value is the score, and whether the plan of the api key gives access to it
+func (s ListenScore) Value() (int, bool) {
+	return s.score, s.available
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting a number, a numeric string, or any other string or null as an
+// unavailable score
+func (s *ListenScore) UnmarshalJSON(data []byte) error {
+	*s = ListenScore{}
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if score, err := strconv.Atoi(strings.TrimSpace(text)); err == nil {
+			*s = NewListenScore(score)
+		}
+		return nil
+	}
+
+	var score float64
+	if err := json.Unmarshal(data, &score); err != nil {
+		return fmt.Errorf("failed to parse listen score %s: %w", data, err)
+	}
+	*s = NewListenScore(int(score))
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, writing an unavailable score as null
+func (s ListenScore) MarshalJSON() ([]byte, error) {
+	if !s.available {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Itoa(s.score)), nil
+}
+
+// Audience is the typed form of the FetchAudienceForPodcast response
+type Audience struct {
+	ByRegions []RegionRatio `json:"by_regions"`
+}
+
+// RegionRatio is the share of a podcast's audience in a region
+type RegionRatio struct {
+	// Region is the lower case country code, e.g. "us"
+	Region string
+	// Percent is the share of the audience in percent, "5.90%" is 5.9
+	Percent float64
+}
+
+// Fraction is the share of the audience between 0 and 1
+func (r RegionRatio) Fraction() float64 {
+	return r.Percent / 100
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing the ratio percentage strings of the api
+func (r *RegionRatio) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Region string          `json:"region"`
+		Ratio  json.RawMessage `json:"ratio"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	r.Region = raw.Region
+	r.Percent = 0
+	if len(raw.Ratio) == 0 || string(raw.Ratio) == "null" {
+		return nil
+	}
+
+	var ratio string
+	if err := json.Unmarshal(raw.Ratio, &ratio); err != nil {
+		// a bare number is taken as a percentage as well
+		return json.Unmarshal(raw.Ratio, &r.Percent)
+	}
+	percent, err := ParseRatio(ratio)
+	if err != nil {
+		return err
+	}
+	r.Percent = percent
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, writing the ratio back in the format of the api
+func (r RegionRatio) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Region string `json:"region"`
+		Ratio  string `json:"ratio"`
+	}{r.Region, strconv.FormatFloat(r.Percent, 'f', 2, 64) + "%"})
+}
+
+// ParseRatio parses an audience ratio like "5.90%" into its percentage, 5.9
+func ParseRatio(ratio string) (float64, error) {
+	trimmed := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(ratio), "%"))
+	percent, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse audience ratio %q: %w", ratio, err)
+	}
+	return percent, nil
+}
+
+// PubDate is the publish date of the episode
+func (e Episode) PubDate() time.Time {
+	return msToTime(e.PubDateMs)
+}
+
+// AudioLength is the length of the episode audio
+func (e Episode) AudioLength() time.Duration {
+	return time.Duration(e.AudioLengthSec) * time.Second
+}
+
+// LatestPubDate is the publish date of the latest episode
+func (p Podcast) LatestPubDate() time.Time {
+	return msToTime(p.LatestPubDateMs)
+}
+
+// EarliestPubDate is the publish date of the earliest episode
+func (p Podcast) EarliestPubDate() time.Time {
+	return msToTime(p.EarliestPubDateMs)
+}
+
+// Decode will decode the response data into v, e.g. a *Podcast for FetchPodcastByID, in the same way
+// encoding/json.Unmarshal would decode the raw response body.
+func (r *Response) Decode(v interface{}) error {
+	if r == nil {
+		return fmt.Errorf("failed to decode nil response")
+	}
+	raw, err := json.Marshal(r.Data)
+	if err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+func msToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
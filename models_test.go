@@ -0,0 +1,108 @@
+package listennotes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestResponseDecode(t *testing.T) {
+	resp := &Response{
+		Data: map[string]interface{}{
+			"id":        "abc",
+			"title":     "A podcast",
+			"genre_ids": []interface{}{float64(68), float64(82)},
+			"episodes": []interface{}{
+				map[string]interface{}{"id": "e1", "pub_date_ms": float64(1478764802349), "audio_length_sec": float64(651)},
+			},
+		},
+	}
+
+	var podcast Podcast
+	if err := resp.Decode(&podcast); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if podcast.ID != "abc" || len(podcast.GenreIDs) != 2 || len(podcast.Episodes) != 1 {
+		t.Errorf("Podcast was not decoded as expected: %+v", podcast)
+	}
+
+	episode := podcast.Episodes[0]
+	if episode.PubDate().UTC().Year() != 2016 || episode.AudioLength() != 651*time.Second {
+		t.Errorf("Episode was not decoded as expected: %+v", episode)
+	}
+
+	var nilResp *Response
+	if err := nilResp.Decode(&podcast); err == nil {
+		t.Errorf("Expected an error decoding a nil response")
+	}
+}
+
+func TestAudienceDecode(t *testing.T) {
+	resp := &Response{
+		Data: map[string]interface{}{
+			"by_regions": []interface{}{
+				map[string]interface{}{"region": "us", "ratio": "52.53%"},
+				map[string]interface{}{"region": "gb", "ratio": "5.90%"},
+			},
+		},
+	}
+
+	var audience Audience
+	if err := resp.Decode(&audience); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if len(audience.ByRegions) != 2 || audience.ByRegions[1].Region != "gb" || audience.ByRegions[1].Percent != 5.9 {
+		t.Errorf("Audience was not decoded as expected: %+v", audience)
+	}
+	if f := audience.ByRegions[0].Fraction(); f < 0.5252 || f > 0.5254 {
+		t.Errorf("Unexpected fraction: %v", f)
+	}
+
+	bad := &Response{Data: map[string]interface{}{"by_regions": []interface{}{map[string]interface{}{"ratio": "n/a"}}}}
+	if err := bad.Decode(&audience); err == nil {
+		t.Error("Expected an error for an invalid ratio")
+	}
+}
+
+func TestFreePlanDecode(t *testing.T) {
+	// on the FREE plan PRO-only fields hold a notice instead of their value
+	fixture := `{
+		"id": "e1",
+		"title": "An episode",
+		"podcast": {
+			"id": "abc",
+			"title": "A podcast",
+			"listen_score": "Please upgrade to PRO or ENTERPRISE plan to see Listen Score",
+			"listen_score_global_rank": "Please upgrade to PRO or ENTERPRISE plan to see Listen Score"
+		}
+	}`
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(fixture), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	var episode Episode
+	if err := (&Response{Data: data}).Decode(&episode); err != nil {
+		t.Fatalf("Expected a FREE plan response to decode but got: %s", err)
+	}
+	if score, ok := episode.Podcast.ListenScore.Value(); ok || score != 0 {
+		t.Errorf("Expected an unavailable listen score but got %d", score)
+	}
+	if episode.Podcast.Title != "A podcast" {
+		t.Errorf("Podcast was not decoded as expected: %+v", episode.Podcast)
+	}
+
+	var podcast Podcast
+	if err := (&Response{Data: map[string]interface{}{"listen_score": float64(81)}}).Decode(&podcast); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if score, ok := podcast.ListenScore.Value(); !ok || score != 81 {
+		t.Errorf("Expected a listen score of 81 but got %d", score)
+	}
+
+	for score, expected := range map[ListenScore]string{NewListenScore(81): "81", {}: "null"} {
+		if data, _ := json.Marshal(score); string(data) != expected {
+			t.Errorf("Expected %s but got %s", expected, data)
+		}
+	}
+}
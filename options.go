@@ -2,6 +2,7 @@ package listennotes
 
 import (
 	"net/http"
+	"time"
 )
 
 // ClientOption allows for options to be passed to the client constructor function
@@ -21,3 +22,38 @@ func WithBaseURL(baseURL string) ClientOption {
 		c.baseURL = baseURL
 	}
 }
+
+// WithRequestCoalescing collapses identical GET requests that are in flight at the same time into a single upstream
+// call, keyed by path and arguments.  Every caller gets the shared result, including any error.  The response is shared
+// as well, so callers should treat its Data as read only.  A waiter stops waiting after the timeout of the http client,
+// the bound its own request would have had, without cancelling the shared call for the others.
+func WithRequestCoalescing() ClientOption {
+	return func(c *standardHTTPClient) {
+		c.inflight = newRequestGroup()
+	}
+}
+
+// WithKeyPool spreads requests across the keys of the pool instead of the single apiKey given to NewClient.  Keep a
+// reference to the pool to read its per key usage through KeyPool.Snapshot.  WithKeyPool and WithCredentials both
+// decide which key a request uses, the last of them passed to NewClient wins.
+func WithKeyPool(pool *KeyPool) ClientOption {
+	return func(c *standardHTTPClient) {
+		c.keyPool = pool
+		c.credentials = nil
+	}
+}
+
+// WithCredentials fetches the api key from the provider instead of using the apiKey given to NewClient.  The key is
+// cached for DefaultCredentialsTTL, and dropped early when the api answers with ErrUnauthorized.
+func WithCredentials(provider CredentialProvider) ClientOption {
+	return WithCredentialsTTL(provider, DefaultCredentialsTTL)
+}
+
+// WithCredentialsTTL is WithCredentials with a custom cache duration for the key.  It replaces a key pool set by an
+// earlier WithKeyPool, see WithKeyPool.
+func WithCredentialsTTL(provider CredentialProvider, ttl time.Duration) ClientOption {
+	return func(c *standardHTTPClient) {
+		c.credentials = newCachedCredentials(provider, ttl)
+		c.keyPool = nil
+	}
+}
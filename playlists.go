@@ -0,0 +1,403 @@
+package listennotes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// PlaylistType selects which items FetchPlaylistByID returns
+type PlaylistType string
+
+// Playlist types
+const (
+	PlaylistEpisodeList PlaylistType = "episode_list"
+	PlaylistPodcastList PlaylistType = "podcast_list"
+)
+
+// PlaylistItemType is the kind of a playlist item
+type PlaylistItemType string
+
+// Playlist item types
+const (
+	PlaylistItemEpisode PlaylistItemType = "episode"
+	PlaylistItemPodcast PlaylistItemType = "podcast"
+)
+
+// Playlist visibilities
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+// Playlist is the typed form of the playlist objects returned by FetchPlaylistByID and FetchMyPlaylists
+type Playlist struct {
+	ID                  string       `json:"id"`
+	Name                string       `json:"name"`
+	Description         string       `json:"description"`
+	Image               string       `json:"image"`
+	Thumbnail           string       `json:"thumbnail"`
+	Visibility          string       `json:"visibility"`
+	ListennotesURL      string       `json:"listennotes_url"`
+	EpisodeCount        int          `json:"episode_count,omitempty"`
+	PodcastCount        int          `json:"podcast_count,omitempty"`
+	TotalAudioLengthSec int          `json:"total_audio_length_sec"`
+	Type                PlaylistType `json:"type,omitempty"`
+	// Total is the number of items of Type, Items only holds the current page
+	Total           int            `json:"total,omitempty"`
+	LastTimestampMs int64          `json:"last_timestamp_ms,omitempty"`
+	Items           []PlaylistItem `json:"items,omitempty"`
+}
+
+// PlaylistItem is an episode or a podcast added to a playlist
+type PlaylistItem struct {
+	ID        int64
+	Type      PlaylistItemType
+	Notes     string
+	AddedAtMs int64
+	// Episode is set for episode items
+	Episode *Episode
+	// Podcast is set for podcast items
+	Podcast *Podcast
+	// Status is "deleted" when the item was removed from the podcast database, Error explains why
+	Status string
+	Error  string
+}
+
+type playlistItemJSON struct {
+	ID        int64            `json:"id"`
+	Type      PlaylistItemType `json:"type"`
+	Notes     string           `json:"notes"`
+	AddedAtMs int64            `json:"added_at_ms"`
+	Data      json.RawMessage  `json:"data"`
+}
+
+type playlistItemStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AddedAt is when the item was added to the playlist
+func (i PlaylistItem) AddedAt() time.Time {
+	return msToTime(i.AddedAtMs)
+}
+
+// Deleted reports whether the episode or podcast was removed from the podcast database
+func (i PlaylistItem) Deleted() bool {
+	return i.Status == "deleted"
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the item data as an episode or a podcast depending on its type
+func (i *PlaylistItem) UnmarshalJSON(data []byte) error {
+	var raw playlistItemJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*i = PlaylistItem{ID: raw.ID, Type: raw.Type, Notes: raw.Notes, AddedAtMs: raw.AddedAtMs}
+	if len(raw.Data) == 0 || string(raw.Data) == "null" {
+		return nil
+	}
+
+	var status playlistItemStatus
+	if err := json.Unmarshal(raw.Data, &status); err != nil {
+		return err
+	}
+	i.Status = status.Status
+	i.Error = status.Error
+
+	switch raw.Type {
+	case PlaylistItemEpisode:
+		i.Episode = &Episode{}
+		return json.Unmarshal(raw.Data, i.Episode)
+	case PlaylistItemPodcast:
+		i.Podcast = &Podcast{}
+		return json.Unmarshal(raw.Data, i.Podcast)
+	default:
+		return fmt.Errorf("unknown playlist item type %q", raw.Type)
+	}
+}
+
+// MarshalJSON implements json.Marshaler, writing the item back in the format of the api
+func (i PlaylistItem) MarshalJSON() ([]byte, error) {
+	var data interface{}
+	switch {
+	case i.Deleted() || i.Error != "":
+		var id string
+		if i.Episode != nil {
+			id = i.Episode.ID
+		} else if i.Podcast != nil {
+			id = i.Podcast.ID
+		}
+		data = playlistItemStatus{ID: id, Status: i.Status, Error: i.Error}
+	case i.Episode != nil:
+		data = i.Episode
+	case i.Podcast != nil:
+		data = i.Podcast
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(playlistItemJSON{ID: i.ID, Type: i.Type, Notes: i.Notes, AddedAtMs: i.AddedAtMs, Data: raw})
+}
+
+// PlaylistItemIterator walks every item of a playlist, fetching pages with FetchPlaylistByID and following
+// last_timestamp_ms.  Use it like PodcastEpisodeIterator.
+type PlaylistItemIterator struct {
+	client     HTTPClient
+	playlistID string
+	args       map[string]string
+
+	playlist Playlist
+	page     []PlaylistItem
+	index    int
+	seen     int
+	cursor   int64
+	started  bool
+	done     bool
+	err      error
+}
+
+// NewPlaylistItemIterator will create an iterator over the items of the given type.  args are passed to every page
+// request.
+func NewPlaylistItemIterator(client HTTPClient, playlistID string, typ PlaylistType, args map[string]string) *PlaylistItemIterator {
+	pageArgs := map[string]string{}
+	for k, v := range args {
+		pageArgs[k] = v
+	}
+	pageArgs["type"] = string(typ)
+
+	return &PlaylistItemIterator{
+		client:     client,
+		playlistID: playlistID,
+		args:       pageArgs,
+		index:      -1,
+	}
+}
+
+// Next advances to the next item, fetching a new page when needed.  It returns false once every item has been
+// visited or an error happened.
+func (it *PlaylistItemIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.page) {
+		if it.done {
+			return false
+		}
+		if !it.fetch() {
+			return false
+		}
+	}
+	it.seen++
+	return true
+}
+
+// Item is the current item
+func (it *PlaylistItemIterator) Item() PlaylistItem {
+	if it.index < 0 || it.index >= len(it.page) {
+		return PlaylistItem{}
+	}
+	return it.page[it.index]
+}
+
+// Playlist is the playlist being walked, available after the first call to Next.  Its Items field only holds the
+// current page.
+func (it *PlaylistItemIterator) Playlist() Playlist {
+	return it.playlist
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *PlaylistItemIterator) Err() error {
+	return it.err
+}
+
+func (it *PlaylistItemIterator) fetch() bool {
+	args := map[string]string{}
+	for k, v := range it.args {
+		args[k] = v
+	}
+	if it.started {
+		args["last_timestamp_ms"] = strconv.FormatInt(it.cursor, 10)
+	}
+
+	resp, err := it.client.FetchPlaylistByID(it.playlistID, args)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	var playlist Playlist
+	if err := resp.Decode(&playlist); err != nil {
+		it.err = err
+		return false
+	}
+
+	// a page that does not move the cursor forward would loop forever
+	it.done = len(playlist.Items) == 0 || it.seen+len(playlist.Items) >= playlist.Total ||
+		(it.started && playlist.LastTimestampMs == it.cursor)
+
+	it.started = true
+	it.playlist = playlist
+	it.page = playlist.Items
+	it.index = 0
+	it.cursor = playlist.LastTimestampMs
+	return true
+}
+
+// PlaylistIterator walks every playlist of the api key owner, fetching pages with FetchMyPlaylists.  Use it like
+// PodcastEpisodeIterator.
+type PlaylistIterator struct {
+	client HTTPClient
+	args   map[string]string
+
+	page     []Playlist
+	index    int
+	nextPage int
+	err      error
+}
+
+type playlistsPage struct {
+	Playlists      []Playlist `json:"playlists"`
+	HasNext        bool       `json:"has_next"`
+	NextPageNumber int        `json:"next_page_number"`
+}
+
+// NewPlaylistIterator will create an iterator over the playlists.  args are passed to every page request, e.g.
+// "sort" to order the playlists by name.
+func NewPlaylistIterator(client HTTPClient, args map[string]string) *PlaylistIterator {
+	return &PlaylistIterator{
+		client:   client,
+		args:     args,
+		index:    -1,
+		nextPage: 1,
+	}
+}
+
+// Next advances to the next playlist, fetching a new page when needed.  It returns false once every playlist has been
+// visited or an error happened.
+func (it *PlaylistIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.page) {
+		if it.nextPage == 0 {
+			return false
+		}
+		if !it.fetch() {
+			return false
+		}
+	}
+	return true
+}
+
+// Playlist is the current playlist, without items
+func (it *PlaylistIterator) Playlist() Playlist {
+	if it.index < 0 || it.index >= len(it.page) {
+		return Playlist{}
+	}
+	return it.page[it.index]
+}
+
+// Err is the error that stopped the iteration, if any
+func (it *PlaylistIterator) Err() error {
+	return it.err
+}
+
+func (it *PlaylistIterator) fetch() bool {
+	args := map[string]string{}
+	for k, v := range it.args {
+		args[k] = v
+	}
+	args["page"] = strconv.Itoa(it.nextPage)
+
+	resp, err := it.client.FetchMyPlaylists(args)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	var page playlistsPage
+	if err := resp.Decode(&page); err != nil {
+		it.err = err
+		return false
+	}
+
+	next := 0
+	if page.HasNext && page.NextPageNumber > it.nextPage {
+		next = page.NextPageNumber
+	}
+	it.nextPage = next
+	it.page = page.Playlists
+	it.index = 0
+	return true
+}
+
+// PlaylistLibrary is a backup of every playlist of the api key owner with all their items
+type PlaylistLibrary struct {
+	ExportedAt time.Time        `json:"exported_at"`
+	Playlists  []PlaylistBackup `json:"playlists"`
+}
+
+// PlaylistBackup is a single playlist of a PlaylistLibrary
+type PlaylistBackup struct {
+	Playlist
+	Episodes []PlaylistItem `json:"episodes"`
+	Podcasts []PlaylistItem `json:"podcasts"`
+}
+
+// FetchPlaylistLibrary walks every playlist with both its episode and podcast items
+func FetchPlaylistLibrary(client HTTPClient) (*PlaylistLibrary, error) {
+	library := &PlaylistLibrary{ExportedAt: time.Now().UTC(), Playlists: []PlaylistBackup{}}
+
+	playlists := NewPlaylistIterator(client, nil)
+	for playlists.Next() {
+		backup := PlaylistBackup{Playlist: playlists.Playlist(), Episodes: []PlaylistItem{}, Podcasts: []PlaylistItem{}}
+		for _, typ := range []PlaylistType{PlaylistEpisodeList, PlaylistPodcastList} {
+			if (typ == PlaylistEpisodeList && backup.EpisodeCount == 0) || (typ == PlaylistPodcastList && backup.PodcastCount == 0) {
+				continue
+			}
+			items := NewPlaylistItemIterator(client, backup.ID, typ, nil)
+			for items.Next() {
+				if typ == PlaylistEpisodeList {
+					backup.Episodes = append(backup.Episodes, items.Item())
+				} else {
+					backup.Podcasts = append(backup.Podcasts, items.Item())
+				}
+			}
+			if err := items.Err(); err != nil {
+				return nil, fmt.Errorf("failed to fetch items of playlist %s: %w", backup.ID, err)
+			}
+		}
+		library.Playlists = append(library.Playlists, backup)
+	}
+	if err := playlists.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch playlists: %w", err)
+	}
+	return library, nil
+}
+
+// ExportPlaylistLibrary writes a backup of every playlist, with all their items, to w as indented JSON
+func ExportPlaylistLibrary(client HTTPClient, w io.Writer) error {
+	library, err := FetchPlaylistLibrary(client)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(library); err != nil {
+		return fmt.Errorf("failed to write playlist library: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package listennotes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPlaylistItemIterator(t *testing.T) {
+	pages := map[string]string{
+		"": `{"id": "p1", "type": "episode_list", "visibility": "public", "total": 3, "last_timestamp_ms": 200, "items": [
+			{"id": 1, "type": "episode", "notes": "great", "added_at_ms": 300, "data": {"id": "e1", "title": "One", "podcast": {"id": "c1"}}},
+			{"id": 2, "type": "episode", "added_at_ms": 200, "data": {"id": "e2", "status": "deleted", "error": "gone"}}
+		]}`,
+		"200": `{"id": "p1", "type": "episode_list", "total": 3, "last_timestamp_ms": 100, "items": [
+			{"id": 3, "type": "episode", "added_at_ms": 100, "data": {"id": "e3"}}
+		]}`,
+	}
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("last_timestamp_ms")
+		requests = append(requests, cursor)
+		if r.URL.Query().Get("type") != "episode_list" {
+			t.Errorf("type was not passed to every page: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(pages[cursor]))
+	}))
+	defer ts.Close()
+
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL))
+	it := NewPlaylistItemIterator(client, "p1", PlaylistEpisodeList, nil)
+
+	var items []PlaylistItem
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if len(items) != 3 || len(requests) != 2 {
+		t.Fatalf("Expected 3 items in 2 pages but got %v in %v", items, requests)
+	}
+	if first := items[0]; first.Episode == nil || first.Episode.Title != "One" || first.Episode.Podcast.ID != "c1" ||
+		first.Podcast != nil || first.Notes != "great" {
+		t.Errorf("Episode item was not decoded as expected: %+v", first)
+	}
+	if !items[1].Deleted() || items[1].Error != "gone" || items[1].Episode.ID != "e2" {
+		t.Errorf("Deleted item was not decoded as expected: %+v", items[1])
+	}
+	if it.Playlist().ID != "p1" {
+		t.Errorf("Playlist was not as expected: %+v", it.Playlist())
+	}
+}
+
+func TestPlaylistItemJSON(t *testing.T) {
+	raw := `{"id": 7, "type": "podcast", "notes": "", "added_at_ms": 1634779096596, "data": {"id": "c1", "title": "A podcast"}}`
+	var item PlaylistItem
+	if err := json.Unmarshal([]byte(raw), &item); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if item.Podcast == nil || item.Podcast.Title != "A podcast" || item.Episode != nil || item.AddedAt().UTC().Year() != 2021 {
+		t.Errorf("Podcast item was not decoded as expected: %+v", item)
+	}
+
+	encoded, _ := json.Marshal(item)
+	var again PlaylistItem
+	if err := json.Unmarshal(encoded, &again); err != nil || again.Podcast.Title != "A podcast" || again.ID != 7 {
+		t.Errorf("Item did not survive a round trip: %s", encoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"type": "song", "data": {}}`), &item); err == nil {
+		t.Error("Expected an error for an unknown item type")
+	}
+}
+
+func TestExportPlaylistLibrary(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/playlists" && q.Get("page") == "1":
+			w.Write([]byte(`{"has_next": true, "next_page_number": 2, "playlists": [{"id": "p1", "episode_count": 1}]}`))
+		case r.URL.Path == "/playlists" && q.Get("page") == "2":
+			w.Write([]byte(`{"has_next": false, "playlists": [{"id": "p2", "podcast_count": 1}]}`))
+		case r.URL.Path == "/playlists/p1" && q.Get("type") == "episode_list":
+			w.Write([]byte(`{"id": "p1", "total": 1, "items": [{"id": 1, "type": "episode", "data": {"id": "e1"}}]}`))
+		case r.URL.Path == "/playlists/p2" && q.Get("type") == "podcast_list":
+			w.Write([]byte(`{"id": "p2", "total": 1, "items": [{"id": 2, "type": "podcast", "data": {"id": "c1"}}]}`))
+		default:
+			t.Errorf("Unexpected request: %s", r.URL)
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient("", WithHTTPClient(http.DefaultClient), WithBaseURL(ts.URL))
+	var buf bytes.Buffer
+	if err := ExportPlaylistLibrary(client, &buf); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	var library PlaylistLibrary
+	if err := json.Unmarshal(buf.Bytes(), &library); err != nil {
+		t.Fatalf("Expected valid json but got %s: %s", err, buf.String())
+	}
+	if len(library.Playlists) != 2 || library.Playlists[0].Episodes[0].Episode.ID != "e1" ||
+		library.Playlists[1].Podcasts[0].Podcast.ID != "c1" || len(library.Playlists[1].Episodes) != 0 {
+		t.Errorf("Library was not as expected: %s", buf.String())
+	}
+}
@@ -0,0 +1,28 @@
+package proxy
+
+import (
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/lru"
+)
+
+// responseCache is a small in-memory LRU cache of upstream responses with a fixed ttl
+type responseCache struct {
+	lru *lru.Cache
+}
+
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	return &responseCache{lru: lru.New(ttl, maxEntries, time.Now)}
+}
+
+func (c *responseCache) get(key string) *listennotes.Response {
+	if value, ok := c.lru.Get(key); ok {
+		return value.(*listennotes.Response)
+	}
+	return nil
+}
+
+func (c *responseCache) set(key string, resp *listennotes.Response) {
+	c.lru.Set(key, resp)
+}
@@ -0,0 +1,137 @@
+package proxy
+
+import (
+	"regexp"
+	"strings"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type callFunc func(client listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error)
+
+// Endpoint is a read endpoint that the proxy is able to forward.  The pattern is the path relative to the api base url,
+// with `{id}` marking the single path parameter (podcast id, episode id, domain name, etc...).
+type Endpoint struct {
+	Pattern string
+	call    callFunc
+}
+
+// Read endpoints that can be placed on the proxy allow-list
+var (
+	Search = Endpoint{"search", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.Search(args)
+	}}
+	Typeahead = Endpoint{"typeahead", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.Typeahead(args)
+	}}
+	SearchEpisodeTitles = Endpoint{"search_episode_titles", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.SearchEpisodeTitles(args)
+	}}
+	SpellCheck = Endpoint{"spellcheck", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.SpellCheck(args)
+	}}
+	RelatedSearches = Endpoint{"related_searches", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchRelatedSearches(args)
+	}}
+	TrendingSearches = Endpoint{"trending_searches", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchTrendingSearches(args)
+	}}
+	BestPodcasts = Endpoint{"best_podcasts", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchBestPodcasts(args)
+	}}
+	PodcastsByDomain = Endpoint{"podcasts/domains/{id}", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchPodcastsByDomain(id, args)
+	}}
+	PodcastRecommendations = Endpoint{"podcasts/{id}/recommendations", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchRecommendationsForPodcast(id, args)
+	}}
+	PodcastAudience = Endpoint{"podcasts/{id}/audience", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchAudienceForPodcast(id, args)
+	}}
+	Podcast = Endpoint{"podcasts/{id}", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchPodcastByID(id, args)
+	}}
+	EpisodeRecommendations = Endpoint{"episodes/{id}/recommendations", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchRecommendationsForEpisode(id, args)
+	}}
+	Episode = Endpoint{"episodes/{id}", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchEpisodeByID(id, args)
+	}}
+	CuratedPodcastsList = Endpoint{"curated_podcasts/{id}", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchCuratedPodcastsListByID(id, args)
+	}}
+	CuratedPodcastsLists = Endpoint{"curated_podcasts", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchCuratedPodcastsLists(args)
+	}}
+	Genres = Endpoint{"genres", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchPodcastGenres(args)
+	}}
+	Regions = Endpoint{"regions", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchPodcastRegions(args)
+	}}
+	Languages = Endpoint{"languages", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchPodcastLanguages(args)
+	}}
+	JustListen = Endpoint{"just_listen", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.JustListen(args)
+	}}
+	Playlist = Endpoint{"playlists/{id}", func(c listennotes.HTTPClient, id string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchPlaylistByID(id, args)
+	}}
+	Playlists = Endpoint{"playlists", func(c listennotes.HTTPClient, _ string, args map[string]string) (*listennotes.Response, error) {
+		return c.FetchMyPlaylists(args)
+	}}
+)
+
+// DefaultEndpoints is the allow-list used when none is configured.  It contains the public read endpoints, but not the
+// playlist endpoints since those expose the playlists of the account that owns the api key.
+var DefaultEndpoints = []Endpoint{
+	Search,
+	Typeahead,
+	SearchEpisodeTitles,
+	SpellCheck,
+	RelatedSearches,
+	TrendingSearches,
+	BestPodcasts,
+	PodcastsByDomain,
+	PodcastRecommendations,
+	PodcastAudience,
+	Podcast,
+	EpisodeRecommendations,
+	Episode,
+	CuratedPodcastsList,
+	CuratedPodcastsLists,
+	Genres,
+	Regions,
+	Languages,
+	JustListen,
+}
+
+// validID matches the ids and domains the api takes in paths.  Anything else, e.g. a `?` or `/` sent escaped, would
+// change the upstream url.
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// match checks the escaped path against the endpoint pattern and returns the value of the `{id}` segment, if any.
+// Ids with characters the api does not use in ids are rejected.
+func (e Endpoint) match(path string) (string, bool) {
+	patternParts := strings.Split(e.Pattern, "/")
+	pathParts := strings.Split(path, "/")
+	if len(patternParts) != len(pathParts) {
+		return "", false
+	}
+
+	id := ""
+	for i, part := range patternParts {
+		if part == "{id}" {
+			if !validID.MatchString(pathParts[i]) {
+				return "", false
+			}
+			id = pathParts[i]
+			continue
+		}
+		if part != pathParts[i] {
+			return "", false
+		}
+	}
+	return id, true
+}
@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option allows for options to be passed to the handler constructor function
+type Option func(h *Handler)
+
+// WithEndpoints replaces the allow-list of endpoints that will be forwarded.  If not provided DefaultEndpoints is used.
+func WithEndpoints(endpoints ...Endpoint) Option {
+	return func(h *Handler) {
+		h.endpoints = endpoints
+	}
+}
+
+// WithRateLimit limits every caller to requestsPerSecond, allowing bursts of up to burst requests.  Callers going over
+// the limit get a 429 without a request being sent upstream.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(h *Handler) {
+		h.limiter = newRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithCallerFunc allows identifying callers for rate limiting, e.g. by a session or user id.  If not provided the
+// remote address of the request is used.
+func WithCallerFunc(callerFunc func(r *http.Request) string) Option {
+	return func(h *Handler) {
+		h.callerFunc = callerFunc
+	}
+}
+
+// WithCache caches successful upstream responses in memory for ttl.  maxEntries bounds the cache size, the least
+// recently used response is evicted first.  Zero means no bound.
+func WithCache(ttl time.Duration, maxEntries int) Option {
+	return func(h *Handler) {
+		h.cache = newResponseCache(ttl, maxEntries)
+	}
+}
+
+// WithTierFunc allows deciding the tier of each caller.  If not provided every caller is treated as TierFree.
+func WithTierFunc(tierFunc TierFunc) Option {
+	return func(h *Handler) {
+		h.tierFunc = tierFunc
+	}
+}
+
+// WithProFields replaces the fields that are stripped from responses for TierFree callers.  If not provided
+// DefaultProFields is used.
+func WithProFields(fields ...string) Option {
+	return func(h *Handler) {
+		h.proFields = toSet(fields)
+	}
+}
+
+// WithAllowedOrigins sets the origins that receive CORS headers.  Use "*" to allow any origin.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(h *Handler) {
+		h.allowedOrigins = toSet(origins)
+	}
+}
+
+// WithUsageHeaders passes the X-ListenAPI-* usage headers of the upstream response through to callers.  They are
+// withheld by default since they describe the quota of the account that owns the api key.
+func WithUsageHeaders() Option {
+	return func(h *Handler) {
+		h.usageHeaders = true
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,225 @@
+// Package proxy provides an http.Handler that forwards browser and mobile requests to the listennotes API through a
+// server side client, so that the api key never leaves the server.
+//
+// Only endpoints on an allow-list are forwarded.  The handler can apply per caller rate limits, cache responses and
+// strip PRO-only fields for callers whose tier does not allow them.
+//
+// Mount it under a prefix with http.StripPrefix, e.g.:
+//
+//	client := listennotes.NewClient(os.Getenv("LISTEN_API_KEY"))
+//	http.Handle("/api/", http.StripPrefix("/api", proxy.New(client, proxy.WithAllowedOrigins("https://example.com"))))
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Handler forwards allow-listed read requests through a listennotes client
+type Handler struct {
+	client         listennotes.HTTPClient
+	endpoints      []Endpoint
+	limiter        *rateLimiter
+	callerFunc     func(r *http.Request) string
+	cache          *responseCache
+	tierFunc       TierFunc
+	proFields      map[string]bool
+	allowedOrigins map[string]bool
+	usageHeaders   bool
+}
+
+var _ http.Handler = &Handler{}
+
+// New will create a handler forwarding to the client.  The client carries the api key, callers never need one.
+// You can optionally override some configuration.
+func New(client listennotes.HTTPClient, opts ...Option) *Handler {
+	h := &Handler{
+		client:     client,
+		endpoints:  DefaultEndpoints,
+		callerFunc: remoteAddr,
+		tierFunc:   func(*http.Request) Tier { return TierFree },
+		proFields:  toSet(DefaultProFields),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w, r)
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	path := strings.Trim(r.URL.EscapedPath(), "/")
+	endpoint, id, ok := h.route(path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "endpoint is not available")
+		return
+	}
+
+	if h.limiter != nil {
+		if allowed, wait := h.limiter.allow(h.callerFunc(r)); !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, "too many requests")
+			return
+		}
+	}
+
+	args := map[string]string{}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			args[k] = v[0]
+		}
+	}
+
+	key := cacheKey(path, args)
+	var resp *listennotes.Response
+	if h.cache != nil {
+		resp = h.cache.get(key)
+	}
+	if resp == nil {
+		var err error
+		resp, err = endpoint.call(h.client, id, args)
+		if err != nil {
+			writeError(w, statusForError(err), err.Error())
+			return
+		}
+		if h.cache != nil {
+			h.cache.set(key, resp)
+		}
+	}
+
+	var data interface{} = resp.Data
+	if h.tierFunc(r) != TierPro && len(h.proFields) > 0 {
+		data = stripFields(resp.Data, h.proFields)
+	}
+
+	if h.usageHeaders {
+		setUsageHeaders(w, resp.Stats)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+func (h *Handler) route(path string) (Endpoint, string, bool) {
+	for _, endpoint := range h.endpoints {
+		if id, ok := endpoint.match(path); ok {
+			return endpoint, id, true
+		}
+	}
+	return Endpoint{}, "", false
+}
+
+func (h *Handler) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(h.allowedOrigins) == 0 {
+		return
+	}
+
+	header := w.Header()
+	switch {
+	case h.allowedOrigins["*"]:
+		header.Set("Access-Control-Allow-Origin", "*")
+	case h.allowedOrigins[origin]:
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	default:
+		return
+	}
+
+	header.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+	if h.usageHeaders {
+		header.Set("Access-Control-Expose-Headers", strings.Join([]string{
+			listennotes.ResponseHeaderKeyFreeQuota,
+			listennotes.ResponseHeaderKeyUsage,
+			listennotes.ResponseHeaderKeyLatencySeconds,
+			listennotes.ResponseHeaderKeyNextBillingDate,
+		}, ", "))
+	}
+}
+
+func setUsageHeaders(w http.ResponseWriter, stats listennotes.ResponseStatistics) {
+	header := w.Header()
+	header.Set(listennotes.ResponseHeaderKeyFreeQuota, strconv.Itoa(stats.FreeQuota))
+	header.Set(listennotes.ResponseHeaderKeyUsage, strconv.Itoa(stats.Usage))
+	header.Set(listennotes.ResponseHeaderKeyLatencySeconds, strconv.FormatFloat(stats.LatencySeconds, 'f', -1, 64))
+	if !stats.NextBillingDate.IsZero() {
+		header.Set(listennotes.ResponseHeaderKeyNextBillingDate, stats.NextBillingDate.Format(listennotes.TimeFormat))
+	}
+}
+
+// statusForError maps client errors to the status returned to callers.  Errors the caller cannot act on, like a bad
+// api key on the server, are reported as a bad gateway.
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, listennotes.ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, listennotes.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, listennotes.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func remoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func cacheKey(path string, args map[string]string) string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(path)
+	for i, k := range keys {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(k))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(args[k]))
+	}
+	return b.String()
+}
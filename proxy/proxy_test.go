@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	calls  int
+	lastID string
+	err    error
+}
+
+func (c *fakeClient) FetchPodcastByID(id string, args map[string]string) (*listennotes.Response, error) {
+	c.calls++
+	c.lastID = id
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &listennotes.Response{
+		Stats: listennotes.ResponseStatistics{FreeQuota: 300, Usage: 12},
+		Data: map[string]interface{}{
+			"id":  id,
+			"rss": "https://example.com/rss",
+			"episodes": []interface{}{
+				map[string]interface{}{"id": "e1", "transcript": "hello"},
+			},
+		},
+	}, nil
+}
+
+func (c *fakeClient) FetchMyPlaylists(args map[string]string) (*listennotes.Response, error) {
+	c.calls++
+	return &listennotes.Response{Data: map[string]interface{}{}}, nil
+}
+
+func serve(h http.Handler, method string, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestForwardAndStripProFields(t *testing.T) {
+	client := &fakeClient{}
+	h := New(client)
+
+	w := serve(h, "GET", "/podcasts/abc", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if client.lastID != "abc" {
+		t.Errorf("Wrong id forwarded: %s", client.lastID)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &data); err != nil {
+		t.Fatalf("Invalid json: %s", err)
+	}
+	if _, ok := data["rss"]; ok {
+		t.Errorf("rss should have been stripped for free tier")
+	}
+	episode := data["episodes"].([]interface{})[0].(map[string]interface{})
+	if _, ok := episode["transcript"]; ok {
+		t.Errorf("nested transcript should have been stripped for free tier")
+	}
+	if w.Header().Get(listennotes.ResponseHeaderKeyUsage) != "" {
+		t.Errorf("usage headers should not be passed through by default")
+	}
+}
+
+func TestProTierAndUsageHeaders(t *testing.T) {
+	h := New(&fakeClient{},
+		WithTierFunc(func(*http.Request) Tier { return TierPro }),
+		WithUsageHeaders(),
+	)
+
+	w := serve(h, "GET", "/podcasts/abc", nil)
+	if !strings.Contains(w.Body.String(), "rss") {
+		t.Errorf("rss should be returned for pro tier: %s", w.Body.String())
+	}
+	if v := w.Header().Get(listennotes.ResponseHeaderKeyUsage); v != "12" {
+		t.Errorf("usage header was not passed through: %s", v)
+	}
+}
+
+func TestAllowList(t *testing.T) {
+	client := &fakeClient{}
+	h := New(client)
+
+	if w := serve(h, "GET", "/playlists", nil); w.Code != http.StatusNotFound {
+		t.Errorf("playlists should not be on the default allow-list: %d", w.Code)
+	}
+	if w := serve(h, "POST", "/podcasts/abc", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("only GET should be forwarded: %d", w.Code)
+	}
+
+	h = New(client, WithEndpoints(Playlists))
+	if w := serve(h, "GET", "/playlists", nil); w.Code != http.StatusOK {
+		t.Errorf("playlists should be allowed: %d", w.Code)
+	}
+	if w := serve(h, "GET", "/podcasts/abc", nil); w.Code != http.StatusNotFound {
+		t.Errorf("podcasts should not be allowed: %d", w.Code)
+	}
+}
+
+func TestEscapedIDs(t *testing.T) {
+	client := &fakeClient{}
+	h := New(client)
+
+	for _, path := range []string{"/podcasts/abc%3Fpage=2", "/podcasts/abc%2Fepisodes", "/podcasts/..", "/podcasts/%2E%2E"} {
+		if w := serve(h, "GET", path, nil); w.Code != http.StatusNotFound {
+			t.Errorf("%s should be rejected: %d", path, w.Code)
+		}
+	}
+	if client.calls != 0 {
+		t.Errorf("Rejected ids should not reach the client: %s", client.lastID)
+	}
+
+	if w := serve(h, "GET", "/podcasts/4d3fe717742d4963a85562e9f84d8c79", nil); w.Code != http.StatusOK ||
+		client.lastID != "4d3fe717742d4963a85562e9f84d8c79" {
+		t.Errorf("A valid id should be forwarded: %d %s", w.Code, client.lastID)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	h := New(&fakeClient{err: listennotes.ErrNotFound})
+	if w := serve(h, "GET", "/podcasts/abc", nil); w.Code != http.StatusNotFound {
+		t.Errorf("not found should be passed through: %d", w.Code)
+	}
+
+	h = New(&fakeClient{err: listennotes.ErrUnauthorized})
+	if w := serve(h, "GET", "/podcasts/abc", nil); w.Code != http.StatusBadGateway {
+		t.Errorf("unauthorized should be a bad gateway: %d", w.Code)
+	}
+}
+
+func TestCache(t *testing.T) {
+	client := &fakeClient{}
+	h := New(client, WithCache(time.Minute, 10))
+
+	serve(h, "GET", "/podcasts/abc?a=1&b=2", nil)
+	serve(h, "GET", "/podcasts/abc?b=2&a=1", nil)
+	if client.calls != 1 {
+		t.Errorf("Expected a single upstream call but got %d", client.calls)
+	}
+
+	serve(h, "GET", "/podcasts/abc?a=2", nil)
+	if client.calls != 2 {
+		t.Errorf("Different args should not be cached together: %d", client.calls)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	h := New(&fakeClient{}, WithRateLimit(1, 2))
+
+	now := time.Now()
+	h.limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if w := serve(h, "GET", "/podcasts/abc", nil); w.Code != http.StatusOK {
+			t.Errorf("Request %d should be within burst: %d", i, w.Code)
+		}
+	}
+	w := serve(h, "GET", "/podcasts/abc", nil)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Request should be rate limited: %d retry after %s", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	now = now.Add(time.Second)
+	if w := serve(h, "GET", "/podcasts/abc", nil); w.Code != http.StatusOK {
+		t.Errorf("Token should have been refilled: %d", w.Code)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	h := New(&fakeClient{}, WithAllowedOrigins("https://a.example"))
+
+	w := serve(h, "OPTIONS", "/podcasts/abc", map[string]string{"Origin": "https://a.example"})
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://a.example" {
+		t.Errorf("Preflight was not allowed: %d %v", w.Code, w.Header())
+	}
+
+	w = serve(h, "GET", "/podcasts/abc", map[string]string{"Origin": "https://b.example"})
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Unknown origin should not get CORS headers")
+	}
+}
@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"net/http"
+)
+
+// Tier is the access level of a proxy caller
+type Tier int
+
+// Available caller tiers
+const (
+	// TierFree callers do not see PRO-only fields
+	TierFree Tier = iota
+	// TierPro callers see the response as returned by the api
+	TierPro
+)
+
+// TierFunc decides the tier of the caller making the request
+type TierFunc func(r *http.Request) Tier
+
+// DefaultProFields are the response fields that are only returned to PRO plans by the api
+var DefaultProFields = []string{"rss", "email", "transcript"}
+
+// stripFields returns a copy of the data with the given keys removed at any depth.  The input is never modified, since
+// it may be shared through the response cache.
+func stripFields(data interface{}, fields map[string]bool) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		stripped := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if fields[key] {
+				continue
+			}
+			stripped[key] = stripFields(value, fields)
+		}
+		return stripped
+	case []interface{}:
+		stripped := make([]interface{}, len(v))
+		for i, value := range v {
+			stripped[i] = stripFields(value, fields)
+		}
+		return stripped
+	default:
+		return v
+	}
+}
@@ -0,0 +1,192 @@
+// Package recgraph explores the recommendation graph of podcasts and episodes.
+//
+// An Explorer crawls FetchRecommendationsForPodcast, or FetchRecommendationsForEpisode, breadth first from seeds up to
+// a depth and a budget of api calls, and builds a weighted Graph.  The graph answers questions about neighbors,
+// shortest paths, communities and shows recommended from many seeds, and exports to GraphML and DOT:
+//
+//	explorer := recgraph.NewExplorer(client, recgraph.WithMaxDepth(2), recgraph.WithBudget(200))
+//	graph, err := explorer.ExplorePodcasts(ctx, seedIDs)
+//	if err != nil {
+//		return err
+//	}
+//	for _, community := range graph.Communities() {
+//		fmt.Println(community)
+//	}
+//	graph.WriteGraphML(f)
+package recgraph
+
+import (
+	"context"
+	"fmt"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Stats describes how an exploration went
+type Stats struct {
+	Requests int
+	// Truncated is set when the budget ran out before the depth limit was reached
+	Truncated bool
+}
+
+// Explorer crawls recommendations breadth first
+type Explorer struct {
+	client   listennotes.HTTPClient
+	maxDepth int
+	budget   int
+	args     map[string]string
+
+	stats Stats
+}
+
+// NewExplorer will create an explorer with reasonable defaults.
+// You can optionally override some configuration.
+func NewExplorer(client listennotes.HTTPClient, opts ...Option) *Explorer {
+	e := &Explorer{
+		client:   client,
+		maxDepth: 2,
+		budget:   100,
+		args:     map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Stats returns the statistics of the last exploration
+func (e *Explorer) Stats() Stats {
+	return e.stats
+}
+
+// ExplorePodcasts crawls podcast recommendations from the seed podcast ids.  When the budget runs out the graph built
+// so far is returned and Stats reports it as truncated.  An api error or the context being done stops the crawl, the
+// partial graph is returned along with the error.
+func (e *Explorer) ExplorePodcasts(ctx context.Context, seeds []string) (*Graph, error) {
+	return e.explore(ctx, KindPodcast, seeds)
+}
+
+// ExploreEpisodes crawls episode recommendations from the seed episode ids, like ExplorePodcasts
+func (e *Explorer) ExploreEpisodes(ctx context.Context, seeds []string) (*Graph, error) {
+	return e.explore(ctx, KindEpisode, seeds)
+}
+
+type visit struct {
+	id    string
+	depth int
+}
+
+func (e *Explorer) explore(ctx context.Context, kind NodeKind, seeds []string) (*Graph, error) {
+	e.stats = Stats{}
+	graph := NewGraph()
+
+	// seedsOf tracks which seeds reach each node, nodes are re-expanded when a new seed reaches them
+	seedsOf := map[string][]string{}
+	expanded := map[string]bool{}
+	var queue []visit
+	for _, seed := range seeds {
+		graph.AddNode(Node{ID: seed, Kind: kind, Seeds: []string{seed}})
+		seedsOf[seed] = []string{seed}
+		queue = append(queue, visit{id: seed})
+	}
+
+	// recommendations already fetched, a node reached from a second seed only propagates the seed
+	fetched := map[string][]Node{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.depth >= e.maxDepth {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return graph, err
+		}
+
+		recs, ok := fetched[current.id]
+		if !ok {
+			if e.stats.Requests >= e.budget {
+				e.stats.Truncated = true
+				continue
+			}
+			var err error
+			recs, err = e.fetch(kind, current.id)
+			if err != nil {
+				return graph, err
+			}
+			fetched[current.id] = recs
+		}
+
+		for rank, rec := range recs {
+			rec.Depth = current.depth + 1
+			rec.Seeds = seedsOf[current.id]
+			graph.AddNode(rec)
+			graph.AddEdge(Edge{
+				From:   current.id,
+				To:     rec.ID,
+				Rank:   rank + 1,
+				Weight: float64(len(recs)-rank) / float64(len(recs)),
+			})
+
+			node, _ := graph.Node(rec.ID)
+			grew := len(node.Seeds) > len(seedsOf[rec.ID])
+			seedsOf[rec.ID] = node.Seeds
+			if !expanded[rec.ID] || grew {
+				expanded[rec.ID] = true
+				queue = append(queue, visit{id: rec.ID, depth: current.depth + 1})
+			}
+		}
+	}
+	return graph, nil
+}
+
+func (e *Explorer) fetch(kind NodeKind, id string) ([]Node, error) {
+	args := map[string]string{}
+	for k, v := range e.args {
+		args[k] = v
+	}
+
+	e.stats.Requests++
+	var resp *listennotes.Response
+	var err error
+	if kind == KindEpisode {
+		resp, err = e.client.FetchRecommendationsForEpisode(id, args)
+	} else {
+		resp, err = e.client.FetchRecommendationsForPodcast(id, args)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recommendations for %s: %w", id, err)
+	}
+
+	var recs struct {
+		Recommendations []listennotes.Episode `json:"recommendations"`
+	}
+	var podcasts struct {
+		Recommendations []listennotes.Podcast `json:"recommendations"`
+	}
+
+	var nodes []Node
+	if kind == KindEpisode {
+		if err := resp.Decode(&recs); err != nil {
+			return nil, err
+		}
+		for _, r := range recs.Recommendations {
+			node := Node{ID: r.ID, Kind: KindEpisode, Title: r.Title}
+			if r.Podcast != nil {
+				node.Publisher = r.Podcast.Publisher
+				node.ListenScore, _ = r.Podcast.ListenScore.Value()
+			}
+			nodes = append(nodes, node)
+		}
+	} else {
+		if err := resp.Decode(&podcasts); err != nil {
+			return nil, err
+		}
+		for _, r := range podcasts.Recommendations {
+			score, _ := r.ListenScore.Value()
+			nodes = append(nodes, Node{ID: r.ID, Kind: KindPodcast, Title: r.Title, Publisher: r.Publisher, ListenScore: score})
+		}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,98 @@
+package recgraph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteGraphML writes the graph as GraphML, with the node title, kind, publisher, listen score, depth and seed count
+// as node attributes and the rank and weight as edge attributes
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	keys := []struct{ id, target, name, typ string }{
+		{"title", "node", "title", "string"},
+		{"kind", "node", "kind", "string"},
+		{"publisher", "node", "publisher", "string"},
+		{"listen_score", "node", "listen_score", "int"},
+		{"depth", "node", "depth", "int"},
+		{"seeds", "node", "seeds", "int"},
+		{"rank", "edge", "rank", "int"},
+		{"weight", "edge", "weight", "double"},
+	}
+	for _, k := range keys {
+		fmt.Fprintf(bw, `  <key id="%s" for="%s" attr.name="%s" attr.type="%s"/>`+"\n", k.id, k.target, k.name, k.typ)
+	}
+	bw.WriteString(`  <graph id="recommendations" edgedefault="directed">` + "\n")
+
+	for _, n := range g.Nodes() {
+		fmt.Fprintf(bw, `    <node id="%s">`+"\n", escapeXML(n.ID))
+		writeData(bw, "title", n.Title)
+		writeData(bw, "kind", string(n.Kind))
+		writeData(bw, "publisher", n.Publisher)
+		writeData(bw, "listen_score", strconv.Itoa(n.ListenScore))
+		writeData(bw, "depth", strconv.Itoa(n.Depth))
+		writeData(bw, "seeds", strconv.Itoa(len(n.Seeds)))
+		bw.WriteString("    </node>\n")
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(bw, `    <edge source="%s" target="%s">`+"\n", escapeXML(e.From), escapeXML(e.To))
+		writeData(bw, "rank", strconv.Itoa(e.Rank))
+		writeData(bw, "weight", strconv.FormatFloat(e.Weight, 'f', 4, 64))
+		bw.WriteString("    </edge>\n")
+	}
+
+	bw.WriteString("  </graph>\n</graphml>\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write graphml: %w", err)
+	}
+	return nil
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language.  Nodes are labeled with their title, seeds are drawn bold
+// and edges are drawn thicker the higher they are ranked.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph recommendations {\n")
+	for _, n := range g.Nodes() {
+		label := n.Title
+		if label == "" {
+			label = n.ID
+		}
+		attrs := fmt.Sprintf("label=%s", quoteDOT(label))
+		if n.Depth == 0 {
+			attrs += ", style=bold"
+		}
+		fmt.Fprintf(bw, "  %s [%s];\n", quoteDOT(n.ID), attrs)
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(bw, "  %s -> %s [weight=%s, penwidth=%s];\n", quoteDOT(e.From), quoteDOT(e.To),
+			strconv.FormatFloat(e.Weight, 'f', 4, 64), strconv.FormatFloat(0.5+2*e.Weight, 'f', 2, 64))
+	}
+	bw.WriteString("}\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write dot: %w", err)
+	}
+	return nil
+}
+
+func writeData(w *bufio.Writer, key, value string) {
+	fmt.Fprintf(w, `      <data key="%s">%s</data>`+"\n", key, escapeXML(value))
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteDOT(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
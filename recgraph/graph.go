@@ -0,0 +1,270 @@
+package recgraph
+
+import (
+	"sort"
+)
+
+// NodeKind is whether a node is a podcast or an episode
+type NodeKind string
+
+// Node kinds
+const (
+	KindPodcast NodeKind = "podcast"
+	KindEpisode NodeKind = "episode"
+)
+
+// Node is a podcast or an episode of the graph
+type Node struct {
+	ID    string
+	Kind  NodeKind
+	Title string
+	// Publisher is the podcast publisher, for episodes the publisher of their podcast
+	Publisher   string
+	ListenScore int
+	// Depth is the number of recommendation hops from the nearest seed, seeds have depth 0
+	Depth int
+	// Seeds are the seeds the node was reached from
+	Seeds []string
+}
+
+// Edge is a recommendation from one node to another
+type Edge struct {
+	From string
+	To   string
+	// Rank is the position in the recommendation list, starting at 1
+	Rank int
+	// Weight is between 0 and 1, higher ranked recommendations weigh more
+	Weight float64
+}
+
+// Graph is a directed, weighted recommendation graph
+type Graph struct {
+	nodes map[string]*Node
+	order []string
+	out   map[string]map[string]Edge
+	in    map[string]map[string]Edge
+}
+
+// NewGraph will create an empty graph
+func NewGraph() *Graph {
+	return &Graph{
+		nodes: map[string]*Node{},
+		out:   map[string]map[string]Edge{},
+		in:    map[string]map[string]Edge{},
+	}
+}
+
+// AddNode adds the node, or merges it into the existing node with the same id keeping the lowest depth and every
+// seed
+func (g *Graph) AddNode(node Node) {
+	existing, ok := g.nodes[node.ID]
+	if !ok {
+		n := node
+		n.Seeds = append([]string(nil), node.Seeds...)
+		g.nodes[node.ID] = &n
+		g.order = append(g.order, node.ID)
+		return
+	}
+
+	if node.Depth < existing.Depth {
+		existing.Depth = node.Depth
+	}
+	if existing.Title == "" {
+		existing.Title = node.Title
+		existing.Publisher = node.Publisher
+		existing.ListenScore = node.ListenScore
+	}
+	for _, seed := range node.Seeds {
+		if !contains(existing.Seeds, seed) {
+			existing.Seeds = append(existing.Seeds, seed)
+		}
+	}
+}
+
+// AddEdge adds the edge, replacing an existing edge between the same nodes.  Both nodes must have been added.
+func (g *Graph) AddEdge(edge Edge) {
+	if g.out[edge.From] == nil {
+		g.out[edge.From] = map[string]Edge{}
+	}
+	if g.in[edge.To] == nil {
+		g.in[edge.To] = map[string]Edge{}
+	}
+	g.out[edge.From][edge.To] = edge
+	g.in[edge.To][edge.From] = edge
+}
+
+// Node returns the node with the id
+func (g *Graph) Node(id string) (Node, bool) {
+	n, ok := g.nodes[id]
+	if !ok {
+		return Node{}, false
+	}
+	return *n, true
+}
+
+// Nodes returns every node in the order they were added
+func (g *Graph) Nodes() []Node {
+	nodes := make([]Node, len(g.order))
+	for i, id := range g.order {
+		nodes[i] = *g.nodes[id]
+	}
+	return nodes
+}
+
+// Edges returns every edge, ordered by source node and rank
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	for _, id := range g.order {
+		edges = append(edges, g.Neighbors(id)...)
+	}
+	return edges
+}
+
+// Neighbors returns the recommendations of the node, highest ranked first
+func (g *Graph) Neighbors(id string) []Edge {
+	return sortedEdges(g.out[id])
+}
+
+// RecommendedBy returns the edges of the nodes recommending the node, heaviest first
+func (g *Graph) RecommendedBy(id string) []Edge {
+	return sortedEdges(g.in[id])
+}
+
+// ShortestPath returns the node ids on a path with the fewest recommendation hops from one node to another, both
+// included.  It returns nil when there is no path.
+func (g *Graph) ShortestPath(from, to string) []string {
+	if _, ok := g.nodes[from]; !ok {
+		return nil
+	}
+	if from == to {
+		return []string{from}
+	}
+
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.Neighbors(current) {
+			if _, seen := previous[edge.To]; seen {
+				continue
+			}
+			previous[edge.To] = current
+			if edge.To == to {
+				path := []string{to}
+				for node := current; node != ""; node = previous[node] {
+					path = append([]string{node}, path...)
+				}
+				return path
+			}
+			queue = append(queue, edge.To)
+		}
+	}
+	return nil
+}
+
+// CommonRecommendations returns the nodes reached from at least minSeeds different seeds, most seeds first.  Seeds
+// themselves are left out.
+func (g *Graph) CommonRecommendations(minSeeds int) []Node {
+	var nodes []Node
+	for _, id := range g.order {
+		n := g.nodes[id]
+		if n.Depth > 0 && len(n.Seeds) >= minSeeds {
+			nodes = append(nodes, *n)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if len(nodes[i].Seeds) != len(nodes[j].Seeds) {
+			return len(nodes[i].Seeds) > len(nodes[j].Seeds)
+		}
+		return len(g.in[nodes[i].ID]) > len(g.in[nodes[j].ID])
+	})
+	return nodes
+}
+
+// Communities groups the nodes into clusters of shows that recommend each other, using weighted label propagation
+// over the graph with edge directions ignored.  Communities are ordered by size, largest first, and every node is in
+// exactly one community.
+func (g *Graph) Communities() [][]string {
+	labels := map[string]string{}
+	for _, id := range g.order {
+		labels[id] = id
+	}
+
+	// a fixed visiting order and tie break keep the result deterministic
+	for round := 0; round < 20; round++ {
+		changed := false
+		for _, id := range g.order {
+			votes := map[string]float64{}
+			for to, edge := range g.out[id] {
+				votes[labels[to]] += edge.Weight
+			}
+			for from, edge := range g.in[id] {
+				votes[labels[from]] += edge.Weight
+			}
+			if len(votes) == 0 {
+				continue
+			}
+
+			best, bestVotes := labels[id], votes[labels[id]]
+			for label, v := range votes {
+				if v > bestVotes || (v == bestVotes && label < best) {
+					best, bestVotes = label, v
+				}
+			}
+			if best != labels[id] {
+				labels[id] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	groups := map[string][]string{}
+	var labelOrder []string
+	for _, id := range g.order {
+		label := labels[id]
+		if _, ok := groups[label]; !ok {
+			labelOrder = append(labelOrder, label)
+		}
+		groups[label] = append(groups[label], id)
+	}
+
+	communities := make([][]string, 0, len(groups))
+	for _, label := range labelOrder {
+		communities = append(communities, groups[label])
+	}
+	sort.SliceStable(communities, func(i, j int) bool { return len(communities[i]) > len(communities[j]) })
+	return communities
+}
+
+func sortedEdges(edges map[string]Edge) []Edge {
+	list := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Weight != list[j].Weight {
+			return list[i].Weight > list[j].Weight
+		}
+		if list[i].Rank != list[j].Rank {
+			return list[i].Rank < list[j].Rank
+		}
+		if list[i].From != list[j].From {
+			return list[i].From < list[j].From
+		}
+		return list[i].To < list[j].To
+	})
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package recgraph
+
+// Option allows for options to be passed to the explorer constructor function
+type Option func(e *Explorer)
+
+// WithMaxDepth sets how many recommendation hops away from the seeds are crawled.  The default is 2.
+func WithMaxDepth(depth int) Option {
+	return func(e *Explorer) {
+		e.maxDepth = depth
+	}
+}
+
+// WithBudget caps the number of api calls of an exploration.  The default is 100.
+func WithBudget(requests int) Option {
+	return func(e *Explorer) {
+		e.budget = requests
+	}
+}
+
+// WithArgs passes extra arguments, e.g. "safe_mode", to every recommendations request
+func WithArgs(args map[string]string) Option {
+	return func(e *Explorer) {
+		e.args = args
+	}
+}
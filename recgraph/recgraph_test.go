@@ -0,0 +1,146 @@
+package recgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	recs  map[string][]string
+	calls []string
+}
+
+func (c *fakeClient) FetchRecommendationsForPodcast(id string, args map[string]string) (*listennotes.Response, error) {
+	c.calls = append(c.calls, id)
+	var recs []interface{}
+	for _, r := range c.recs[id] {
+		recs = append(recs, map[string]interface{}{"id": r, "title": "Show " + r, "publisher": "Pub & Co", "listen_score": float64(50)})
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"recommendations": recs}}, nil
+}
+
+func (c *fakeClient) FetchRecommendationsForEpisode(id string, args map[string]string) (*listennotes.Response, error) {
+	c.calls = append(c.calls, id)
+	var recs []interface{}
+	for _, r := range c.recs[id] {
+		recs = append(recs, map[string]interface{}{"id": r, "title": "Episode " + r, "podcast": map[string]interface{}{"publisher": "Pub"}})
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"recommendations": recs}}, nil
+}
+
+func newClient() *fakeClient {
+	return &fakeClient{recs: map[string][]string{
+		"a": {"c", "d", "e"},
+		"b": {"c", "f"},
+		"c": {"a", "g"},
+		"d": {"e"},
+		"e": {"d", "a"},
+		"f": {"b", "c"},
+		"g": {"h"},
+	}}
+}
+
+func TestExplore(t *testing.T) {
+	client := newClient()
+	explorer := NewExplorer(client, WithMaxDepth(2))
+	graph, err := explorer.ExplorePodcasts(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	if len(graph.Nodes()) != 7 {
+		t.Errorf("Expected the nodes within 2 hops but got %v", graph.Nodes())
+	}
+	if _, ok := graph.Node("h"); ok {
+		t.Error("Nodes beyond the depth limit should not be crawled")
+	}
+	if explorer.Stats().Requests != 6 || explorer.Stats().Truncated {
+		t.Errorf("Expected every node within depth to be fetched once: %v %+v", client.calls, explorer.Stats())
+	}
+
+	neighbors := graph.Neighbors("a")
+	if len(neighbors) != 3 || neighbors[0].To != "c" || neighbors[0].Rank != 1 || neighbors[0].Weight != 1 {
+		t.Errorf("Unexpected neighbors: %+v", neighbors)
+	}
+	if c, _ := graph.Node("c"); c.Title != "Show c" || c.Depth != 1 || len(c.Seeds) != 2 {
+		t.Errorf("Unexpected node: %+v", c)
+	}
+	if by := graph.RecommendedBy("c"); len(by) != 3 {
+		t.Errorf("Expected c to be recommended by a, b and f: %+v", by)
+	}
+
+	if path := graph.ShortestPath("b", "e"); strings.Join(path, ",") != "b,c,a,e" {
+		t.Errorf("Unexpected path: %v", path)
+	}
+	if path := graph.ShortestPath("g", "a"); path != nil {
+		t.Errorf("Expected no path: %v", path)
+	}
+
+	common := graph.CommonRecommendations(2)
+	if len(common) == 0 || common[0].ID != "c" {
+		t.Errorf("Expected c to be recommended from both seeds: %v", common)
+	}
+
+	communities := graph.Communities()
+	total := 0
+	for _, c := range communities {
+		total += len(c)
+	}
+	if total != 7 || len(communities) < 2 {
+		t.Errorf("Expected every node in one of several communities: %v", communities)
+	}
+}
+
+func TestExploreBudget(t *testing.T) {
+	client := newClient()
+	explorer := NewExplorer(client, WithMaxDepth(5), WithBudget(2))
+	graph, err := explorer.ExploreEpisodes(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if len(client.calls) != 2 || !explorer.Stats().Truncated {
+		t.Errorf("Expected the crawl to stop at the budget: %v", client.calls)
+	}
+	if n, _ := graph.Node("c"); n.Kind != KindEpisode || n.Publisher != "Pub" {
+		t.Errorf("Unexpected episode node: %+v", n)
+	}
+}
+
+func TestExport(t *testing.T) {
+	graph, _ := NewExplorer(newClient(), WithMaxDepth(1)).ExplorePodcasts(context.Background(), []string{"a"})
+
+	var graphml bytes.Buffer
+	if err := graph.WriteGraphML(&graphml); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(graphml.Bytes(), &doc); err != nil {
+		t.Fatalf("Expected valid xml but got %s:\n%s", err, graphml.String())
+	}
+	if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 3 || !strings.Contains(graphml.String(), "Pub &amp; Co") {
+		t.Errorf("Unexpected graphml:\n%s", graphml.String())
+	}
+
+	var dot bytes.Buffer
+	graph.WriteDOT(&dot)
+	for _, expected := range []string{`"a" [label="a", style=bold];`, `"c" [label="Show c"];`, `"a" -> "c" [weight=1.0000, penwidth=2.50];`} {
+		if !strings.Contains(dot.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, dot.String())
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package resolve
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Cache stores known mappings, it is looked up by any id of a mapping.  Implementations must be safe for concurrent
+// use.
+type Cache interface {
+	Get(id ID) (Mapping, bool, error)
+	Put(mapping Mapping) error
+}
+
+// MemoryCache is a Cache that keeps mappings in memory, state is lost on restart
+type MemoryCache struct {
+	mu       sync.Mutex
+	mappings map[string]Mapping
+}
+
+var _ Cache = &MemoryCache{}
+
+// NewMemoryCache will create an empty in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{mappings: map[string]Mapping{}}
+}
+
+// Get implements Cache
+func (c *MemoryCache) Get(id ID) (Mapping, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	mapping, ok := c.mappings[id.key()]
+	return mapping, ok, nil
+}
+
+// Put implements Cache
+func (c *MemoryCache) Put(mapping Mapping) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range mapping.IDs() {
+		c.mappings[id.key()] = mapping
+	}
+	return nil
+}
+
+// FileCache is a Cache persisted as JSON Lines.  Mappings are only ever appended, a later line for the same podcast
+// wins when the file is read back on open.
+type FileCache struct {
+	memory *MemoryCache
+	mu     sync.Mutex
+	f      *os.File
+}
+
+var _ Cache = &FileCache{}
+
+// OpenFileCache opens, or creates, the cache at path.  Close it once done.
+func OpenFileCache(path string) (*FileCache, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open id cache %s: %w", path, err)
+	}
+
+	c := &FileCache{memory: NewMemoryCache(), f: f}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var mapping Mapping
+		if err := json.Unmarshal(scanner.Bytes(), &mapping); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed parsing id cache line %d: %w", line, err)
+		}
+		c.memory.Put(mapping)
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed reading id cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Close closes the underlying file
+func (c *FileCache) Close() error {
+	return c.f.Close()
+}
+
+// Get implements Cache
+func (c *FileCache) Get(id ID) (Mapping, bool, error) {
+	return c.memory.Get(id)
+}
+
+// Put implements Cache
+func (c *FileCache) Put(mapping Mapping) error {
+	line, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("failed to encode mapping: %w", err)
+	}
+	line = append(line, '\n')
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.f.Write(line); err != nil {
+		return fmt.Errorf("failed to write mapping: %w", err)
+	}
+	return c.memory.Put(mapping)
+}
@@ -0,0 +1,42 @@
+package resolve
+
+import (
+	"time"
+)
+
+// Option allows for options to be passed to the resolver constructor function
+type Option func(r *Resolver)
+
+// WithChunkSize sets how many ids are sent per BatchFetchPodcasts call.  The default is 10.
+func WithChunkSize(n int) Option {
+	return func(r *Resolver) {
+		if n > 0 {
+			r.chunkSize = n
+		}
+	}
+}
+
+// WithConcurrency sets how many BatchFetchPodcasts calls are in flight at once.  The default is 4.
+func WithConcurrency(n int) Option {
+	return func(r *Resolver) {
+		if n > 0 {
+			r.concurrency = n
+		}
+	}
+}
+
+// WithProgressFunc is called after every chunk with the number of chunks done and the total, e.g. to log the progress
+// of a large catalog
+func WithProgressFunc(fn func(done, total int)) Option {
+	return func(r *Resolver) {
+		r.onProgress = fn
+	}
+}
+
+// WithMissTTL sets how long an id the api did not find is reported unresolved without asking the api again.  The
+// default is an hour, zero looks unresolved ids up on every call.
+func WithMissTTL(d time.Duration) Option {
+	return func(r *Resolver) {
+		r.missTTL = d
+	}
+}
@@ -0,0 +1,362 @@
+// Package resolve maps podcast ids of other platforms, Apple Podcasts (iTunes), Spotify and rss feed urls, to Listen
+// Notes podcast ids and back.
+//
+// Lookups go through BatchFetchPodcasts in chunks and every mapping found is cached, so resolving a large catalog is
+// a single call that only asks the api about ids it has not seen before:
+//
+//	cache, err := resolve.OpenFileCache("/var/lib/podcast-ids.jsonl")
+//	if err != nil {
+//		return err
+//	}
+//	defer cache.Close()
+//	resolver := resolve.NewResolver(client, cache)
+//	result, err := resolver.Resolve([]resolve.ID{
+//		resolve.ITunes("826420969"),
+//		resolve.Spotify("1do6Oa0fxKFyw1Yt1IlBIk"),
+//		resolve.RSS("https://exponent.fm/feed/"),
+//	})
+//	for id, mapping := range result.Mappings {
+//		fmt.Println(id, "->", mapping.ListenNotesID)
+//	}
+//	log.Printf("%d ids could not be resolved", len(result.Unresolved))
+package resolve
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/feedurl"
+)
+
+// Kind is the platform an id belongs to
+type Kind string
+
+// Id kinds
+const (
+	KindListenNotes Kind = "listennotes"
+	KindITunes      Kind = "itunes"
+	KindSpotify     Kind = "spotify"
+	KindRSS         Kind = "rss"
+)
+
+// batchArgs are the BatchFetchPodcasts arguments taking each kind of id
+var batchArgs = map[Kind]string{
+	KindListenNotes: "ids",
+	KindITunes:      "itunes_ids",
+	KindSpotify:     "spotify_ids",
+	KindRSS:         "rsses",
+}
+
+// ID is a podcast id of a platform
+type ID struct {
+	Kind  Kind
+	Value string
+}
+
+// String implements fmt.Stringer, e.g. "itunes:826420969"
+func (id ID) String() string {
+	return string(id.Kind) + ":" + id.Value
+}
+
+var (
+	itunesURLPattern  = regexp.MustCompile(`/id(\d+)`)
+	spotifyURLPattern = regexp.MustCompile(`show[/:]([0-9A-Za-z]+)`)
+)
+
+// ListenNotes is a Listen Notes podcast id
+func ListenNotes(id string) ID {
+	return ID{Kind: KindListenNotes, Value: strings.TrimSpace(id)}
+}
+
+// ITunes is an Apple Podcasts id.  Apple Podcasts urls and "id" prefixed ids are accepted as well.
+func ITunes(id string) ID {
+	id = strings.TrimSpace(id)
+	if m := itunesURLPattern.FindStringSubmatch(id); m != nil {
+		id = m[1]
+	}
+	return ID{Kind: KindITunes, Value: strings.TrimPrefix(id, "id")}
+}
+
+// Spotify is a Spotify show id.  open.spotify.com urls and spotify:show: uris are accepted as well.
+func Spotify(id string) ID {
+	id = strings.TrimSpace(id)
+	if m := spotifyURLPattern.FindStringSubmatch(id); m != nil {
+		id = m[1]
+	}
+	return ID{Kind: KindSpotify, Value: id}
+}
+
+// RSS is an rss feed url.  Urls differing only by scheme, host case or a trailing slash match the same podcast.
+func RSS(url string) ID {
+	return ID{Kind: KindRSS, Value: strings.TrimSpace(url)}
+}
+
+// key identifies the podcast an id refers to, for matching api results and cache lookups
+func (id ID) key() string {
+	if id.Kind == KindRSS {
+		return string(id.Kind) + ":" + feedurl.Normalize(id.Value)
+	}
+	return id.String()
+}
+
+// Mapping is the ids of a single podcast across platforms.  Ids the api does not know are empty.
+type Mapping struct {
+	ListenNotesID string    `json:"listennotes_id"`
+	ITunesID      string    `json:"itunes_id,omitempty"`
+	SpotifyID     string    `json:"spotify_id,omitempty"`
+	RSS           string    `json:"rss,omitempty"`
+	ResolvedAt    time.Time `json:"resolved_at"`
+}
+
+// IDs returns every id the mapping knows
+func (m Mapping) IDs() []ID {
+	var ids []ID
+	if m.ListenNotesID != "" {
+		ids = append(ids, ListenNotes(m.ListenNotesID))
+	}
+	if m.ITunesID != "" {
+		ids = append(ids, ITunes(m.ITunesID))
+	}
+	if m.SpotifyID != "" {
+		ids = append(ids, Spotify(m.SpotifyID))
+	}
+	if m.RSS != "" {
+		ids = append(ids, RSS(m.RSS))
+	}
+	return ids
+}
+
+// Result is the outcome of resolving a set of ids
+type Result struct {
+	// Mappings holds every resolved id, keyed by the id as given to Resolve.  Rss urls are not normalized, look them up
+	// with the same ID that was resolved.
+	Mappings map[ID]Mapping
+	// Unresolved are the ids the api did not find, in the order they were given
+	Unresolved []ID
+}
+
+type batchResponse struct {
+	Podcasts []struct {
+		ID       string `json:"id"`
+		RSS      string `json:"rss"`
+		ITunesID int64  `json:"itunes_id"`
+		Extra    struct {
+			SpotifyURL string `json:"spotify_url"`
+		} `json:"extra"`
+	} `json:"podcasts"`
+}
+
+// Resolver maps ids between platforms
+type Resolver struct {
+	client      listennotes.HTTPClient
+	cache       Cache
+	chunkSize   int
+	concurrency int
+	onProgress  func(done, total int)
+	missTTL     time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	misses map[string]time.Time
+}
+
+// NewResolver will create a resolver using the client for api calls and the cache for known mappings.
+// You can optionally override some configuration.
+func NewResolver(client listennotes.HTTPClient, cache Cache, opts ...Option) *Resolver {
+	r := &Resolver{
+		client:      client,
+		cache:       cache,
+		chunkSize:   10,
+		concurrency: 4,
+		onProgress:  func(int, int) {},
+		missTTL:     time.Hour,
+		now:         time.Now,
+		misses:      map[string]time.Time{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Resolve maps any mix of ids.  Resolving Listen Notes ids gives their ids on the other platforms.  Ids are answered
+// from the cache where possible, the rest are looked up in chunks per kind.  When a lookup fails the error is returned
+// along with everything resolved so far, which is cached, so calling again continues where it stopped.  Ids the api
+// did not find are remembered by the resolver, not the cache, and are not looked up again within the miss ttl, see
+// WithMissTTL.
+func (r *Resolver) Resolve(ids []ID) (Result, error) {
+	result := Result{Mappings: map[ID]Mapping{}}
+
+	pending := map[Kind][]ID{}
+	queued := map[ID]bool{}
+	for _, id := range ids {
+		if id.Value == "" || queued[id] {
+			continue
+		}
+		if _, ok := result.Mappings[id]; ok {
+			continue
+		}
+		mapping, ok, err := r.cache.Get(id)
+		if err != nil {
+			return result, fmt.Errorf("failed to read id cache: %w", err)
+		}
+		if ok {
+			result.Mappings[id] = mapping
+			continue
+		}
+		if r.missedRecently(id) {
+			continue
+		}
+		queued[id] = true
+		pending[id.Kind] = append(pending[id.Kind], id)
+	}
+
+	var chunks [][]ID
+	for _, kind := range []Kind{KindListenNotes, KindITunes, KindSpotify, KindRSS} {
+		list := pending[kind]
+		for start := 0; start < len(list); start += r.chunkSize {
+			end := start + r.chunkSize
+			if end > len(list) {
+				end = len(list)
+			}
+			chunks = append(chunks, list[start:end])
+		}
+	}
+
+	err := r.lookupAll(chunks, result.Mappings)
+	if err == nil {
+		r.recordMisses(queued, result.Mappings)
+	}
+
+	unresolved := map[ID]bool{}
+	for _, id := range ids {
+		if _, ok := result.Mappings[id]; !ok && id.Value != "" && !unresolved[id] {
+			unresolved[id] = true
+			result.Unresolved = append(result.Unresolved, id)
+		}
+	}
+	return result, err
+}
+
+// missedRecently reports whether the api did not find the id within the miss ttl
+func (r *Resolver) missedRecently(id ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	missed, ok := r.misses[id.key()]
+	return ok && r.now().Sub(missed) < r.missTTL
+}
+
+// recordMisses remembers the looked up ids the api did not find
+func (r *Resolver) recordMisses(queued map[ID]bool, mappings map[ID]Mapping) {
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, missed := range r.misses {
+		if now.Sub(missed) >= r.missTTL {
+			delete(r.misses, key)
+		}
+	}
+	for id := range queued {
+		if _, ok := mappings[id]; !ok {
+			r.misses[id.key()] = now
+		}
+	}
+}
+
+// lookupAll runs the chunks with bounded concurrency, stopping at the first error
+func (r *Resolver) lookupAll(chunks [][]ID, mappings map[ID]Mapping) error {
+	var mu sync.Mutex
+	var firstErr error
+	done := 0
+
+	work := make(chan []ID)
+	var wg sync.WaitGroup
+	for w := 0; w < r.concurrency && w < len(chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range work {
+				found, err := r.lookup(chunk)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				for id, m := range found {
+					mappings[id] = m
+				}
+				done++
+				r.onProgress(done, len(chunks))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, chunk := range chunks {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		work <- chunk
+	}
+	close(work)
+	wg.Wait()
+	return firstErr
+}
+
+// lookup resolves a chunk of ids of a single kind and caches the mappings found
+func (r *Resolver) lookup(chunk []ID) (map[ID]Mapping, error) {
+	kind := chunk[0].Kind
+	values := make([]string, len(chunk))
+	for i, id := range chunk {
+		values[i] = id.Value
+	}
+
+	resp, err := r.client.BatchFetchPodcasts(map[string]string{batchArgs[kind]: strings.Join(values, ",")})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s ids: %w", kind, err)
+	}
+	batch := &batchResponse{}
+	if err := resp.Decode(batch); err != nil {
+		return nil, err
+	}
+
+	wanted := map[string][]ID{}
+	for _, id := range chunk {
+		wanted[id.key()] = append(wanted[id.key()], id)
+	}
+
+	found := map[ID]Mapping{}
+	now := r.now()
+	for _, p := range batch.Podcasts {
+		if p.ID == "" {
+			continue
+		}
+		mapping := Mapping{ListenNotesID: p.ID, RSS: p.RSS, ResolvedAt: now}
+		if p.ITunesID > 0 {
+			mapping.ITunesID = strconv.FormatInt(p.ITunesID, 10)
+		}
+		if p.Extra.SpotifyURL != "" {
+			mapping.SpotifyID = Spotify(p.Extra.SpotifyURL).Value
+		}
+
+		if err := r.cache.Put(mapping); err != nil {
+			return found, fmt.Errorf("failed to write id cache: %w", err)
+		}
+		for _, known := range mapping.IDs() {
+			for _, id := range wanted[known.key()] {
+				found[id] = mapping
+			}
+		}
+	}
+	return found, nil
+}
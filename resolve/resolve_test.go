@@ -0,0 +1,184 @@
+package resolve
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/feedurl"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	podcasts []map[string]interface{}
+	mu       sync.Mutex
+	calls    []map[string]string
+	fail     bool
+}
+
+func (c *fakeClient) BatchFetchPodcasts(args map[string]string) (*listennotes.Response, error) {
+	c.mu.Lock()
+	c.calls = append(c.calls, args)
+	c.mu.Unlock()
+	if c.fail {
+		return nil, listennotes.ErrTooManyRequests
+	}
+
+	var found []interface{}
+	for _, p := range c.podcasts {
+		extra := p["extra"].(map[string]interface{})
+		for key, values := range args {
+			for _, v := range strings.Split(values, ",") {
+				match := (key == "ids" && p["id"] == v) ||
+					(key == "rsses" && feedurl.Normalize(p["rss"].(string)) == feedurl.Normalize(v)) ||
+					(key == "itunes_ids" && strconv.FormatFloat(p["itunes_id"].(float64), 'f', -1, 64) == v) ||
+					(key == "spotify_ids" && strings.HasSuffix(extra["spotify_url"].(string), "/"+v))
+				if match {
+					found = append(found, p)
+				}
+			}
+		}
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"podcasts": found}}, nil
+}
+
+func podcast(id, itunes, spotify, rss string) map[string]interface{} {
+	// itunes_id is a number in api responses
+	itunesID, _ := strconv.ParseFloat(itunes, 64)
+	return map[string]interface{}{
+		"id":        id,
+		"rss":       rss,
+		"itunes_id": itunesID,
+		"extra":     map[string]interface{}{"spotify_url": "https://open.spotify.com/show/" + spotify},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	client := &fakeClient{podcasts: []map[string]interface{}{
+		podcast("ln1", "826420969", "1do6Oa0fxKFyw1Yt1IlBIk", "https://exponent.fm/feed/"),
+		podcast("ln2", "111", "sp2", "https://two.example/rss"),
+		podcast("ln3", "333", "sp3", "https://three.example/rss"),
+	}}
+	var progress []int
+	resolver := NewResolver(client, NewMemoryCache(), WithChunkSize(2), WithConcurrency(1),
+		WithProgressFunc(func(done, total int) { progress = append(progress, done, total) }))
+
+	ids := []ID{
+		ITunes("https://podcasts.apple.com/us/podcast/exponent/id826420969"),
+		Spotify("spotify:show:sp2"),
+		RSS("http://THREE.example/rss/"),
+		ITunes("999"),
+		ListenNotes("ln2"),
+		ITunes("id111"),
+	}
+	result, err := resolver.Resolve(ids)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	expected := map[ID]string{ids[0]: "ln1", ids[1]: "ln2", ids[2]: "ln3", ids[4]: "ln2", ids[5]: "ln2"}
+	for id, ln := range expected {
+		if m, ok := result.Mappings[id]; !ok || m.ListenNotesID != ln {
+			t.Errorf("Expected %s to resolve to %s but got %+v", id, ln, m)
+		}
+	}
+	if back := result.Mappings[ids[4]]; back.ITunesID != "111" || back.SpotifyID != "sp2" || back.RSS != "https://two.example/rss" {
+		t.Errorf("Listen Notes ids should resolve to the other platforms: %+v", back)
+	}
+	if len(result.Unresolved) != 1 || result.Unresolved[0] != ITunes("999") {
+		t.Errorf("Unexpected unresolved ids: %v", result.Unresolved)
+	}
+	// itunes: 3 ids in 2 chunks, spotify, rss and listennotes: 1 chunk each
+	if len(client.calls) != 5 || len(progress) != 10 || progress[8] != 5 {
+		t.Errorf("Unexpected chunking %v with progress %v", client.calls, progress)
+	}
+
+	client.calls = nil
+	result, _ = resolver.Resolve([]ID{Spotify("1do6Oa0fxKFyw1Yt1IlBIk"), RSS("https://two.example/rss")})
+	if len(client.calls) != 0 || len(result.Mappings) != 2 {
+		t.Errorf("Expected cached answers but got calls %v and %v", client.calls, result.Mappings)
+	}
+}
+
+func TestResolveRSSKeys(t *testing.T) {
+	client := &fakeClient{podcasts: []map[string]interface{}{
+		podcast("ln1", "826420969", "1do6Oa0fxKFyw1Yt1IlBIk", "https://exponent.fm/feed/"),
+	}}
+	resolver := NewResolver(client, NewMemoryCache())
+
+	given := RSS("http://Exponent.FM/feed")
+	other := RSS("https://EXPONENT.fm/feed/")
+	result, err := resolver.Resolve([]ID{given, other})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	for _, id := range []ID{given, other} {
+		if m, ok := result.Mappings[id]; !ok || m.ListenNotesID != "ln1" {
+			t.Errorf("Expected the mapping under the given url %s but got %v", id, result.Mappings)
+		}
+	}
+	if _, ok := result.Mappings[RSS("exponent.fm/feed")]; ok || len(result.Mappings) != 2 {
+		t.Errorf("Mappings should only be keyed by the given ids: %v", result.Mappings)
+	}
+}
+
+func TestResolveRemembersMisses(t *testing.T) {
+	client := &fakeClient{}
+	resolver := NewResolver(client, NewMemoryCache(), WithMissTTL(time.Hour))
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+
+	resolver.Resolve([]ID{ITunes("999")})
+	result, err := resolver.Resolve([]ID{ITunes("999")})
+	if err != nil || len(result.Unresolved) != 1 || len(client.calls) != 1 {
+		t.Errorf("Expected a recent miss to be unresolved without a lookup: %v %v: %v", result.Unresolved, client.calls, err)
+	}
+
+	now = now.Add(time.Hour)
+	resolver.Resolve([]ID{ITunes("999")})
+	if len(client.calls) != 2 {
+		t.Errorf("Expected the miss to be looked up again after the ttl: %v", client.calls)
+	}
+}
+
+func TestResolveError(t *testing.T) {
+	client := &fakeClient{fail: true}
+	resolver := NewResolver(client, NewMemoryCache())
+	result, err := resolver.Resolve([]ID{ITunes("1"), ITunes("2")})
+	if !errors.Is(err, listennotes.ErrTooManyRequests) || len(result.Unresolved) != 2 {
+		t.Errorf("Expected the api error and every id unresolved but got %v: %v", result.Unresolved, err)
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.jsonl")
+	cache, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	cache.Put(Mapping{ListenNotesID: "ln1", ITunesID: "1", RSS: "https://a.example/rss"})
+	cache.Close()
+
+	reopened, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	defer reopened.Close()
+
+	var found []string
+	for _, id := range []ID{ITunes("1"), RSS("http://A.example/rss/"), ListenNotes("ln1"), Spotify("x")} {
+		if m, ok, _ := reopened.Get(id); ok {
+			found = append(found, m.ListenNotesID+" "+id.String())
+		}
+	}
+	sort.Strings(found)
+	if len(found) != 3 {
+		t.Errorf("Expected every id of the mapping to be found: %v", found)
+	}
+}
@@ -0,0 +1,32 @@
+package submissions
+
+import (
+	"time"
+)
+
+// Option allows for options to be passed to the tracker constructor function
+type Option func(t *Tracker)
+
+// WithTransitionFunc is called with every status change, e.g. submitted -> in review -> accepted
+func WithTransitionFunc(fn func(Transition)) Option {
+	return func(t *Tracker) {
+		t.onTransition = fn
+	}
+}
+
+// WithRecheckAfter sets how long a submission can be in review before Poll submits it again to learn whether it was
+// rejected, and how long Poll waits between two such submissions.  Listen Notes reviews submissions within 12 hours,
+// which is the default.
+func WithRecheckAfter(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.recheckAfter = d
+	}
+}
+
+// WithErrorFunc is called by Run with the error of each poll that failed, e.g. to log it.  The errors are dropped if
+// not provided.
+func WithErrorFunc(fn func(error)) Option {
+	return func(t *Tracker) {
+		t.onError = fn
+	}
+}
@@ -0,0 +1,68 @@
+package submissions
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Summary is the daily report of submission activity
+type Summary struct {
+	Day       time.Time
+	Submitted int
+	Accepted  int
+	Rejected  int
+	// InReview is the number of submissions still waiting for review at the end of the day, whenever submitted
+	InReview int
+	// AverageTimeToAccept is measured over the submissions accepted on the day
+	AverageTimeToAccept time.Duration
+}
+
+// DailySummary reports the activity of the day containing the given time, in its location
+func (t *Tracker) DailySummary(day time.Time) (Summary, error) {
+	all, err := t.store.List()
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to list submissions: %w", err)
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	within := func(ts time.Time) bool {
+		return !ts.Before(start) && ts.Before(end)
+	}
+
+	summary := Summary{Day: start}
+	var acceptDuration time.Duration
+	for _, submission := range all {
+		if within(submission.SubmittedAt) {
+			summary.Submitted++
+		}
+		switch {
+		case submission.Status == StatusAccepted && within(submission.UpdatedAt):
+			summary.Accepted++
+			acceptDuration += submission.UpdatedAt.Sub(submission.SubmittedAt)
+		case submission.Status == StatusRejected && within(submission.UpdatedAt):
+			summary.Rejected++
+		case !submission.Done() && submission.SubmittedAt.Before(end):
+			summary.InReview++
+		}
+	}
+	if summary.Accepted > 0 {
+		summary.AverageTimeToAccept = acceptDuration / time.Duration(summary.Accepted)
+	}
+	return summary, nil
+}
+
+// String renders the summary as a plain text report
+func (s Summary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Podcast submissions for %s\n", s.Day.Format("2006-01-02"))
+	fmt.Fprintf(&b, "  Submitted: %d\n", s.Submitted)
+	fmt.Fprintf(&b, "  Accepted:  %d\n", s.Accepted)
+	fmt.Fprintf(&b, "  Rejected:  %d\n", s.Rejected)
+	fmt.Fprintf(&b, "  In review: %d\n", s.InReview)
+	if s.Accepted > 0 {
+		fmt.Fprintf(&b, "  Average time to accept: %s\n", s.AverageTimeToAccept.Round(time.Minute))
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package submissions
+
+import (
+	"sort"
+	"sync"
+)
+
+// Store persists submissions, keyed by their rss url.  Implementations must be safe for concurrent use.
+type Store interface {
+	Save(submission Submission) error
+	Get(rss string) (Submission, bool, error)
+	List() ([]Submission, error)
+}
+
+// MemoryStore is a Store that keeps submissions in memory, state is lost on restart
+type MemoryStore struct {
+	mu          sync.Mutex
+	submissions map[string]Submission
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore will create an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{submissions: map[string]Submission{}}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(submission Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.submissions[submission.RSS] = submission
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(rss string) (Submission, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submission, ok := s.submissions[rss]
+	return submission, ok, nil
+}
+
+// List implements Store, submissions are ordered by submission time
+func (s *MemoryStore) List() ([]Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Submission, 0, len(s.submissions))
+	for _, submission := range s.submissions {
+		list = append(list, submission)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SubmittedAt.Before(list[j].SubmittedAt)
+	})
+	return list, nil
+}
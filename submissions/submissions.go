@@ -0,0 +1,292 @@
+// Package submissions tracks podcasts submitted to the listennotes database through SubmitPodcast until they are
+// accepted or rejected.
+//
+// Every submission is recorded in a Store.  Poll, or Run on a schedule, checks the submissions still in review with
+// BatchFetchPodcasts by rss and reports each status change to a callback:
+//
+//	tracker := submissions.NewTracker(client, submissions.NewMemoryStore(),
+//		submissions.WithTransitionFunc(func(t submissions.Transition) {
+//			log.Printf("%s: %s -> %s", t.Submission.RSS, t.From, t.To)
+//		}),
+//	)
+//	tracker.Submit("https://feeds.example.com/show.xml", nil)
+//	go tracker.Run(ctx, 30*time.Minute)
+package submissions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/feedurl"
+)
+
+// Status is the lifecycle state of a submission
+type Status string
+
+// Submission statuses
+const (
+	StatusSubmitted Status = "submitted"
+	StatusInReview  Status = "in review"
+	StatusAccepted  Status = "accepted"
+	StatusRejected  Status = "rejected"
+)
+
+// Statuses returned by the SubmitPodcast endpoint
+const (
+	apiStatusFound    = "found"
+	apiStatusRejected = "rejected"
+)
+
+// batchSize is the number of rss urls checked per BatchFetchPodcasts call
+const batchSize = 10
+
+// Submission is a single podcast submitted to listennotes
+type Submission struct {
+	RSS           string
+	PodcastID     string
+	Status        Status
+	SubmittedAt   time.Time
+	UpdatedAt     time.Time
+	LastCheckedAt time.Time
+	// ResubmittedAt is when the podcast was last submitted again, by Submit or by Poll once the recheck delay passed
+	ResubmittedAt time.Time
+	Checks        int
+}
+
+// lastSubmittedAt is when the podcast was last sent to SubmitPodcast
+func (s Submission) lastSubmittedAt() time.Time {
+	if s.ResubmittedAt.After(s.SubmittedAt) {
+		return s.ResubmittedAt
+	}
+	return s.SubmittedAt
+}
+
+// Done reports whether the submission reached a final status
+func (s Submission) Done() bool {
+	return s.Status == StatusAccepted || s.Status == StatusRejected
+}
+
+// Transition is a status change of a submission
+type Transition struct {
+	Submission Submission
+	From       Status
+	To         Status
+	At         time.Time
+}
+
+// Tracker records submissions and follows them until they are accepted or rejected
+type Tracker struct {
+	client       listennotes.HTTPClient
+	store        Store
+	onTransition func(Transition)
+	onError      func(error)
+	recheckAfter time.Duration
+	now          func() time.Time
+}
+
+// NewTracker will create a tracker using the client for api calls and the store for state.
+// You can optionally override some configuration.
+func NewTracker(client listennotes.HTTPClient, store Store, opts ...Option) *Tracker {
+	t := &Tracker{
+		client:       client,
+		store:        store,
+		onTransition: func(Transition) {},
+		onError:      func(error) {},
+		recheckAfter: 12 * time.Hour,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Submit sends the rss url to SubmitPodcast and records the submission.  Extra args, like "email", are passed as is.
+func (t *Tracker) Submit(rss string, args map[string]string) (Submission, error) {
+	fields := map[string]string{}
+	for k, v := range args {
+		fields[k] = v
+	}
+	fields["rss"] = rss
+
+	resp, err := t.client.SubmitPodcast(fields)
+	if err != nil {
+		return Submission{}, fmt.Errorf("failed to submit %s: %w", rss, err)
+	}
+
+	now := t.now()
+	submission, ok, err := t.store.Get(rss)
+	if err != nil {
+		return Submission{}, fmt.Errorf("failed to load submission %s: %w", rss, err)
+	}
+	if !ok {
+		submission = Submission{RSS: rss, SubmittedAt: now}
+		if err := t.transition(&submission, StatusSubmitted); err != nil {
+			return Submission{}, err
+		}
+	} else {
+		submission.ResubmittedAt = now
+	}
+
+	if id := podcastID(resp.Data); id != "" {
+		submission.PodcastID = id
+	}
+	if err := t.transition(&submission, statusFromAPI(resp.Data)); err != nil {
+		return Submission{}, err
+	}
+	return submission, nil
+}
+
+// Poll checks every submission still in review.  Accepted podcasts are found through BatchFetchPodcasts.  Submissions
+// still in review a recheck delay after they were last submitted are submitted again, which reports rejections.
+func (t *Tracker) Poll() error {
+	all, err := t.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list submissions: %w", err)
+	}
+
+	var pending []Submission
+	for _, submission := range all {
+		if !submission.Done() {
+			pending = append(pending, submission)
+		}
+	}
+
+	for start := 0; start < len(pending); start += batchSize {
+		end := start + batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		if err := t.checkBatch(pending[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run polls every interval until the context is done.  Errors are passed to the error callback and failed polls are
+// retried on the next tick, only the context ends the loop.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Poll(); err != nil {
+			t.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *Tracker) checkBatch(batch []Submission) error {
+	rsses := make([]string, len(batch))
+	for i, submission := range batch {
+		rsses[i] = submission.RSS
+	}
+
+	resp, err := t.client.BatchFetchPodcasts(map[string]string{"rsses": strings.Join(rsses, ",")})
+	if err != nil {
+		return fmt.Errorf("failed to check submissions: %w", err)
+	}
+
+	found := map[string]string{}
+	podcasts, _ := resp.Data["podcasts"].([]interface{})
+	for _, p := range podcasts {
+		podcast, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rss, _ := podcast["rss"].(string)
+		id, _ := podcast["id"].(string)
+		if rss != "" {
+			found[feedurl.Normalize(rss)] = id
+		}
+	}
+
+	now := t.now()
+	for i := range batch {
+		submission := &batch[i]
+		submission.Checks++
+		submission.LastCheckedAt = now
+
+		if id, ok := found[feedurl.Normalize(submission.RSS)]; ok {
+			submission.PodcastID = id
+			if err := t.transition(submission, StatusAccepted); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if now.Sub(submission.lastSubmittedAt()) >= t.recheckAfter {
+			resp, err := t.client.SubmitPodcast(map[string]string{"rss": submission.RSS})
+			if err != nil {
+				return fmt.Errorf("failed to recheck %s: %w", submission.RSS, err)
+			}
+			submission.ResubmittedAt = now
+			if id := podcastID(resp.Data); id != "" {
+				submission.PodcastID = id
+			}
+			if err := t.transition(submission, statusFromAPI(resp.Data)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := t.store.Save(*submission); err != nil {
+			return fmt.Errorf("failed to save submission %s: %w", submission.RSS, err)
+		}
+	}
+	return nil
+}
+
+// transition moves the submission to the status, saving it and reporting the change.  Staying in the same status only
+// saves it.
+func (t *Tracker) transition(submission *Submission, to Status) error {
+	from := submission.Status
+	now := t.now()
+	if from != to {
+		submission.Status = to
+		submission.UpdatedAt = now
+	}
+
+	if err := t.store.Save(*submission); err != nil {
+		return fmt.Errorf("failed to save submission %s: %w", submission.RSS, err)
+	}
+
+	if from != to {
+		t.onTransition(Transition{
+			Submission: *submission,
+			From:       from,
+			To:         to,
+			At:         now,
+		})
+	}
+	return nil
+}
+
+func statusFromAPI(data map[string]interface{}) Status {
+	status, _ := data["status"].(string)
+	switch status {
+	case apiStatusFound:
+		return StatusAccepted
+	case apiStatusRejected:
+		return StatusRejected
+	default:
+		return StatusInReview
+	}
+}
+
+func podcastID(data map[string]interface{}) string {
+	podcast, _ := data["podcast"].(map[string]interface{})
+	id, _ := podcast["id"].(string)
+	return id
+}
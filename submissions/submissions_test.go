@@ -0,0 +1,196 @@
+package submissions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	submitStatus map[string]string
+	accepted     map[string]string
+	batchCalls   int
+	submitCalls  int
+	failBatches  int
+}
+
+func (c *fakeClient) SubmitPodcast(args map[string]string) (*listennotes.Response, error) {
+	c.submitCalls++
+	status := c.submitStatus[args["rss"]]
+	if status == "" {
+		status = "in review"
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"status": status}}, nil
+}
+
+func (c *fakeClient) BatchFetchPodcasts(args map[string]string) (*listennotes.Response, error) {
+	c.batchCalls++
+	if c.batchCalls <= c.failBatches {
+		return nil, listennotes.ErrInternalServerError
+	}
+	var podcasts []interface{}
+	for _, rss := range strings.Split(args["rsses"], ",") {
+		if id, ok := c.accepted[rss]; ok {
+			podcasts = append(podcasts, map[string]interface{}{"id": id, "rss": rss + "/"})
+		}
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"podcasts": podcasts}}, nil
+}
+
+func TestLifecycle(t *testing.T) {
+	client := &fakeClient{
+		submitStatus: map[string]string{"https://a.example/rss": "in review", "https://b.example/rss": "found"},
+		accepted:     map[string]string{},
+	}
+
+	var transitions []Transition
+	tracker := NewTracker(client, NewMemoryStore(), WithTransitionFunc(func(tr Transition) {
+		transitions = append(transitions, tr)
+	}))
+	now := time.Date(2021, 5, 3, 10, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	if s, err := tracker.Submit("https://a.example/rss", nil); err != nil || s.Status != StatusInReview {
+		t.Fatalf("Expected in review but got %v: %v", s.Status, err)
+	}
+	if s, _ := tracker.Submit("https://b.example/rss", nil); s.Status != StatusAccepted {
+		t.Errorf("A found podcast should be accepted right away: %v", s.Status)
+	}
+
+	now = now.Add(time.Hour)
+	tracker.Poll()
+	if len(transitions) != 4 {
+		t.Errorf("A podcast still in review should not transition: %v", transitions)
+	}
+
+	client.accepted["https://a.example/rss"] = "abc"
+	now = now.Add(time.Hour)
+	if err := tracker.Poll(); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	expected := []struct{ from, to Status }{
+		{"", StatusSubmitted},
+		{StatusSubmitted, StatusInReview},
+		{"", StatusSubmitted},
+		{StatusSubmitted, StatusAccepted},
+		{StatusInReview, StatusAccepted},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected %d transitions but got %v", len(expected), transitions)
+	}
+	for i, e := range expected {
+		if transitions[i].From != e.from || transitions[i].To != e.to {
+			t.Errorf("Transition %d was %s -> %s", i, transitions[i].From, transitions[i].To)
+		}
+	}
+	if transitions[4].Submission.PodcastID != "abc" {
+		t.Errorf("Accepted podcast id was not recorded: %v", transitions[4].Submission)
+	}
+	if client.batchCalls != 2 {
+		t.Errorf("Only pending submissions should be polled: %d", client.batchCalls)
+	}
+
+	summary, _ := tracker.DailySummary(now)
+	if summary.Submitted != 2 || summary.Accepted != 2 || summary.InReview != 0 || summary.AverageTimeToAccept != time.Hour {
+		t.Errorf("Summary was not as expected: %+v", summary)
+	}
+	if !strings.Contains(summary.String(), "Accepted:  2") {
+		t.Errorf("Summary report was not as expected: %s", summary)
+	}
+}
+
+func TestRejectedOnRecheck(t *testing.T) {
+	client := &fakeClient{submitStatus: map[string]string{}, accepted: map[string]string{}}
+	tracker := NewTracker(client, NewMemoryStore(), WithRecheckAfter(time.Hour))
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Submit("https://a.example/rss", nil)
+	client.submitStatus["https://a.example/rss"] = "rejected"
+
+	tracker.Poll()
+	if s, _, _ := tracker.store.Get("https://a.example/rss"); s.Status != StatusInReview {
+		t.Errorf("Should not recheck before the delay: %v", s.Status)
+	}
+
+	now = now.Add(2 * time.Hour)
+	tracker.Poll()
+	if s, _, _ := tracker.store.Get("https://a.example/rss"); s.Status != StatusRejected || s.Checks != 2 {
+		t.Errorf("Expected rejected after recheck: %+v", s)
+	}
+}
+
+func TestRecheckOncePerDelay(t *testing.T) {
+	client := &fakeClient{submitStatus: map[string]string{}, accepted: map[string]string{}}
+	tracker := NewTracker(client, NewMemoryStore(), WithRecheckAfter(12*time.Hour))
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Submit("https://a.example/rss", nil)
+
+	for _, poll := range []struct {
+		after       time.Duration
+		submitCalls int
+	}{
+		{13 * time.Hour, 2},
+		{14 * time.Hour, 2},
+		{20 * time.Hour, 2},
+		{25 * time.Hour, 3},
+		{26 * time.Hour, 3},
+	} {
+		tracker.now = func() time.Time { return now.Add(poll.after) }
+		if err := tracker.Poll(); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		if client.submitCalls != poll.submitCalls {
+			t.Errorf("Expected %d submissions after %s but got %d", poll.submitCalls, poll.after, client.submitCalls)
+		}
+	}
+}
+
+func TestRunRetriesFailedPolls(t *testing.T) {
+	client := &fakeClient{
+		accepted:    map[string]string{"https://a.example/rss": "ln1"},
+		failBatches: 1,
+	}
+	errs := make(chan error, 1)
+	accepted := make(chan Transition, 1)
+	tracker := NewTracker(client, NewMemoryStore(),
+		WithErrorFunc(func(err error) { errs <- err }),
+		WithTransitionFunc(func(tr Transition) {
+			if tr.To == StatusAccepted {
+				accepted <- tr
+			}
+		}),
+	)
+	if _, err := tracker.Submit("https://a.example/rss", nil); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx, 10*time.Millisecond)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, listennotes.ErrInternalServerError) {
+			t.Errorf("Expected the batch error but got: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to report the failed poll")
+	}
+	select {
+	case tr := <-accepted:
+		if tr.Submission.PodcastID != "ln1" {
+			t.Errorf("Unexpected transition: %+v", tr)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected Run to keep polling after a failure")
+	}
+}
@@ -0,0 +1,77 @@
+package suggest
+
+import (
+	"time"
+
+	"github.com/ListenNotes/podcast-api-go/internal/lru"
+)
+
+// Option allows for options to be passed to the suggester constructor function
+type Option func(s *Suggester)
+
+// WithDebounce sets how long Update waits for input to settle before calling the api.  The default is 200ms.
+func WithDebounce(d time.Duration) Option {
+	return func(s *Suggester) {
+		s.debounce = d
+	}
+}
+
+// WithMinChars sets the shortest query, after trimming, that is sent to the api.  Shorter queries get an empty result.
+// The default is 2.
+func WithMinChars(n int) Option {
+	return func(s *Suggester) {
+		s.minChars = n
+	}
+}
+
+// WithMaxSuggestions caps the length of the merged suggestion list.  The default is 10.
+func WithMaxSuggestions(n int) Option {
+	return func(s *Suggester) {
+		s.maxSuggestions = n
+	}
+}
+
+// WithCache sets the ttl and size of the cache of api results.  The default keeps 500 queries for 10 minutes.
+func WithCache(ttl time.Duration, maxEntries int) Option {
+	return func(s *Suggester) {
+		s.cache = lru.New(ttl, maxEntries, s.clock)
+	}
+}
+
+// WithPrefixLimit enables answering a query from the cached result of a shorter query it starts with, saving api
+// calls while typing.  n is how many terms, podcasts and genres Typeahead returns at most, a cached result with fewer
+// of each is taken to hold every match and is filtered locally.  Typeahead matching is fuzzy, not prefix only, so the
+// filtered suggestions can differ from what the api returns for the longer query.  Prefix reuse is off by default.
+func WithPrefixLimit(n int) Option {
+	return func(s *Suggester) {
+		s.prefixLimit = n
+	}
+}
+
+// WithPodcasts includes matching podcasts in the suggestions
+func WithPodcasts() Option {
+	return func(s *Suggester) {
+		s.showPodcasts = true
+	}
+}
+
+// WithGenres includes matching genres in the suggestions
+func WithGenres() Option {
+	return func(s *Suggester) {
+		s.showGenres = true
+	}
+}
+
+// WithoutSpellCheck leaves SpellCheck corrections out, saving an api call per query
+func WithoutSpellCheck() Option {
+	return func(s *Suggester) {
+		s.spellCheck = false
+	}
+}
+
+// WithoutRelatedSearches leaves FetchRelatedSearches suggestions out, saving an api call per query
+func WithoutRelatedSearches() Option {
+	return func(s *Suggester) {
+		s.relatedSearches = false
+	}
+}
@@ -0,0 +1,464 @@
+// Package suggest turns the Typeahead endpoint into a search box helper.
+//
+// A Suggester debounces keystrokes, caches api results, drops results of superseded queries and merges Typeahead
+// terms with SpellCheck corrections and FetchRelatedSearches terms into one ranked list:
+//
+//	s := suggest.NewSuggester(client, suggest.WithPodcasts())
+//	defer s.Close()
+//	go func() {
+//		for result := range s.Results() {
+//			render(result.Suggestions)
+//		}
+//	}()
+//	// on every keystroke
+//	s.Update(input)
+package suggest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+	"github.com/ListenNotes/podcast-api-go/internal/lru"
+)
+
+// Kind is where a suggestion comes from
+type Kind string
+
+// Suggestion kinds
+const (
+	KindTerm       Kind = "term"
+	KindCorrection Kind = "correction"
+	KindRelated    Kind = "related"
+	KindGenre      Kind = "genre"
+	KindPodcast    Kind = "podcast"
+)
+
+// Base scores per kind, suggestions lose a little for every position they are down their source list
+const (
+	scoreTerm       = 1.0
+	scoreCorrection = 0.8
+	scoreRelated    = 0.6
+	scorePodcast    = 0.5
+	scoreGenre      = 0.4
+	positionDecay   = 0.02
+)
+
+// Suggestion is a single entry of the merged suggestion list
+type Suggestion struct {
+	Text  string
+	Kind  Kind
+	Score float64
+	// ID is the podcast or genre id for those kinds
+	ID string
+}
+
+// Result is the suggestion list for a query
+type Result struct {
+	Query       string
+	Suggestions []Suggestion
+	Err         error
+}
+
+// typeahead is the part of a Typeahead response the suggester uses
+type typeahead struct {
+	Terms    []string `json:"terms"`
+	Genres   []genre  `json:"genres"`
+	Podcasts []struct {
+		ID        string `json:"id"`
+		Title     string `json:"title_original"`
+		Publisher string `json:"publisher_original"`
+	} `json:"podcasts"`
+	// complete is set when the response holds every match for its query
+	complete bool
+}
+
+type genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type spellCheck struct {
+	Tokens []struct {
+		Token      string `json:"token"`
+		Offset     int    `json:"offset"`
+		Suggestion string `json:"suggestion"`
+	} `json:"tokens"`
+}
+
+type relatedSearches struct {
+	Terms []string `json:"terms"`
+}
+
+// Suggester produces suggestions for search box input
+type Suggester struct {
+	client          listennotes.HTTPClient
+	debounce        time.Duration
+	minChars        int
+	maxSuggestions  int
+	prefixLimit     int
+	showPodcasts    bool
+	showGenres      bool
+	spellCheck      bool
+	relatedSearches bool
+	cache           *lru.Cache
+	now             func() time.Time
+
+	mu         sync.Mutex
+	generation uint64
+	timer      *time.Timer
+	results    chan Result
+	closed     bool
+}
+
+// NewSuggester will create a suggester with reasonable defaults.
+// You can optionally override some configuration.
+func NewSuggester(client listennotes.HTTPClient, opts ...Option) *Suggester {
+	s := &Suggester{
+		client:          client,
+		debounce:        200 * time.Millisecond,
+		minChars:        2,
+		maxSuggestions:  10,
+		spellCheck:      true,
+		relatedSearches: true,
+		now:             time.Now,
+		results:         make(chan Result, 1),
+	}
+	s.cache = lru.New(10*time.Minute, 500, s.clock)
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Results delivers the result of the latest Update.  Results of queries that were superseded before they completed
+// are never delivered, and an undelivered result is replaced by a newer one.  The channel is closed by Close.
+func (s *Suggester) Results() <-chan Result {
+	return s.results
+}
+
+// Update records new input.  Once the input has not changed for the debounce delay its suggestions are fetched and
+// delivered on Results.
+func (s *Suggester) Update(query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.generation++
+	generation := s.generation
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(s.debounce, func() {
+		result, err := s.suggest(query, func() bool { return s.superseded(generation) })
+		if err == errSuperseded {
+			return
+		}
+		result.Err = err
+		s.deliver(generation, result)
+	})
+}
+
+// Suggest fetches the suggestions for the query right away, without debouncing
+func (s *Suggester) Suggest(query string) (Result, error) {
+	return s.suggest(query, func() bool { return false })
+}
+
+// Close stops pending work and closes the Results channel
+func (s *Suggester) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	close(s.results)
+}
+
+var errSuperseded = errors.New("query superseded")
+
+func (s *Suggester) superseded(generation uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed || generation != s.generation
+}
+
+// deliver hands the result to Results if its query is still the latest one, replacing an undelivered older result
+func (s *Suggester) deliver(generation uint64, result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || generation != s.generation {
+		return
+	}
+	select {
+	case <-s.results:
+	default:
+	}
+	s.results <- result
+}
+
+// suggest builds the result for the query.  The api calls cannot be cancelled once sent, so superseded is checked
+// before each of them and the remaining calls are skipped as soon as a newer query came in.
+func (s *Suggester) suggest(query string, superseded func() bool) (Result, error) {
+	result := Result{Query: query}
+	normalized := normalize(query)
+	if utf8.RuneCountInString(normalized) < s.minChars {
+		return result, nil
+	}
+
+	if superseded() {
+		return result, errSuperseded
+	}
+	terms, err := s.typeahead(normalized)
+	if err != nil {
+		return result, err
+	}
+
+	var correction string
+	if s.spellCheck {
+		if superseded() {
+			return result, errSuperseded
+		}
+		if correction, err = s.correction(normalized); err != nil {
+			return result, err
+		}
+	}
+
+	var related []string
+	if s.relatedSearches {
+		if superseded() {
+			return result, errSuperseded
+		}
+		if related, err = s.related(normalized); err != nil {
+			return result, err
+		}
+	}
+
+	result.Suggestions = s.merge(terms, correction, related)
+	return result, nil
+}
+
+func (s *Suggester) typeahead(query string) (*typeahead, error) {
+	if cached, ok := s.cache.Get("typeahead " + query); ok {
+		return cached.(*typeahead), nil
+	}
+	if cached := s.prefixMatch(query); cached != nil {
+		return cached, nil
+	}
+
+	args := map[string]string{"q": query}
+	if s.showPodcasts {
+		args["show_podcasts"] = "1"
+	}
+	if s.showGenres {
+		args["show_genres"] = "1"
+	}
+	resp, err := s.client.Typeahead(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch typeahead for %q: %w", query, err)
+	}
+
+	result := &typeahead{}
+	if err := resp.Decode(result); err != nil {
+		return nil, err
+	}
+	result.complete = s.prefixLimit > 0 && len(result.Terms) < s.prefixLimit && len(result.Podcasts) < s.prefixLimit &&
+		len(result.Genres) < s.prefixLimit
+	s.cache.Set("typeahead "+query, result)
+	return result, nil
+}
+
+// prefixMatch answers the query from the cached result of a shorter query it starts with, if that result holds every
+// match.  "star w" is answered from a complete "star" result by filtering it.
+func (s *Suggester) prefixMatch(query string) *typeahead {
+	if s.prefixLimit <= 0 {
+		return nil
+	}
+	for end := len(query) - 1; end > 0; end-- {
+		if !utf8.RuneStart(query[end]) {
+			continue
+		}
+		cached, ok := s.cache.Get("typeahead " + query[:end])
+		if !ok || !cached.(*typeahead).complete {
+			continue
+		}
+		shorter := cached.(*typeahead)
+
+		filtered := &typeahead{complete: true}
+		for _, term := range shorter.Terms {
+			if strings.HasPrefix(normalize(term), query) {
+				filtered.Terms = append(filtered.Terms, term)
+			}
+		}
+		for _, g := range shorter.Genres {
+			if matchesWords(g.Name, query) {
+				filtered.Genres = append(filtered.Genres, g)
+			}
+		}
+		for _, p := range shorter.Podcasts {
+			if matchesWords(p.Title+" "+p.Publisher, query) {
+				filtered.Podcasts = append(filtered.Podcasts, p)
+			}
+		}
+		return filtered
+	}
+	return nil
+}
+
+// correction returns the query with every SpellCheck suggestion applied, or "" when it is spelled right
+func (s *Suggester) correction(query string) (string, error) {
+	if cached, ok := s.cache.Get("spellcheck " + query); ok {
+		return cached.(string), nil
+	}
+
+	resp, err := s.client.SpellCheck(map[string]string{"q": query})
+	if err != nil {
+		return "", fmt.Errorf("failed to spell check %q: %w", query, err)
+	}
+	check := &spellCheck{}
+	if err := resp.Decode(check); err != nil {
+		return "", err
+	}
+
+	// apply from the end so that earlier offsets stay valid
+	sort.Slice(check.Tokens, func(i, j int) bool { return check.Tokens[i].Offset > check.Tokens[j].Offset })
+	corrected := query
+	for _, token := range check.Tokens {
+		if token.Suggestion == "" {
+			continue
+		}
+		end := token.Offset + len(token.Token)
+		if token.Offset >= 0 && end <= len(corrected) && corrected[token.Offset:end] == token.Token {
+			corrected = corrected[:token.Offset] + token.Suggestion + corrected[end:]
+		} else {
+			corrected = strings.Replace(corrected, token.Token, token.Suggestion, 1)
+		}
+	}
+	if corrected == query {
+		corrected = ""
+	}
+
+	s.cache.Set("spellcheck "+query, corrected)
+	return corrected, nil
+}
+
+func (s *Suggester) related(query string) ([]string, error) {
+	if cached, ok := s.cache.Get("related " + query); ok {
+		return cached.([]string), nil
+	}
+
+	resp, err := s.client.FetchRelatedSearches(map[string]string{"q": query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch related searches for %q: %w", query, err)
+	}
+	related := &relatedSearches{}
+	if err := resp.Decode(related); err != nil {
+		return nil, err
+	}
+
+	s.cache.Set("related "+query, related.Terms)
+	return related.Terms, nil
+}
+
+// merge ranks the suggestions of every source into one list.  The same text suggested by several sources is listed
+// once, with its best score and a small boost per extra source.
+func (s *Suggester) merge(ta *typeahead, correction string, related []string) []Suggestion {
+	var order []string
+	merged := map[string]*Suggestion{}
+	add := func(suggestion Suggestion) {
+		key := string(suggestion.Kind) + " " + normalize(suggestion.Text)
+		if suggestion.Kind != KindPodcast && suggestion.Kind != KindGenre {
+			// terms, corrections and related terms are all search queries
+			key = normalize(suggestion.Text)
+		}
+		if key == "" {
+			return
+		}
+		existing, ok := merged[key]
+		if !ok {
+			merged[key] = &suggestion
+			order = append(order, key)
+			return
+		}
+		if suggestion.Score > existing.Score {
+			suggestion.Score += positionDecay
+			merged[key] = &suggestion
+		} else {
+			existing.Score += positionDecay
+		}
+	}
+
+	for i, term := range ta.Terms {
+		add(Suggestion{Text: term, Kind: KindTerm, Score: scoreTerm - positionDecay*float64(i)})
+	}
+	if correction != "" {
+		score := scoreCorrection
+		if len(ta.Terms) == 0 {
+			// nothing starts with a misspelled query, the correction is the best there is
+			score = scoreTerm + positionDecay
+		}
+		add(Suggestion{Text: correction, Kind: KindCorrection, Score: score})
+	}
+	for i, term := range related {
+		add(Suggestion{Text: term, Kind: KindRelated, Score: scoreRelated - positionDecay*float64(i)})
+	}
+	for i, p := range ta.Podcasts {
+		add(Suggestion{Text: p.Title, Kind: KindPodcast, ID: p.ID, Score: scorePodcast - positionDecay*float64(i)})
+	}
+	for i, g := range ta.Genres {
+		add(Suggestion{Text: g.Name, Kind: KindGenre, ID: fmt.Sprint(g.ID), Score: scoreGenre - positionDecay*float64(i)})
+	}
+
+	suggestions := make([]Suggestion, 0, len(order))
+	for _, key := range order {
+		suggestions = append(suggestions, *merged[key])
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if s.maxSuggestions > 0 && len(suggestions) > s.maxSuggestions {
+		suggestions = suggestions[:s.maxSuggestions]
+	}
+	return suggestions
+}
+
+func (s *Suggester) clock() time.Time {
+	return s.now()
+}
+
+// normalize lower cases the query and collapses whitespace, so that equivalent input shares cache entries
+func normalize(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// matchesWords reports whether every word of the query starts a word of the text, the last query word may be partial
+func matchesWords(text, query string) bool {
+	words := strings.Fields(normalize(text))
+	for _, q := range strings.Fields(query) {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package suggest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	terms    map[string][]interface{}
+	genres   map[string][]interface{}
+	fixes    map[string][]interface{}
+	related  map[string][]interface{}
+	block    map[string]chan struct{}
+	mu       sync.Mutex
+	queries  []string
+	apiCalls int
+}
+
+func (c *fakeClient) record(q string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiCalls++
+	c.queries = append(c.queries, q)
+}
+
+func (c *fakeClient) Typeahead(args map[string]string) (*listennotes.Response, error) {
+	c.record("typeahead " + args["q"])
+	if ch, ok := c.block[args["q"]]; ok {
+		<-ch
+	}
+	return &listennotes.Response{Data: map[string]interface{}{
+		"terms":  c.terms[args["q"]],
+		"genres": c.genres[args["q"]],
+	}}, nil
+}
+
+func (c *fakeClient) SpellCheck(args map[string]string) (*listennotes.Response, error) {
+	c.record("spellcheck " + args["q"])
+	return &listennotes.Response{Data: map[string]interface{}{"tokens": c.fixes[args["q"]]}}, nil
+}
+
+func (c *fakeClient) FetchRelatedSearches(args map[string]string) (*listennotes.Response, error) {
+	c.record("related " + args["q"])
+	return &listennotes.Response{Data: map[string]interface{}{"terms": c.related[args["q"]]}}, nil
+}
+
+func texts(suggestions []Suggestion) string {
+	var list []string
+	for _, s := range suggestions {
+		list = append(list, s.Text)
+	}
+	return strings.Join(list, ",")
+}
+
+func TestSuggestMerge(t *testing.T) {
+	client := &fakeClient{
+		terms:   map[string][]interface{}{"star": {"star wars", "star trek"}},
+		related: map[string][]interface{}{"star": {"star trek", "astronomy"}},
+	}
+	s := NewSuggester(client)
+
+	result, err := s.Suggest("Star")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if got := texts(result.Suggestions); got != "star wars,star trek,astronomy" {
+		t.Errorf("Unexpected ranking: %s", got)
+	}
+	if result.Suggestions[1].Kind != KindTerm || result.Suggestions[1].Score <= scoreTerm-positionDecay {
+		t.Errorf("A term suggested by several sources should get a boost: %+v", result.Suggestions[1])
+	}
+}
+
+func TestSuggestCorrection(t *testing.T) {
+	client := &fakeClient{
+		fixes: map[string][]interface{}{"evergrand stok": {
+			map[string]interface{}{"token": "evergrand", "offset": 0, "suggestion": "evergrande"},
+			map[string]interface{}{"token": "stok", "offset": 10, "suggestion": "stock"},
+		}},
+		related: map[string][]interface{}{"evergrand stok": {"evergrande news"}},
+	}
+	s := NewSuggester(client)
+
+	result, _ := s.Suggest("evergrand  stok")
+	if len(result.Suggestions) != 2 || result.Suggestions[0].Kind != KindCorrection ||
+		result.Suggestions[0].Text != "evergrande stock" {
+		t.Errorf("Expected the correction first but got %+v", result.Suggestions)
+	}
+}
+
+func TestPrefixCache(t *testing.T) {
+	client := &fakeClient{
+		terms: map[string][]interface{}{
+			"star": {"star wars", "star trek", "starbucks"},
+			"ab":   {"ab1", "ab2", "ab3", "ab4"},
+		},
+	}
+	s := NewSuggester(client, WithoutSpellCheck(), WithoutRelatedSearches(), WithPrefixLimit(4))
+
+	s.Suggest("star")
+	result, _ := s.Suggest("star w")
+	if got := texts(result.Suggestions); got != "star wars" {
+		t.Errorf("Expected the filtered prefix result but got %s", got)
+	}
+	s.Suggest("star")
+	if client.apiCalls != 1 {
+		t.Errorf("Expected a single api call but got %v", client.queries)
+	}
+
+	// a full page may leave out matches of the longer query
+	s.Suggest("ab")
+	s.Suggest("abc")
+	if client.apiCalls != 3 {
+		t.Errorf("A full result should not be reused for longer queries: %v", client.queries)
+	}
+}
+
+func TestPrefixCacheOptIn(t *testing.T) {
+	client := &fakeClient{terms: map[string][]interface{}{"star": {"star wars"}}}
+	s := NewSuggester(client, WithoutSpellCheck(), WithoutRelatedSearches())
+
+	s.Suggest("star")
+	s.Suggest("star w")
+	if client.apiCalls != 2 {
+		t.Errorf("Prefix reuse should be off by default: %v", client.queries)
+	}
+}
+
+func TestPrefixCacheGenres(t *testing.T) {
+	genre := func(id int, name string) interface{} {
+		return map[string]interface{}{"id": id, "name": name}
+	}
+	client := &fakeClient{
+		terms: map[string][]interface{}{"co": {"comedy"}},
+		genres: map[string][]interface{}{"co": {
+			genre(133, "Comedy"), genre(134, "Comedy Interviews"), genre(135, "Comedy Fiction"), genre(136, "Courses"),
+		}},
+	}
+	s := NewSuggester(client, WithGenres(), WithoutSpellCheck(), WithoutRelatedSearches(), WithPrefixLimit(4))
+
+	// few terms but a full page of genres may leave out genres of the longer query
+	s.Suggest("co")
+	s.Suggest("com")
+	if client.apiCalls != 2 {
+		t.Errorf("A full genre result should not be reused for longer queries: %v", client.queries)
+	}
+}
+
+func TestUpdateDebounce(t *testing.T) {
+	client := &fakeClient{terms: map[string][]interface{}{"star w": {"star wars"}}}
+	s := NewSuggester(client, WithDebounce(20*time.Millisecond), WithoutSpellCheck(), WithoutRelatedSearches())
+	defer s.Close()
+
+	for _, q := range []string{"st", "sta", "star", "star ", "star w"} {
+		s.Update(q)
+	}
+	select {
+	case result := <-s.Results():
+		if result.Query != "star w" || texts(result.Suggestions) != "star wars" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a result")
+	}
+	if client.apiCalls != 1 {
+		t.Errorf("Expected only the settled query to be sent but got %v", client.queries)
+	}
+}
+
+func TestUpdateDropsSuperseded(t *testing.T) {
+	release := make(chan struct{})
+	client := &fakeClient{
+		terms: map[string][]interface{}{"slow": {"slow one"}, "fast": {"fast one"}},
+		block: map[string]chan struct{}{"slow": release},
+	}
+	s := NewSuggester(client, WithDebounce(time.Millisecond), WithoutRelatedSearches())
+	defer s.Close()
+
+	s.Update("slow")
+	for deadline := time.Now().Add(time.Second); ; {
+		client.mu.Lock()
+		sent := client.apiCalls
+		client.mu.Unlock()
+		if sent == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	s.Update("fast")
+	result := <-s.Results()
+	close(release)
+	if result.Query != "fast" {
+		t.Errorf("Expected the latest query but got %+v", result)
+	}
+
+	select {
+	case result := <-s.Results():
+		t.Errorf("The superseded result should be dropped: %+v", result)
+	case <-time.After(50 * time.Millisecond):
+	}
+	for _, q := range client.queries {
+		if q == "spellcheck slow" {
+			t.Errorf("The remaining calls of a superseded query should be skipped: %v", client.queries)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package transcript
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cue is a timed piece of the transcript
+type Cue struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// timedLine matches paragraphs starting with a timestamp, e.g. "[00:12:30] ...", "(12:30) ..." or "1:02:03 - ..."
+var timedLine = regexp.MustCompile(`^[\[(]?((?:\d{1,2}:)?\d{1,2}:\d{2})(?:[.,](\d{1,3}))?[\])]?\s*(?:[-–:]\s*)?(.*)$`)
+
+// Cues returns the timed cues of the transcript.  Timing is only available when paragraphs start with timestamps,
+// otherwise ok is false.  Each cue ends where the next one starts, the last one ends at audioLength (or a few seconds
+// after its start when the length is unknown).  Untimed paragraphs are added to the cue before them.
+func Cues(text string, audioLength time.Duration) (cues []Cue, ok bool) {
+	for _, p := range Paragraphs(text) {
+		m := timedLine.FindStringSubmatch(p.Text)
+		if m == nil {
+			if len(cues) > 0 {
+				cues[len(cues)-1].Text += "\n" + p.Text
+			}
+			continue
+		}
+		cues = append(cues, Cue{
+			Start: parseTimestamp(m[1], m[2]),
+			Text:  strings.TrimSpace(m[3]),
+		})
+	}
+	if len(cues) == 0 {
+		return nil, false
+	}
+
+	for i := range cues {
+		switch {
+		case i+1 < len(cues):
+			cues[i].End = cues[i+1].Start
+		case audioLength > cues[i].Start:
+			cues[i].End = audioLength
+		default:
+			cues[i].End = cues[i].Start + 5*time.Second
+		}
+	}
+	return cues, true
+}
+
+// parseTimestamp parses "hh:mm:ss", "mm:ss" and an optional millisecond fraction
+func parseTimestamp(clock string, fraction string) time.Duration {
+	var d time.Duration
+	for _, part := range strings.Split(clock, ":") {
+		n, _ := strconv.Atoi(part)
+		d = d*60 + time.Duration(n)*time.Second
+	}
+	if fraction != "" {
+		for len(fraction) < 3 {
+			fraction += "0"
+		}
+		ms, _ := strconv.Atoi(fraction)
+		d += time.Duration(ms) * time.Millisecond
+	}
+	return d
+}
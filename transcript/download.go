@@ -0,0 +1,46 @@
+package transcript
+
+import (
+	"context"
+	"fmt"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// EpisodeTranscript is the transcript of a single episode
+type EpisodeTranscript struct {
+	Episode    listennotes.Episode
+	Transcript string
+}
+
+// DownloadAll walks every episode of the podcast and fetches its transcript, calling fn for each episode that has
+// one.  Transcripts need a PRO plan, episodes without one are skipped.  Returning an error from fn stops the download.
+func DownloadAll(ctx context.Context, client listennotes.HTTPClient, podcastID string, fn func(EpisodeTranscript) error) error {
+	it := listennotes.NewPodcastEpisodeIterator(client, podcastID, nil)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		episode := it.Episode()
+		resp, err := client.FetchEpisodeByID(episode.ID, map[string]string{"show_transcript": "1"})
+		if err != nil {
+			return fmt.Errorf("failed to fetch transcript of episode %s: %w", episode.ID, err)
+		}
+		var detailed listennotes.Episode
+		if err := resp.Decode(&detailed); err != nil {
+			return err
+		}
+		if detailed.Transcript == "" {
+			continue
+		}
+
+		if err := fn(EpisodeTranscript{Episode: detailed, Transcript: detailed.Transcript}); err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("failed to list episodes of podcast %s: %w", podcastID, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNoTiming is returned when exporting a transcript without timestamps to a timed format
+var ErrNoTiming = fmt.Errorf("transcript has no timing information")
+
+// Format is an export format
+type Format int
+
+// Export formats
+const (
+	// FormatText writes the paragraphs separated by blank lines
+	FormatText Format = iota
+	// FormatSRT writes SubRip subtitles, timing is required
+	FormatSRT
+	// FormatWebVTT writes WebVTT subtitles, timing is required
+	FormatWebVTT
+	// FormatJSON writes the paragraphs, with their sentences, and the cues when timing is available
+	FormatJSON
+)
+
+// Export writes the transcript in the format.  audioLength is used to end the last cue, pass 0 when unknown.
+func Export(w io.Writer, text string, audioLength time.Duration, format Format) error {
+	switch format {
+	case FormatText:
+		return exportText(w, text)
+	case FormatSRT:
+		return exportTimed(w, text, audioLength, "", srtTimestamp, true)
+	case FormatWebVTT:
+		return exportTimed(w, text, audioLength, "WEBVTT\n\n", vttTimestamp, false)
+	case FormatJSON:
+		return exportJSON(w, text, audioLength)
+	default:
+		return fmt.Errorf("unknown transcript format %d", format)
+	}
+}
+
+func exportText(w io.Writer, text string) error {
+	paragraphs := Paragraphs(text)
+	for i, p := range paragraphs {
+		sep := "\n\n"
+		if i == len(paragraphs)-1 {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(w, p.Text+sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportTimed(w io.Writer, text string, audioLength time.Duration, header string, timestamp func(time.Duration) string, numbered bool) error {
+	cues, ok := Cues(text, audioLength)
+	if !ok {
+		return ErrNoTiming
+	}
+
+	var b strings.Builder
+	b.WriteString(header)
+	for i, cue := range cues {
+		if numbered {
+			fmt.Fprintf(&b, "%d\n", i+1)
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", timestamp(cue.Start), timestamp(cue.End), cue.Text)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func exportJSON(w io.Writer, text string, audioLength time.Duration) error {
+	doc := struct {
+		Paragraphs []Paragraph `json:"paragraphs"`
+		Cues       []jsonCue   `json:"cues,omitempty"`
+	}{
+		Paragraphs: Paragraphs(text),
+	}
+	if cues, ok := Cues(text, audioLength); ok {
+		for _, cue := range cues {
+			doc.Cues = append(doc.Cues, jsonCue{
+				Start: cue.Start.Seconds(),
+				End:   cue.End.Seconds(),
+				Text:  cue.Text,
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// jsonCue uses seconds rather than nanoseconds, like the rest of the api
+type jsonCue struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+func srtTimestamp(d time.Duration) string {
+	return clock(d, ",")
+}
+
+func vttTimestamp(d time.Duration) string {
+	return clock(d, ".")
+}
+
+func clock(d time.Duration, msSeparator string) string {
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+	ms := (d % time.Second) / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, msSeparator, ms)
+}
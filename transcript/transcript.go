@@ -0,0 +1,156 @@
+// Package transcript works with the plain text episode transcripts returned by FetchEpisodeByID (with
+// show_transcript=1, on PRO plans).  Transcripts are split into paragraphs on "\n".
+//
+// All offsets are byte offsets into the transcript string, so text[m.Offset:m.End] is the matched text.
+package transcript
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Sentence is a sentence of a paragraph
+type Sentence struct {
+	Offset int    `json:"offset"`
+	Text   string `json:"text"`
+}
+
+// Paragraph is a "\n" separated block of the transcript
+type Paragraph struct {
+	Offset    int        `json:"offset"`
+	Text      string     `json:"text"`
+	Sentences []Sentence `json:"sentences"`
+}
+
+// Match is a keyword found in the transcript, with its surrounding context
+type Match struct {
+	Offset int    `json:"offset"`
+	End    int    `json:"end"`
+	Before string `json:"before"`
+	Text   string `json:"text"`
+	After  string `json:"after"`
+	// Paragraph is the index of the paragraph containing the match
+	Paragraph int `json:"paragraph"`
+}
+
+// Paragraphs splits the transcript into its non-empty paragraphs
+func Paragraphs(text string) []Paragraph {
+	var paragraphs []Paragraph
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		start := offset
+		offset += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		start += strings.Index(line, trimmed)
+		paragraphs = append(paragraphs, Paragraph{
+			Offset:    start,
+			Text:      trimmed,
+			Sentences: sentences(trimmed, start),
+		})
+	}
+	return paragraphs
+}
+
+// Sentences splits the transcript into sentences, across all paragraphs
+func Sentences(text string) []Sentence {
+	var all []Sentence
+	for _, p := range Paragraphs(text) {
+		all = append(all, p.Sentences...)
+	}
+	return all
+}
+
+// sentences splits a paragraph after ".", "!" or "?" followed by a space
+func sentences(paragraph string, base int) []Sentence {
+	var list []Sentence
+	start := 0
+	for i, r := range paragraph {
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		next := i + utf8.RuneLen(r)
+		// closing quotes and brackets stay with the sentence
+		for next < len(paragraph) && strings.IndexByte(`"')]`, paragraph[next]) >= 0 {
+			next++
+		}
+		if next < len(paragraph) && !unicode.IsSpace(rune(paragraph[next])) {
+			continue
+		}
+		list = appendSentence(list, paragraph, start, next, base)
+		start = next
+	}
+	return appendSentence(list, paragraph, start, len(paragraph), base)
+}
+
+func appendSentence(list []Sentence, paragraph string, start int, end int, base int) []Sentence {
+	if start >= end {
+		return list
+	}
+	raw := paragraph[start:end]
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return list
+	}
+	return append(list, Sentence{
+		Offset: base + start + strings.Index(raw, trimmed),
+		Text:   trimmed,
+	})
+}
+
+// Search finds every case-insensitive occurrence of the keyword, with up to contextChars characters of context on
+// each side.  Context is not taken across paragraph breaks.
+func Search(text string, keyword string, contextChars int) []Match {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil
+	}
+	re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(keyword))
+
+	var matches []Match
+	paragraphs := Paragraphs(text)
+	for pi, p := range paragraphs {
+		for _, loc := range re.FindAllStringIndex(p.Text, -1) {
+			matches = append(matches, Match{
+				Offset:    p.Offset + loc[0],
+				End:       p.Offset + loc[1],
+				Before:    lastRunes(p.Text[:loc[0]], contextChars),
+				Text:      p.Text[loc[0]:loc[1]],
+				After:     firstRunes(p.Text[loc[1]:], contextChars),
+				Paragraph: pi,
+			})
+		}
+	}
+	return matches
+}
+
+func firstRunes(s string, n int) string {
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
+
+func lastRunes(s string, n int) string {
+	count := utf8.RuneCountInString(s)
+	if count <= n {
+		return s
+	}
+	skip := count - n
+	for i := range s {
+		if skip == 0 {
+			return s[i:]
+		}
+		skip--
+	}
+	return ""
+}
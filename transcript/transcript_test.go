@@ -0,0 +1,129 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+const plainTranscript = "Welcome to the show. Today we talk about Star Wars!\n\nIs \"Andor\" the best show? I think so.\nThanks for listening."
+
+const timedTranscript = "[00:00:00] Welcome to the show.\n[00:01:30.5] Today we talk about Star Wars.\nIt is great.\n[01:02:03] Thanks for listening."
+
+func TestParagraphsAndSentences(t *testing.T) {
+	paragraphs := Paragraphs(plainTranscript)
+	if len(paragraphs) != 3 {
+		t.Fatalf("Expected 3 paragraphs but got %d: %v", len(paragraphs), paragraphs)
+	}
+
+	second := paragraphs[1]
+	if plainTranscript[second.Offset:second.Offset+len(second.Text)] != second.Text {
+		t.Errorf("Paragraph offset is wrong: %d", second.Offset)
+	}
+	if len(second.Sentences) != 2 || second.Sentences[0].Text != `Is "Andor" the best show?` {
+		t.Errorf("Sentences were not as expected: %v", second.Sentences)
+	}
+
+	sentences := Sentences(plainTranscript)
+	if len(sentences) != 5 {
+		t.Errorf("Expected 5 sentences but got %d: %v", len(sentences), sentences)
+	}
+	for _, s := range sentences {
+		if plainTranscript[s.Offset:s.Offset+len(s.Text)] != s.Text {
+			t.Errorf("Sentence offset is wrong for %q: %d", s.Text, s.Offset)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	matches := Search(plainTranscript, "star wars", 10)
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match but got %v", matches)
+	}
+	m := matches[0]
+	if plainTranscript[m.Offset:m.End] != "Star Wars" || m.Before != "alk about " || m.After != "!" || m.Paragraph != 0 {
+		t.Errorf("Match was not as expected: %+v", m)
+	}
+
+	if matches := Search(plainTranscript, "show", 5); len(matches) != 2 || matches[1].Paragraph != 1 {
+		t.Errorf("Expected a match in each of the first two paragraphs: %+v", matches)
+	}
+}
+
+func TestExportTimed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, timedTranscript, time.Hour+5*time.Minute, FormatSRT); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	expected := "1\n00:00:00,000 --> 00:01:30,500\nWelcome to the show.\n\n" +
+		"2\n00:01:30,500 --> 01:02:03,000\nToday we talk about Star Wars.\nIt is great.\n\n" +
+		"3\n01:02:03,000 --> 01:05:00,000\nThanks for listening.\n\n"
+	if buf.String() != expected {
+		t.Errorf("SRT was not as expected:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	Export(&buf, timedTranscript, 0, FormatWebVTT)
+	if !strings.HasPrefix(buf.String(), "WEBVTT\n\n00:00:00.000 --> 00:01:30.500\n") ||
+		!strings.Contains(buf.String(), "01:02:03.000 --> 01:02:08.000") {
+		t.Errorf("WebVTT was not as expected:\n%s", buf.String())
+	}
+}
+
+func TestExportUntimed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, plainTranscript, 0, FormatSRT); err != ErrNoTiming {
+		t.Errorf("Expected no timing error but got: %v", err)
+	}
+
+	Export(&buf, plainTranscript, 0, FormatText)
+	if strings.Count(buf.String(), "\n\n") != 2 {
+		t.Errorf("Text export was not as expected:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	Export(&buf, plainTranscript, 0, FormatJSON)
+	if !strings.Contains(buf.String(), `"paragraphs"`) || strings.Contains(buf.String(), `"cues"`) {
+		t.Errorf("JSON export was not as expected:\n%s", buf.String())
+	}
+}
+
+type fakeClient struct {
+	listennotes.HTTPClient
+}
+
+func (c *fakeClient) FetchPodcastByID(id string, args map[string]string) (*listennotes.Response, error) {
+	return &listennotes.Response{Data: map[string]interface{}{
+		"id": id,
+		"episodes": []interface{}{
+			map[string]interface{}{"id": "e1"},
+			map[string]interface{}{"id": "e2"},
+		},
+	}}, nil
+}
+
+func (c *fakeClient) FetchEpisodeByID(id string, args map[string]string) (*listennotes.Response, error) {
+	data := map[string]interface{}{"id": id}
+	if id == "e2" && args["show_transcript"] == "1" {
+		data["transcript"] = plainTranscript
+	}
+	return &listennotes.Response{Data: data}, nil
+}
+
+func TestDownloadAll(t *testing.T) {
+	var downloaded []EpisodeTranscript
+	err := DownloadAll(context.Background(), &fakeClient{}, "abc", func(et EpisodeTranscript) error {
+		downloaded = append(downloaded, et)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if len(downloaded) != 1 || downloaded[0].Episode.ID != "e2" || downloaded[0].Transcript != plainTranscript {
+		t.Errorf("Only the episode with a transcript should be downloaded: %+v", downloaded)
+	}
+}
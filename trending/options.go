@@ -0,0 +1,33 @@
+package trending
+
+// Option allows for options to be passed to the collector constructor function
+type Option func(c *Collector)
+
+// WithTopResults sets how many Search results are kept per new term.  The default is 5, zero skips the search.
+func WithTopResults(n int) Option {
+	return func(c *Collector) {
+		c.topResults = n
+	}
+}
+
+// WithSearchArgs passes extra arguments, e.g. "type" or "language", to the Search of new terms
+func WithSearchArgs(args map[string]string) Option {
+	return func(c *Collector) {
+		c.searchArgs = args
+	}
+}
+
+// WithoutEnrichment only records the trending terms, saving the api calls of enriching new terms
+func WithoutEnrichment() Option {
+	return func(c *Collector) {
+		c.enrich = false
+	}
+}
+
+// WithErrorFunc is called by Run with the error of each tick that failed, e.g. to log it.  The errors are dropped if
+// not provided.
+func WithErrorFunc(fn func(error)) Option {
+	return func(c *Collector) {
+		c.onError = fn
+	}
+}
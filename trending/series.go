@@ -0,0 +1,139 @@
+package trending
+
+import (
+	"sort"
+	"time"
+)
+
+// RankPoint is the rank of a term in a single sample
+type RankPoint struct {
+	At   time.Time
+	Rank int
+}
+
+// TermHistory is the trending history of a single term
+type TermHistory struct {
+	Term      string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Appearances is the number of samples the term was in
+	Appearances int
+	BestRank    int
+	Ranks       []RankPoint
+	// Days is the number of distinct calendar days, in UTC, the term was trending
+	Days int
+}
+
+// AverageRank is the mean rank over the samples the term was in
+func (h TermHistory) AverageRank() float64 {
+	if len(h.Ranks) == 0 {
+		return 0
+	}
+	total := 0
+	for _, p := range h.Ranks {
+		total += p.Rank
+	}
+	return float64(total) / float64(len(h.Ranks))
+}
+
+// Series is the history of every term over a set of samples
+type Series struct {
+	samples []Sample
+	terms   map[string]*TermHistory
+}
+
+// NewSeries will build the history of the samples
+func NewSeries(samples []Sample) *Series {
+	sorted := make([]Sample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].At.Before(sorted[j].At) })
+
+	s := &Series{samples: sorted, terms: map[string]*TermHistory{}}
+	days := map[string]map[string]bool{}
+	for _, sample := range sorted {
+		day := sample.At.UTC().Format("2006-01-02")
+		for i, term := range sample.Terms {
+			rank := i + 1
+			h, ok := s.terms[term]
+			if !ok {
+				h = &TermHistory{Term: term, FirstSeen: sample.At, BestRank: rank}
+				s.terms[term] = h
+				days[term] = map[string]bool{}
+			}
+			h.LastSeen = sample.At
+			h.Appearances++
+			h.Ranks = append(h.Ranks, RankPoint{At: sample.At, Rank: rank})
+			if rank < h.BestRank {
+				h.BestRank = rank
+			}
+			if !days[term][day] {
+				days[term][day] = true
+				h.Days++
+			}
+		}
+	}
+	return s
+}
+
+// Term returns the history of a single term
+func (s *Series) Term(term string) (TermHistory, bool) {
+	h, ok := s.terms[term]
+	if !ok {
+		return TermHistory{}, false
+	}
+	return *h, true
+}
+
+// Terms returns the history of every term, most days trending first
+func (s *Series) Terms() []TermHistory {
+	return s.filter(func(TermHistory) bool { return true })
+}
+
+// TrendingFor returns the terms that were trending on more than the given number of distinct days, most days first
+func (s *Series) TrendingFor(days int) []TermHistory {
+	return s.filter(func(h TermHistory) bool { return h.Days > days })
+}
+
+// Emerging returns the terms first seen at or after since, e.g. the newly emerging terms of this week.  Terms seen
+// in the very first sample are left out, the series cannot tell whether they were new.
+func (s *Series) Emerging(since time.Time) []TermHistory {
+	if len(s.samples) == 0 {
+		return nil
+	}
+	first := s.samples[0].At
+	return s.filter(func(h TermHistory) bool {
+		return !h.FirstSeen.Before(since) && h.FirstSeen.After(first)
+	})
+}
+
+// Active returns the terms in the latest sample, in rank order
+func (s *Series) Active() []TermHistory {
+	if len(s.samples) == 0 {
+		return nil
+	}
+	latest := s.samples[len(s.samples)-1]
+	active := make([]TermHistory, 0, len(latest.Terms))
+	for _, term := range latest.Terms {
+		active = append(active, *s.terms[term])
+	}
+	return active
+}
+
+func (s *Series) filter(keep func(TermHistory) bool) []TermHistory {
+	var list []TermHistory
+	for _, h := range s.terms {
+		if keep(*h) {
+			list = append(list, *h)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Days != list[j].Days {
+			return list[i].Days > list[j].Days
+		}
+		if list[i].Appearances != list[j].Appearances {
+			return list[i].Appearances > list[j].Appearances
+		}
+		return list[i].Term < list[j].Term
+	})
+	return list
+}
@@ -0,0 +1,173 @@
+package trending
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store persists the time series and term information.  Implementations must be safe for concurrent use.
+type Store interface {
+	AddSample(sample Sample) error
+	// Samples returns the samples taken in [from, to), ordered by time
+	Samples(from, to time.Time) ([]Sample, error)
+	SaveTermInfo(info TermInfo) error
+	TermInfo(term string) (TermInfo, bool, error)
+}
+
+// MemoryStore is a Store that keeps everything in memory, state is lost on restart
+type MemoryStore struct {
+	mu      sync.Mutex
+	samples []Sample
+	terms   map[string]TermInfo
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore will create an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{terms: map[string]TermInfo{}}
+}
+
+// AddSample implements Store
+func (s *MemoryStore) AddSample(sample Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].At.After(sample.At) })
+	s.samples = append(s.samples, Sample{})
+	copy(s.samples[i+1:], s.samples[i:])
+	s.samples[i] = sample
+	return nil
+}
+
+// Samples implements Store
+func (s *MemoryStore) Samples(from, to time.Time) ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var samples []Sample
+	for _, sample := range s.samples {
+		if !sample.At.Before(from) && sample.At.Before(to) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
+
+// SaveTermInfo implements Store
+func (s *MemoryStore) SaveTermInfo(info TermInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.terms[info.Term] = info
+	return nil
+}
+
+// TermInfo implements Store
+func (s *MemoryStore) TermInfo(term string) (TermInfo, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, ok := s.terms[term]
+	return info, ok, nil
+}
+
+// fileRecord is a single JSON line of a FileStore, holding either a sample or term information
+type fileRecord struct {
+	Sample *Sample   `json:"sample,omitempty"`
+	Term   *TermInfo `json:"term,omitempty"`
+}
+
+// FileStore is a Store persisted as JSON Lines.  Records are only ever appended, the file is read back on open.
+type FileStore struct {
+	memory *MemoryStore
+	mu     sync.Mutex
+	f      *os.File
+}
+
+var _ Store = &FileStore{}
+
+// OpenFileStore opens, or creates, the store at path.  Close it once done.
+func OpenFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trending store %s: %w", path, err)
+	}
+
+	s := &FileStore{memory: NewMemoryStore(), f: f}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record fileRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed parsing trending store line %d: %w", line, err)
+		}
+		if record.Sample != nil {
+			s.memory.AddSample(*record.Sample)
+		}
+		if record.Term != nil {
+			s.memory.SaveTermInfo(*record.Term)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed reading trending store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Close closes the underlying file
+func (s *FileStore) Close() error {
+	return s.f.Close()
+}
+
+// AddSample implements Store
+func (s *FileStore) AddSample(sample Sample) error {
+	if err := s.append(fileRecord{Sample: &sample}); err != nil {
+		return err
+	}
+	return s.memory.AddSample(sample)
+}
+
+// Samples implements Store
+func (s *FileStore) Samples(from, to time.Time) ([]Sample, error) {
+	return s.memory.Samples(from, to)
+}
+
+// SaveTermInfo implements Store
+func (s *FileStore) SaveTermInfo(info TermInfo) error {
+	if err := s.append(fileRecord{Term: &info}); err != nil {
+		return err
+	}
+	return s.memory.SaveTermInfo(info)
+}
+
+// TermInfo implements Store
+func (s *FileStore) TermInfo(term string) (TermInfo, bool, error) {
+	return s.memory.TermInfo(term)
+}
+
+func (s *FileStore) append(record fileRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode trending record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.f.Write(line); err != nil {
+		return fmt.Errorf("failed to write trending record: %w", err)
+	}
+	return nil
+}
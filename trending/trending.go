@@ -0,0 +1,187 @@
+// Package trending turns the point-in-time FetchTrendingSearches list into a time series.
+//
+// A Collector samples the trending terms on a schedule and records every term with its rank.  Terms seen for the
+// first time are enriched with their related searches and top search results.  A Series answers questions about the
+// recorded history:
+//
+//	collector := trending.NewCollector(client, store)
+//	go collector.Run(ctx, time.Hour)
+//
+//	samples, _ := store.Samples(time.Time{}, time.Now())
+//	series := trending.NewSeries(samples)
+//	longRunning := series.TrendingFor(3)
+//	emerging := series.Emerging(time.Now().AddDate(0, 0, -7))
+package trending
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+// Sample is the trending list at a point in time
+type Sample struct {
+	At time.Time `json:"at"`
+	// Terms are in rank order, the first term has rank 1
+	Terms []string `json:"terms"`
+}
+
+// SearchHit is one of the top search results of a term
+type SearchHit struct {
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	PodcastTitle   string `json:"podcast_title"`
+	ListennotesURL string `json:"listennotes_url"`
+}
+
+// TermInfo is what was learned about a term when it first trended
+type TermInfo struct {
+	Term       string      `json:"term"`
+	EnrichedAt time.Time   `json:"enriched_at"`
+	Related    []string    `json:"related"`
+	TopResults []SearchHit `json:"top_results"`
+}
+
+type searchResponse struct {
+	Results []struct {
+		ID             string `json:"id"`
+		Title          string `json:"title_original"`
+		ListennotesURL string `json:"listennotes_url"`
+		Podcast        struct {
+			Title string `json:"title_original"`
+		} `json:"podcast"`
+	} `json:"results"`
+}
+
+// Collector samples the trending searches and enriches new terms
+type Collector struct {
+	client     listennotes.HTTPClient
+	store      Store
+	searchArgs map[string]string
+	topResults int
+	enrich     bool
+	onError    func(error)
+	now        func() time.Time
+}
+
+// NewCollector will create a collector using the client for api calls and the store for the time series.
+// You can optionally override some configuration.
+func NewCollector(client listennotes.HTTPClient, store Store, opts ...Option) *Collector {
+	c := &Collector{
+		client:     client,
+		store:      store,
+		searchArgs: map[string]string{},
+		topResults: 5,
+		enrich:     true,
+		onError:    func(error) {},
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Collect takes a sample of the trending searches and stores it.  Terms without stored information are enriched,
+// a failed enrichment is returned as error after the sample is stored and is retried on the next Collect.
+func (c *Collector) Collect() (Sample, error) {
+	resp, err := c.client.FetchTrendingSearches(nil)
+	if err != nil {
+		return Sample{}, fmt.Errorf("failed to fetch trending searches: %w", err)
+	}
+	sample := Sample{At: c.now()}
+	if err := resp.Decode(&sample); err != nil {
+		return Sample{}, err
+	}
+
+	if err := c.store.AddSample(sample); err != nil {
+		return Sample{}, fmt.Errorf("failed to store trending sample: %w", err)
+	}
+
+	if !c.enrich {
+		return sample, nil
+	}
+	for _, term := range sample.Terms {
+		if _, ok, err := c.store.TermInfo(term); err != nil {
+			return sample, fmt.Errorf("failed to load term %q: %w", term, err)
+		} else if ok {
+			continue
+		}
+		info, err := c.enrichTerm(term)
+		if err != nil {
+			return sample, err
+		}
+		if err := c.store.SaveTermInfo(info); err != nil {
+			return sample, fmt.Errorf("failed to store term %q: %w", term, err)
+		}
+	}
+	return sample, nil
+}
+
+// Run collects every interval until the context is done.  Errors are passed to the error callback and failed
+// samples are retried on the next tick, only the context ends the loop.
+func (c *Collector) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.Collect(); err != nil {
+			c.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Collector) enrichTerm(term string) (TermInfo, error) {
+	info := TermInfo{Term: term, EnrichedAt: c.now()}
+
+	resp, err := c.client.FetchRelatedSearches(map[string]string{"q": term})
+	if err != nil {
+		return TermInfo{}, fmt.Errorf("failed to fetch related searches for %q: %w", term, err)
+	}
+	related := struct {
+		Terms []string `json:"terms"`
+	}{}
+	if err := resp.Decode(&related); err != nil {
+		return TermInfo{}, err
+	}
+	info.Related = related.Terms
+
+	if c.topResults > 0 {
+		args := map[string]string{}
+		for k, v := range c.searchArgs {
+			args[k] = v
+		}
+		args["q"] = term
+		args["page_size"] = strconv.Itoa(c.topResults)
+		resp, err := c.client.Search(args)
+		if err != nil {
+			return TermInfo{}, fmt.Errorf("failed to search %q: %w", term, err)
+		}
+		results := &searchResponse{}
+		if err := resp.Decode(results); err != nil {
+			return TermInfo{}, err
+		}
+		for _, r := range results.Results {
+			if len(info.TopResults) == c.topResults {
+				break
+			}
+			info.TopResults = append(info.TopResults, SearchHit{
+				ID:             r.ID,
+				Title:          r.Title,
+				PodcastTitle:   r.Podcast.Title,
+				ListennotesURL: r.ListennotesURL,
+			})
+		}
+	}
+	return info, nil
+}
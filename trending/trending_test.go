@@ -0,0 +1,149 @@
+package trending
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	listennotes "github.com/ListenNotes/podcast-api-go"
+)
+
+type fakeClient struct {
+	listennotes.HTTPClient
+	terms    []interface{}
+	err      error
+	enriched []string
+}
+
+func (c *fakeClient) FetchTrendingSearches(args map[string]string) (*listennotes.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &listennotes.Response{Data: map[string]interface{}{"terms": c.terms}}, nil
+}
+
+func (c *fakeClient) FetchRelatedSearches(args map[string]string) (*listennotes.Response, error) {
+	c.enriched = append(c.enriched, args["q"])
+	return &listennotes.Response{Data: map[string]interface{}{"terms": []interface{}{args["q"] + " news"}}}, nil
+}
+
+func (c *fakeClient) Search(args map[string]string) (*listennotes.Response, error) {
+	return &listennotes.Response{Data: map[string]interface{}{"results": []interface{}{
+		map[string]interface{}{"id": "e1", "title_original": "About " + args["q"], "podcast": map[string]interface{}{"title_original": "Show"}},
+		map[string]interface{}{"id": "e2", "title_original": "More " + args["q"]},
+	}}}, nil
+}
+
+func TestCollect(t *testing.T) {
+	client := &fakeClient{terms: []interface{}{"oppenheimer", "barbie"}}
+	store := NewMemoryStore()
+	collector := NewCollector(client, store, WithTopResults(1))
+	now := time.Date(2023, 7, 20, 10, 0, 0, 0, time.UTC)
+	collector.now = func() time.Time { return now }
+
+	if _, err := collector.Collect(); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	client.terms = []interface{}{"barbie", "threads"}
+	now = now.Add(time.Hour)
+	sample, err := collector.Collect()
+	if err != nil || len(sample.Terms) != 2 {
+		t.Fatalf("Unexpected sample %v: %v", sample, err)
+	}
+
+	if len(client.enriched) != 3 {
+		t.Errorf("Each term should be enriched once: %v", client.enriched)
+	}
+	info, ok, _ := store.TermInfo("threads")
+	if !ok || len(info.Related) != 1 || len(info.TopResults) != 1 || info.TopResults[0].PodcastTitle != "Show" {
+		t.Errorf("Unexpected term info: %+v", info)
+	}
+
+	samples, _ := store.Samples(time.Time{}, now.Add(time.Second))
+	if len(samples) != 2 || samples[0].Terms[0] != "oppenheimer" {
+		t.Errorf("Unexpected samples: %v", samples)
+	}
+}
+
+func TestRunReportsErrors(t *testing.T) {
+	client := &fakeClient{err: errors.New("boom")}
+	errs := make(chan error, 1)
+	collector := NewCollector(client, NewMemoryStore(), WithErrorFunc(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go collector.Run(ctx, time.Hour)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, client.err) {
+			t.Errorf("Expected the fetch error but got: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected Run to report the failed sample")
+	}
+}
+
+func TestSeries(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2023, 7, d, h, 0, 0, 0, time.UTC) }
+	series := NewSeries([]Sample{
+		{At: day(3, 12), Terms: []string{"b", "a"}},
+		{At: day(1, 9), Terms: []string{"a", "b"}},
+		{At: day(1, 18), Terms: []string{"a"}},
+		{At: day(2, 9), Terms: []string{"c", "a"}},
+		{At: day(8, 9), Terms: []string{"d", "a"}},
+	})
+
+	a, _ := series.Term("a")
+	if a.Days != 4 || a.Appearances != 5 || a.BestRank != 1 || a.AverageRank() != 1.6 || !a.FirstSeen.Equal(day(1, 9)) {
+		t.Errorf("Unexpected history: %+v", a)
+	}
+
+	long := series.TrendingFor(1)
+	if len(long) != 2 || long[0].Term != "a" || long[1].Term != "b" {
+		t.Errorf("Unexpected long running terms: %v", long)
+	}
+
+	emerging := series.Emerging(day(2, 0))
+	if len(emerging) != 2 || emerging[0].Term != "c" || emerging[1].Term != "d" {
+		t.Errorf("Unexpected emerging terms: %v", emerging)
+	}
+	if emerging := series.Emerging(day(1, 0)); len(emerging) != 2 {
+		t.Errorf("Terms of the first sample are not emerging: %v", emerging)
+	}
+
+	if active := series.Active(); len(active) != 2 || active[0].Term != "d" {
+		t.Errorf("Unexpected active terms: %v", active)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trending.jsonl")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	at := time.Date(2023, 7, 20, 10, 0, 0, 0, time.UTC)
+	store.AddSample(Sample{At: at, Terms: []string{"a"}})
+	store.SaveTermInfo(TermInfo{Term: "a", Related: []string{"a b"}})
+	store.Close()
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	defer reopened.Close()
+	if samples, _ := reopened.Samples(at, at.Add(time.Second)); len(samples) != 1 || samples[0].Terms[0] != "a" {
+		t.Errorf("Unexpected samples: %v", samples)
+	}
+	if info, ok, _ := reopened.TermInfo("a"); !ok || info.Related[0] != "a b" {
+		t.Errorf("Unexpected term info: %+v", info)
+	}
+}
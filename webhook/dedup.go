@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// seenSet remembers event keys for a window, so that redeliveries are only handled once
+type seenSet struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+func newSeenSet(window time.Duration) *seenSet {
+	return &seenSet{
+		window: window,
+		keys:   map[string]time.Time{},
+	}
+}
+
+// claim marks the key as seen, returning false when it already was within the window
+func (s *seenSet) claim(key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, at := range s.keys {
+		if now.Sub(at) >= s.window {
+			delete(s.keys, k)
+		}
+	}
+
+	if _, ok := s.keys[key]; ok {
+		return false
+	}
+	s.keys[key] = now
+	return true
+}
+
+// release forgets the key, e.g. when handling the event failed
+func (s *seenSet) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+}
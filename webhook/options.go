@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"time"
+)
+
+// Option allows for options to be passed to the handler constructor function
+type Option func(h *Handler)
+
+// OnPodcastAccepted is called when a submitted podcast was accepted into the listennotes database
+func OnPodcastAccepted(fn EventFunc) Option {
+	return On(EventPodcastAccepted, fn)
+}
+
+// OnPodcastDeleted is called when a requested podcast deletion was completed
+func OnPodcastDeleted(fn EventFunc) Option {
+	return On(EventPodcastDeleted, fn)
+}
+
+// On sets the callback for an event type
+func On(eventType EventType, fn EventFunc) Option {
+	return func(h *Handler) {
+		h.callbacks[eventType] = fn
+	}
+}
+
+// OnUnknown is called for payloads with an event type this package does not know, or none.  They are acknowledged
+// either way, use it to log them or to handle event types added after this package.
+func OnUnknown(fn EventFunc) Option {
+	return func(h *Handler) {
+		h.onUnknown = fn
+	}
+}
+
+// WithDedupWindow sets how long handled events are remembered to drop redeliveries.  Defaults to 24 hours.
+func WithDedupWindow(window time.Duration) Option {
+	return func(h *Handler) {
+		h.seen = newSeenSet(window)
+	}
+}
+
+// WithMaxBodyBytes limits the size of accepted payloads.  Defaults to 1MB.
+func WithMaxBodyBytes(n int64) Option {
+	return func(h *Handler) {
+		h.maxBodyBytes = n
+	}
+}
@@ -0,0 +1,176 @@
+// Package webhook receives the notifications Listen Notes sends to the webhook url configured in the api dashboard
+// (https://www.listennotes.com/api/dashboard/#webhooks), when a submitted podcast is accepted or a requested deletion
+// is completed.
+//
+// Payloads are parsed into typed events, validated, de-duplicated and handed to callbacks:
+//
+//	http.Handle("/hooks/listennotes", webhook.NewHandler(
+//		webhook.OnPodcastAccepted(func(e webhook.Event) error {
+//			return markLive(e.Podcast.RSS, e.Podcast.ID)
+//		}),
+//	))
+//
+// A callback returning an error answers with a server error, so that the notification is delivered again later.
+//
+// The payload format is an assumption.  The api docs in the README only say that a notification is sent, they do not
+// document its body, so this package expects the shape below.  Check it against a real delivery before relying on it,
+// e.g. by logging the Raw payload of the first events with OnUnknown:
+//
+//	{
+//		"id": "delivery id, optional",
+//		"event": "podcast.accepted" or "podcast.deleted",
+//		"podcast": {"id": "...", "title": "...", "publisher": "...", "rss": "...", "image": "...", "website": "..."}
+//	}
+//
+// Payloads with another event type, or none, are acknowledged and passed to the OnUnknown callback.  Answering them
+// with an error would make the sender retry them forever once it ships a new event type.
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// EventType is the kind of notification
+type EventType string
+
+// Known event types
+const (
+	EventPodcastAccepted EventType = "podcast.accepted"
+	EventPodcastDeleted  EventType = "podcast.deleted"
+)
+
+// Podcast is the podcast a notification is about
+type Podcast struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Publisher string `json:"publisher"`
+	RSS       string `json:"rss"`
+	Image     string `json:"image"`
+	Website   string `json:"website"`
+}
+
+// Event is a single parsed notification
+type Event struct {
+	// ID is the delivery id of the notification, if the payload has one
+	ID      string    `json:"id,omitempty"`
+	Type    EventType `json:"event"`
+	Podcast Podcast   `json:"podcast"`
+	// Raw is the payload as received, for fields not covered by Event
+	Raw        json.RawMessage `json:"-"`
+	ReceivedAt time.Time       `json:"-"`
+}
+
+// Known reports whether the event type is one this package knows the payload of
+func (e Event) Known() bool {
+	return e.Type == EventPodcastAccepted || e.Type == EventPodcastDeleted
+}
+
+// Key identifies the event for de-duplication.  Redeliveries of the same notification share a key.
+func (e Event) Key() string {
+	if e.ID != "" {
+		return e.ID
+	}
+	key := string(e.Type) + " " + e.Podcast.ID
+	if !e.Known() {
+		key = string(e.Raw)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// EventFunc handles a single event
+type EventFunc func(e Event) error
+
+// Handler is the http.Handler for the webhook url
+type Handler struct {
+	callbacks    map[EventType]EventFunc
+	onUnknown    EventFunc
+	seen         *seenSet
+	maxBodyBytes int64
+	now          func() time.Time
+}
+
+var _ http.Handler = &Handler{}
+
+// NewHandler will create a handler with reasonable defaults.  Events without a callback, unknown ones included, are
+// acknowledged and dropped.
+// You can optionally override some configuration.
+func NewHandler(opts ...Option) *Handler {
+	h := &Handler{
+		callbacks:    map[EventType]EventFunc{},
+		seen:         newSeenSet(24 * time.Hour),
+		maxBodyBytes: 1 << 20,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	event, err := ParseEvent(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event.ReceivedAt = h.now()
+
+	key := event.Key()
+	if !h.seen.claim(key, event.ReceivedAt) {
+		// a redelivery of an event that was already handled, or is being handled right now
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	callback, ok := h.callbacks[event.Type]
+	if !event.Known() {
+		callback, ok = h.onUnknown, h.onUnknown != nil
+	}
+	if ok {
+		if err := callback(event); err != nil {
+			// forget the event so the redelivery gets handled
+			h.seen.release(key)
+			http.Error(w, "failed to handle event", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ParseEvent parses and validates a notification payload.  Any JSON object with an unknown event type, or none, is
+// returned as is for the OnUnknown callback, only the known types are validated.
+func ParseEvent(body []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Event{}, fmt.Errorf("failed parsing the webhook payload: %w", err)
+	}
+	event.Raw = json.RawMessage(body)
+
+	if event.Known() && event.Podcast.ID == "" {
+		return Event{}, fmt.Errorf("webhook payload for %s has no podcast id", event.Type)
+	}
+	return event, nil
+}
@@ -0,0 +1,120 @@
+package webhook_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ListenNotes/podcast-api-go/webhook"
+	"github.com/ListenNotes/podcast-api-go/webhook/webhooktest"
+)
+
+func serve(h http.Handler, req *http.Request) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestHandlerDispatch(t *testing.T) {
+	var accepted, deleted []webhook.Event
+	h := webhook.NewHandler(
+		webhook.OnPodcastAccepted(func(e webhook.Event) error {
+			accepted = append(accepted, e)
+			return nil
+		}),
+		webhook.OnPodcastDeleted(func(e webhook.Event) error {
+			deleted = append(deleted, e)
+			return nil
+		}),
+	)
+
+	if code := serve(h, webhooktest.PodcastAcceptedRequest()); code != http.StatusOK {
+		t.Errorf("Expected 200 but got %d", code)
+	}
+	if code := serve(h, webhooktest.PodcastDeletedRequest()); code != http.StatusOK {
+		t.Errorf("Expected 200 but got %d", code)
+	}
+
+	if len(accepted) != 1 || accepted[0].Podcast.ID != webhooktest.SamplePodcastID || accepted[0].ReceivedAt.IsZero() {
+		t.Errorf("Accepted event was not as expected: %+v", accepted)
+	}
+	if len(deleted) != 1 || deleted[0].Podcast.RSS != "https://feeds.example.com/starwars7x7.xml" {
+		t.Errorf("Deleted event was not as expected: %+v", deleted)
+	}
+}
+
+func TestHandlerDeduplicates(t *testing.T) {
+	calls := 0
+	fail := true
+	h := webhook.NewHandler(webhook.OnPodcastAccepted(func(e webhook.Event) error {
+		calls++
+		if fail {
+			return fmt.Errorf("temporary failure")
+		}
+		return nil
+	}))
+
+	if code := serve(h, webhooktest.PodcastAcceptedRequest()); code != http.StatusInternalServerError {
+		t.Errorf("A failed callback should ask for redelivery: %d", code)
+	}
+	fail = false
+	serve(h, webhooktest.PodcastAcceptedRequest())
+	serve(h, webhooktest.PodcastAcceptedRequest())
+	if calls != 2 {
+		t.Errorf("Expected the redelivery after a failure to be handled once: %d", calls)
+	}
+}
+
+func TestHandlerValidation(t *testing.T) {
+	h := webhook.NewHandler()
+
+	invalid := []string{
+		`not-json`,
+		`{"event": "podcast.accepted", "podcast": {}}`,
+	}
+	for _, body := range invalid {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if code := serve(h, req); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s but got %d", body, code)
+		}
+	}
+
+	if code := serve(h, httptest.NewRequest(http.MethodGet, "/", nil)); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 but got %d", code)
+	}
+}
+
+func TestHandlerUnknownEvents(t *testing.T) {
+	var unknown []webhook.Event
+	h := webhook.NewHandler(webhook.OnUnknown(func(e webhook.Event) error {
+		unknown = append(unknown, e)
+		return nil
+	}))
+
+	payloads := []string{
+		`{"event": "podcast.renamed", "podcast": {"id": "abc"}}`,
+		`{"podcast": {"id": "abc"}}`,
+		`{"type": "something else"}`,
+	}
+	for _, body := range payloads {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if code := serve(h, req); code != http.StatusOK {
+			t.Errorf("Expected unknown events to be acknowledged but got %d for %s", code, body)
+		}
+	}
+	if len(unknown) != 3 || unknown[0].Type != "podcast.renamed" || string(unknown[2].Raw) != payloads[2] {
+		t.Errorf("Unknown events were not passed on: %+v", unknown)
+	}
+
+	// redeliveries of unknown events are dropped as well
+	serve(h, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payloads[2])))
+	if len(unknown) != 3 {
+		t.Errorf("Expected the redelivery to be dropped: %d", len(unknown))
+	}
+
+	if code := serve(webhook.NewHandler(), webhooktest.NewRawRequest([]byte(payloads[0]))); code != http.StatusOK {
+		t.Errorf("Unknown events should be acknowledged without a callback: %d", code)
+	}
+}
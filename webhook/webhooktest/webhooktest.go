@@ -0,0 +1,54 @@
+// Package webhooktest provides utilities for testing webhook handlers without network access.
+//
+// The sample payloads follow the format the webhook package assumes, see its documentation.  Once you captured a real
+// delivery, replay it with NewRawRequest to test against the actual format.
+package webhooktest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+)
+
+// SamplePodcastID is the podcast id of the sample payloads
+const SamplePodcastID = "4d3fe717742d4963a85562e9f84d8c79"
+
+// Sample payloads, in the format assumed by the webhook package
+const (
+	PodcastAcceptedPayload = `{
+  "event": "podcast.accepted",
+  "podcast": {
+    "id": "4d3fe717742d4963a85562e9f84d8c79",
+    "title": "Star Wars 7x7 | Star Wars News, Interviews, and More!",
+    "publisher": "Allen Voivod",
+    "rss": "https://feeds.example.com/starwars7x7.xml",
+    "image": "https://cdn-images-1.listennotes.com/podcasts/star-wars-7x7/image.jpg",
+    "website": "https://starwars7x7.com"
+  }
+}`
+	PodcastDeletedPayload = `{
+  "event": "podcast.deleted",
+  "podcast": {
+    "id": "4d3fe717742d4963a85562e9f84d8c79",
+    "title": "Star Wars 7x7 | Star Wars News, Interviews, and More!",
+    "rss": "https://feeds.example.com/starwars7x7.xml"
+  }
+}`
+)
+
+// NewRawRequest builds a POST request with the payload as body, ready to pass to a handler's ServeHTTP
+func NewRawRequest(payload []byte) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// PodcastAcceptedRequest is a sample accepted notification
+func PodcastAcceptedRequest() *http.Request {
+	return NewRawRequest([]byte(PodcastAcceptedPayload))
+}
+
+// PodcastDeletedRequest is a sample deletion notification
+func PodcastDeletedRequest() *http.Request {
+	return NewRawRequest([]byte(PodcastDeletedPayload))
+}